@@ -0,0 +1,105 @@
+package geodistanceserver
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// apiKeyHeader carries the API key on Routes API requests; the Geocoding
+// API takes it as the key query parameter instead.
+const apiKeyHeader = "X-Goog-Api-Key"
+
+// apiKeyRing hands out several API keys round-robin, so load is spread
+// across their quotas. It is safe for concurrent use.
+type apiKeyRing struct {
+	keys []string
+	next atomic.Uint64
+}
+
+func newAPIKeyRing(keys []string) *apiKeyRing {
+	return &apiKeyRing{keys: keys}
+}
+
+// pick returns the key for the next request.
+func (r *apiKeyRing) pick() string {
+	n := r.next.Add(1) - 1
+	return r.keys[n%uint64(len(r.keys))]
+}
+
+// after returns the key following key in the ring, wrapping around, so
+// failing over from each key in turn tries every key once.
+func (r *apiKeyRing) after(key string) string {
+	for i, k := range r.keys {
+		if k == key {
+			return r.keys[(i+1)%len(r.keys)]
+		}
+	}
+	return r.keys[0]
+}
+
+// parseAPIKeys splits a comma-separated GOOGLE_API_KEYS value, dropping
+// blank entries and duplicates.
+func parseAPIKeys(value string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// setAPIKeys configures the handler's keys. More than one key enables
+// rotation; the first key is also kept as apiKey.
+func (gh *GeodistanceHandler) setAPIKeys(keys []string) {
+	gh.apiKey, gh.apiKeys = "", nil
+	if len(keys) > 0 {
+		gh.apiKey = keys[0]
+	}
+	if len(keys) > 1 {
+		gh.apiKeys = newAPIKeyRing(keys)
+	}
+}
+
+// nextAPIKey returns the key to authenticate the next request with.
+func (gh *GeodistanceHandler) nextAPIKey() string {
+	if gh.apiKeys == nil {
+		return gh.apiKey
+	}
+	return gh.apiKeys.pick()
+}
+
+// allAPIKeys returns every configured key.
+func (gh *GeodistanceHandler) allAPIKeys() []string {
+	if gh.apiKeys != nil {
+		return gh.apiKeys.keys
+	}
+	if gh.apiKey != "" {
+		return []string{gh.apiKey}
+	}
+	return nil
+}
+
+// hasAPIKey reports whether req authenticates with a Google API key.
+func hasAPIKey(req *http.Request) bool {
+	return req.Header.Get(apiKeyHeader) != "" || req.URL.Query().Get("key") != ""
+}
+
+// failoverAPIKey switches req, throttled under its current key, to the
+// next key in the ring.
+func (gh *GeodistanceHandler) failoverAPIKey(req *http.Request) {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		req.Header.Set(apiKeyHeader, gh.apiKeys.after(key))
+		return
+	}
+	query := req.URL.Query()
+	if key := query.Get("key"); key != "" {
+		query.Set("key", gh.apiKeys.after(key))
+		req.URL.RawQuery = query.Encode()
+	}
+}
@@ -0,0 +1,170 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadAPIKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      string
+		key       string
+		expected  []string
+		expectErr bool
+	}{
+		{name: "single key", key: "only", expected: []string{"only"}},
+		{name: "list", keys: "a, b,,c", expected: []string{"a", "b", "c"}},
+		{name: "list takes precedence over single key", keys: "a,b", key: "only", expected: []string{"a", "b"}},
+		{name: "duplicates dropped", keys: "a,b,a", expected: []string{"a", "b"}},
+		{name: "blank list falls back to single key", keys: "  ", key: "only", expected: []string{"only"}},
+		{name: "list of separators", keys: ",,", key: "only", expectErr: true},
+		{name: "none", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_API_KEY_FILE", "")
+			t.Setenv("GOOGLE_API_KEYS", tt.keys)
+			t.Setenv("GOOGLE_API_KEY", tt.key)
+
+			keys, err := loadAPIKeys()
+			if tt.expectErr {
+				if !errors.Is(err, ErrMissingAPIKey) {
+					t.Errorf("expected ErrMissingAPIKey, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_APIKeyRotation(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY_FILE", "")
+	t.Setenv("GOOGLE_API_KEYS", "a,b,c")
+	t.Setenv("GOOGLE_API_KEY", "")
+
+	var mu sync.Mutex
+	var used []string
+	handler, err := NewGeodistanceHandlerWithClient(&MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		used = append(used, req.Header.Get(apiKeyHeader))
+		mu.Unlock()
+		return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 6; i++ {
+		if _, err := handler.callDistanceMatrix(context.Background(),
+			[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{}); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if expected := []string{"a", "b", "c", "a", "b", "c"}; !slices.Equal(used, expected) {
+		t.Errorf("expected keys %q, got %q", expected, used)
+	}
+}
+
+func TestAPIKeyRing_Concurrent(t *testing.T) {
+	ring := newAPIKeyRing([]string{"a", "b", "c"})
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := ring.pick()
+			mu.Lock()
+			counts[key]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, key := range ring.keys {
+		if counts[key] != 10 {
+			t.Errorf("expected key %s picked 10 times, got %d", key, counts[key])
+		}
+	}
+}
+
+func TestGeodistanceHandler_do_APIKeyFailover(t *testing.T) {
+	tests := []struct {
+		name          string
+		keys          []string
+		quotaExceeded []string
+		expectErr     bool
+		expectedKeys  []string
+	}{
+		{name: "next key succeeds", keys: []string{"a", "b", "c"}, quotaExceeded: []string{"a"}, expectedKeys: []string{"a", "b"}},
+		{name: "wraps around", keys: []string{"a", "b", "c"}, quotaExceeded: []string{"a", "b"}, expectedKeys: []string{"a", "b", "c"}},
+		{name: "every key exhausted", keys: []string{"a", "b"}, quotaExceeded: []string{"a", "b"}, expectErr: true, expectedKeys: []string{"a", "b"}},
+		{name: "single key only retried", keys: []string{"a"}, quotaExceeded: []string{"a"}, expectErr: true, expectedKeys: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var used []string
+			handler, err := NewGeodistanceHandlerWithClient(&MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				key := req.Header.Get(apiKeyHeader)
+				used = append(used, key)
+				if slices.Contains(tt.quotaExceeded, key) {
+					return createMockResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"Quota exceeded.","status":"RESOURCE_EXHAUSTED"}}`), nil
+				}
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			}}, WithAPIKeys(tt.keys...), WithThrottleRetry(0, time.Millisecond))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = handler.callDistanceMatrix(context.Background(),
+				[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+			if tt.expectErr {
+				if errorStatus(err) != http.StatusTooManyRequests {
+					t.Errorf("expected a 429 error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !slices.Equal(used, tt.expectedKeys) {
+				t.Errorf("expected keys %q, got %q", tt.expectedKeys, used)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_callGeocode_APIKeyFailover(t *testing.T) {
+	var used []string
+	handler := &GeodistanceHandler{client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		key := req.URL.Query().Get("key")
+		used = append(used, key)
+		if key == "a" {
+			return createMockResponse(http.StatusTooManyRequests, `{"status":"OVER_QUERY_LIMIT"}`), nil
+		}
+		return createMockResponse(http.StatusOK, `{"status":"OK","results":[{"formatted_address":"Boston, MA, USA","geometry":{"location":{"lat":42.36,"lng":-71.06}}}]}`), nil
+	}}}
+	WithAPIKeys("a", "b")(handler)
+
+	if _, err := handler.callGeocode(context.Background(), url.Values{"address": {"Boston"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"a", "b"}; !slices.Equal(used, expected) {
+		t.Errorf("expected keys %q, got %q", expected, used)
+	}
+}
@@ -0,0 +1,126 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// requireStringArguments returns the named string arguments in order. Every
+// missing argument is reported in a single error instead of failing on the
+// first one, so clients learn everything the tool expects at once.
+func requireStringArguments(request mcp.CallToolRequest, tool string, keys ...string) ([]string, error) {
+	args := request.GetArguments()
+	values := make([]string, len(keys))
+
+	var missing []string
+	for i, key := range keys {
+		value, ok := args[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("argument %q for %s must be a string", key, tool)
+		}
+		values[i] = str
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments for %s: %s", tool, strings.Join(missing, ", "))
+	}
+
+	return values, nil
+}
+
+// requireStringSliceArguments is the array counterpart of
+// requireStringArguments.
+func requireStringSliceArguments(request mcp.CallToolRequest, tool string, keys ...string) ([][]string, error) {
+	args := request.GetArguments()
+	values := make([][]string, len(keys))
+
+	var missing []string
+	for i, key := range keys {
+		if _, ok := args[key]; !ok {
+			missing = append(missing, key)
+			continue
+		}
+		slice, err := request.RequireStringSlice(key)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q for %s must be an array of strings", key, tool)
+		}
+		values[i] = slice
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments for %s: %s", tool, strings.Join(missing, ", "))
+	}
+
+	return values, nil
+}
+
+// optionalStringSliceArgument returns the named array argument, or nil when
+// it is absent.
+func optionalStringSliceArgument(request mcp.CallToolRequest, tool string, key string) ([]string, error) {
+	if _, ok := request.GetArguments()[key]; !ok {
+		return nil, nil
+	}
+	slice, err := request.RequireStringSlice(key)
+	if err != nil {
+		return nil, fmt.Errorf("argument %q for %s must be an array of strings", key, tool)
+	}
+	return slice, nil
+}
+
+// requireFloatArguments is the numeric counterpart of
+// requireStringArguments. Numbers given as strings are accepted.
+func requireFloatArguments(request mcp.CallToolRequest, tool string, keys ...string) ([]float64, error) {
+	args := request.GetArguments()
+	values := make([]float64, len(keys))
+
+	var missing []string
+	for i, key := range keys {
+		if _, ok := args[key]; !ok {
+			missing = append(missing, key)
+			continue
+		}
+		value, err := request.RequireFloat(key)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q for %s must be a number", key, tool)
+		}
+		values[i] = value
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments for %s: %s", tool, strings.Join(missing, ", "))
+	}
+
+	return values, nil
+}
+
+// optionalFloatArgument returns the named numeric argument, or nil when it
+// is absent.
+func optionalFloatArgument(request mcp.CallToolRequest, tool string, key string) (*float64, error) {
+	if _, ok := request.GetArguments()[key]; !ok {
+		return nil, nil
+	}
+	value, err := request.RequireFloat(key)
+	if err != nil {
+		return nil, fmt.Errorf("argument %q for %s must be a number", key, tool)
+	}
+	return &value, nil
+}
+
+// enumArgument returns an optional string argument holding an API enum
+// value, such as a travel mode. Input is trimmed and uppercased so callers
+// may write "drive" or "Drive"; validation of the value is left to the
+// caller.
+func enumArgument(request mcp.CallToolRequest, key, defaultValue string) string {
+	return normalizeEnum(request.GetString(key, defaultValue))
+}
+
+func normalizeEnum(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
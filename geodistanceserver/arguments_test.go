@@ -0,0 +1,76 @@
+package geodistanceserver
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestRequireStringArguments(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "all present",
+			args: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+			},
+			expected: []string{"New York", "Los Angeles"},
+		},
+		{
+			name:        "no arguments",
+			args:        nil,
+			expectedErr: "missing required arguments for calculate_distance: originAddress, destinationAddress",
+		},
+		{
+			name: "one missing",
+			args: map[string]interface{}{
+				"originAddress": "New York",
+			},
+			expectedErr: "missing required arguments for calculate_distance: destinationAddress",
+		},
+		{
+			name: "wrong type",
+			args: map[string]interface{}{
+				"originAddress":      42,
+				"destinationAddress": "Los Angeles",
+			},
+			expectedErr: `argument "originAddress" for calculate_distance must be a string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "calculate_distance",
+					Arguments: tt.args,
+				},
+			}
+
+			values, err := requireStringArguments(request, "calculate_distance", "originAddress", "destinationAddress")
+
+			if tt.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if err.Error() != tt.expectedErr {
+					t.Errorf("expected error %q, got %q", tt.expectedErr, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, v := range tt.expected {
+				if values[i] != v {
+					t.Errorf("expected value %q at %d, got %q", v, i, values[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	avoidTolls    = "tolls"
+	avoidHighways = "highways"
+	avoidFerries  = "ferries"
+	avoidIndoor   = "indoor"
+)
+
+// applyAvoidList sets the route modifiers named by a comma-separated avoid
+// argument such as "tolls,ferries". Flags already set by the avoidTolls,
+// avoidHighways, avoidFerries and avoidIndoor arguments are kept, so both
+// forms may be combined. Tokens are case-insensitive and blank entries are
+// ignored.
+func applyAvoidList(modifiers *RouteModifiers, value string) error {
+	for _, token := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "":
+		case avoidTolls:
+			modifiers.AvoidTolls = true
+		case avoidHighways:
+			modifiers.AvoidHighways = true
+		case avoidFerries:
+			modifiers.AvoidFerries = true
+		case avoidIndoor:
+			modifiers.AvoidIndoor = true
+		default:
+			return fmt.Errorf("invalid avoid token %q: must be %s, %s, %s or %s",
+				strings.TrimSpace(token), avoidTolls, avoidHighways, avoidFerries, avoidIndoor)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package geodistanceserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestApplyAvoidList(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  RouteModifiers
+		expectErr string
+	}{
+		{name: "empty", value: "", expected: RouteModifiers{}},
+		{name: "single token", value: "tolls", expected: RouteModifiers{AvoidTolls: true}},
+		{
+			name:     "several tokens",
+			value:    "tolls,ferries,highways",
+			expected: RouteModifiers{AvoidTolls: true, AvoidFerries: true, AvoidHighways: true},
+		},
+		{
+			name:     "case and whitespace",
+			value:    " Tolls , HIGHWAYS,,",
+			expected: RouteModifiers{AvoidTolls: true, AvoidHighways: true},
+		},
+		{name: "indoor", value: "indoor", expected: RouteModifiers{AvoidIndoor: true}},
+		{name: "unknown token", value: "tolls,tunnels", expectErr: `invalid avoid token "tunnels"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var modifiers RouteModifiers
+			err := applyAvoidList(&modifiers, tt.value)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if modifiers != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, modifiers)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_parseRouteOptions_Avoid(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  RouteModifiers
+		expectErr string
+	}{
+		{
+			name:     "merged with booleans",
+			args:     map[string]interface{}{"avoid": "ferries", "avoidTolls": true},
+			expected: RouteModifiers{AvoidTolls: true, AvoidFerries: true},
+		},
+		{
+			name:     "boolean false does not clear a token",
+			args:     map[string]interface{}{"avoid": "highways", "avoidHighways": false},
+			expected: RouteModifiers{AvoidHighways: true},
+		},
+		{
+			name:      "indoor outside WALK",
+			args:      map[string]interface{}{"avoid": "indoor"},
+			expectErr: "avoidIndoor is only supported for WALK",
+		},
+		{
+			name:      "unknown token",
+			args:      map[string]interface{}{"avoid": "bridges"},
+			expectErr: `invalid avoid token "bridges"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			opts, err := handler.parseRouteOptions(request)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.RouteModifiers != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, opts.RouteModifiers)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// circuitBreaker stops calling the Routes API after threshold consecutive
+// failures. While open, calls fail fast with ErrCircuitOpen; once cooldown
+// has elapsed a single probe call is let through, and its outcome either
+// closes the breaker or reopens it for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may proceed. A nil breaker allows every call.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		// A probe is already in flight
+		return ErrCircuitOpen
+	default:
+		return nil
+	}
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !isBreakerFailure(err) {
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// isBreakerFailure reports whether err suggests the API is unavailable:
+// a server error, throttling, a timeout, or a transport failure. Client
+// errors such as an unknown address mean the API answered, and a caller
+// canceling its own request says nothing about the API either.
+func isBreakerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if isServerError(err) || errorStatus(err) == http.StatusTooManyRequests {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
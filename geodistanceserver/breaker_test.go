@@ -0,0 +1,143 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	serverErr := &APIError{Code: 503, Status: "UNAVAILABLE", HTTPStatus: http.StatusServiceUnavailable}
+
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	call := func(err error) error {
+		if allowErr := b.allow(); allowErr != nil {
+			return allowErr
+		}
+		b.record(err)
+		return err
+	}
+
+	// Failures below the threshold keep the breaker closed
+	if err := call(serverErr); !errors.Is(err, serverErr) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+	if err := call(serverErr); !errors.Is(err, serverErr) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+
+	// The threshold is reached, so calls now short-circuit
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// After the cooldown a single probe is allowed, and it fails
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected a probe after the cooldown, got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected only one probe, got %v", err)
+	}
+	b.record(serverErr)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a failed probe to reopen the breaker, got %v", err)
+	}
+
+	// The next probe succeeds and the breaker recovers
+	now = now.Add(time.Minute)
+	if err := call(nil); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if err := call(nil); err != nil {
+		t.Fatalf("expected the breaker to be closed, got %v", err)
+	}
+
+	// Failure counting restarts after recovery
+	if err := call(serverErr); !errors.Is(err, serverErr) {
+		t.Fatalf("expected the API error, got %v", err)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected one failure to leave the breaker closed, got %v", err)
+	}
+}
+
+func TestIsBreakerFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "success", err: nil, expected: false},
+		{name: "server error", err: &httpStatusError{StatusCode: http.StatusBadGateway}, expected: true},
+		{name: "throttled", err: &APIError{Status: "RESOURCE_EXHAUSTED", HTTPStatus: http.StatusTooManyRequests}, expected: true},
+		{name: "client error", err: &APIError{Status: "INVALID_ARGUMENT", HTTPStatus: http.StatusBadRequest}, expected: false},
+		{name: "transport error", err: fmt.Errorf("failed to execute request: %w", &url.Error{Op: "Post", URL: "https://routes.test", Err: errors.New("connection refused")}), expected: true},
+		{name: "timeout", err: context.DeadlineExceeded, expected: true},
+		{name: "caller canceled", err: context.Canceled, expected: false},
+		{name: "no routes", err: errors.New("no routes found in response"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBreakerFailure(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_callDistanceMatrix_CircuitBreaker(t *testing.T) {
+	var calls int
+	healthy := false
+	handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		if healthy {
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}
+		return createMockResponse(http.StatusServiceUnavailable, `{"error":{"code":503,"message":"backend down","status":"UNAVAILABLE"}}`), nil
+	}}, WithCircuitBreaker(3, time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Now()
+	handler.breaker.now = func() time.Time { return now }
+
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Boston"}}
+	call := func() error {
+		_, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{})
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := call(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: expected an API error, got %v", i+1, err)
+		}
+	}
+	if err := call(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected the open breaker to skip the API, got %d calls", calls)
+	}
+
+	healthy = true
+	now = now.Add(time.Minute)
+	if err := call(); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if err := call(); err != nil {
+		t.Fatalf("expected the breaker to stay closed, got %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("expected 5 API calls, got %d", calls)
+	}
+}
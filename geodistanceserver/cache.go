@@ -0,0 +1,137 @@
+package geodistanceserver
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// routeCache is a size-bounded LRU cache of parsed Routes API responses.
+// Entries older than ttl are treated as misses; a non-positive ttl keeps
+// entries until they are evicted.
+type routeCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	items    map[string]*list.Element
+	now      func() time.Time
+
+	// file, when set, receives every update; see persistTo
+	file   string
+	logger *slog.Logger
+}
+
+type cacheEntry struct {
+	key      string
+	value    *ResponseBody
+	storedAt time.Time
+}
+
+func newRouteCache(capacity int, ttl time.Duration) *routeCache {
+	return &routeCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func (c *routeCache) get(key string) (*ResponseBody, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && c.now().Sub(entry.storedAt) > c.ttl {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (c *routeCache) add(key string, value *ResponseBody) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.storedAt = c.now()
+		c.order.MoveToFront(elem)
+		c.save()
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, value: value, storedAt: c.now()})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+	c.save()
+}
+
+// clear drops the entries held in memory, leaving any cache file intact
+// for the next process.
+func (c *routeCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.items)
+}
+
+func (c *routeCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// routeCacheKey identifies a request by its normalized origins and
+// destinations, travel mode, and routing preference. The remaining request
+// options are appended so that, for example, a toll-avoiding route is
+// never served for a request that allows tolls.
+func routeCacheKey(body *RequestBody) string {
+	var b strings.Builder
+	for _, origin := range body.Origins {
+		b.WriteString(waypointCacheKey(origin.Address, origin.Location))
+		b.WriteByte('|')
+	}
+	b.WriteString("->")
+	for _, destination := range body.Destinations {
+		b.WriteString(waypointCacheKey(destination.Address, destination.Location))
+		b.WriteByte('|')
+	}
+	b.WriteString(body.TravelMode)
+	b.WriteByte('|')
+	b.WriteString(body.RoutingPreference)
+	b.WriteByte('|')
+
+	rest := *body
+	rest.Origins, rest.Destinations = nil, nil
+	rest.TravelMode, rest.RoutingPreference = "", ""
+	options, _ := json.Marshal(rest)
+	b.Write(options)
+
+	return b.String()
+}
+
+func waypointCacheKey(address string, location *Location) string {
+	if location != nil {
+		return fmt.Sprintf("@%v,%v", location.LatLng.Latitude, location.LatLng.Longitude)
+	}
+	return canonicalAddress(address)
+}
@@ -0,0 +1,162 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRouteCache_LRUEviction(t *testing.T) {
+	cache := newRouteCache(2, 0)
+
+	cache.add("a", &ResponseBody{Routes: []Route{{DistanceMeters: 1}}})
+	cache.add("b", &ResponseBody{Routes: []Route{{DistanceMeters: 2}}})
+
+	// Touch "a" so "b" becomes the least recently used
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected hit for a")
+	}
+	cache.add("c", &ResponseBody{Routes: []Route{{DistanceMeters: 3}}})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("expected a to survive eviction")
+	}
+	if _, ok := cache.get("c"); !ok {
+		t.Error("expected c to be cached")
+	}
+	if cache.len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.len())
+	}
+}
+
+func TestRouteCache_TTL(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := newRouteCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.add("a", &ResponseBody{})
+
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.get("a"); !ok {
+		t.Error("expected hit within TTL")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("a"); ok {
+		t.Error("expected miss after TTL")
+	}
+	if cache.len() != 0 {
+		t.Errorf("expected expired entry to be removed, got %d entries", cache.len())
+	}
+}
+
+func TestRouteCacheKey(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	build := func(origin, destination string, opts routeOptions) string {
+		return routeCacheKey(handler.buildRequestBody(
+			[]Origin{{Address: origin}}, []Destination{{Address: destination}}, opts))
+	}
+
+	base := build("New York", "Los Angeles", routeOptions{})
+
+	if build("  new   york ", "LOS ANGELES", routeOptions{}) != base {
+		t.Error("expected normalized addresses to share a key")
+	}
+	if build("Los Angeles", "New York", routeOptions{}) == base {
+		t.Error("expected reversed pair to use a different key")
+	}
+	if build("New York", "Los Angeles", routeOptions{RoutingPreference: routingPreferenceTrafficUnaware}) == base {
+		t.Error("expected routing preference to be part of the key")
+	}
+	if build("New York", "Los Angeles", routeOptions{RouteModifiers: RouteModifiers{AvoidTolls: true}}) == base {
+		t.Error("expected route modifiers to be part of the key")
+	}
+}
+
+func TestGeodistanceHandler_callDistanceMatrix_Cache(t *testing.T) {
+	var calls int
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+	WithCache(10, time.Minute)(handler)
+
+	call := func(origin, destination string) {
+		t.Helper()
+		if _, err := handler.callDistanceMatrix(context.Background(),
+			[]Origin{{Address: origin}}, []Destination{{Address: destination}}, routeOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	call("New York", "Los Angeles")
+	call("New York", "Los Angeles")
+	if calls != 1 {
+		t.Errorf("expected identical call to be served from cache, got %d API calls", calls)
+	}
+
+	call("New York", "Chicago")
+	if calls != 2 {
+		t.Errorf("expected a different pair to hit the API, got %d API calls", calls)
+	}
+}
+
+func TestGeodistanceHandler_callDistanceMatrix_CacheSkipsErrors(t *testing.T) {
+	var calls int
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			return createMockResponse(http.StatusInternalServerError, "boom"), nil
+		}},
+	}
+	WithCache(10, time.Minute)(handler)
+
+	for i := 0; i < 2; i++ {
+		handler.callDistanceMatrix(context.Background(),
+			[]Origin{{Address: "New York"}}, []Destination{{Address: "Los Angeles"}}, routeOptions{})
+	}
+	if calls != 2 {
+		t.Errorf("expected failures not to be cached, got %d API calls", calls)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	WithCache(5, time.Hour)(handler)
+	if handler.cache == nil || handler.cache.capacity != 5 || handler.cache.ttl != time.Hour {
+		t.Errorf("cache not configured: %+v", handler.cache)
+	}
+
+	WithCache(0, time.Hour)(handler)
+	if handler.cache != nil {
+		t.Error("expected non-positive size to disable the cache")
+	}
+}
+
+func TestRouteCacheKey_Coordinates(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	at := func(lat, lng float64) []Origin {
+		return []Origin{{Location: &Location{LatLng: LatLng{Latitude: lat, Longitude: lng}}}}
+	}
+	destinations := []Destination{{Address: "Lincoln, NE"}}
+
+	a := routeCacheKey(handler.buildRequestBody(at(41.2565, -95.9345), destinations, routeOptions{}))
+	b := routeCacheKey(handler.buildRequestBody(at(41.2565, -95.9345), destinations, routeOptions{}))
+	c := routeCacheKey(handler.buildRequestBody(at(41.2600, -95.9345), destinations, routeOptions{}))
+
+	if a != b {
+		t.Errorf("expected identical coordinates to share a key: %q vs %q", a, b)
+	}
+	if a == c {
+		t.Errorf("expected different coordinates to have different keys, both %q", a)
+	}
+}
@@ -0,0 +1,151 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrorCategory classifies a failure for programmatic handling, so callers
+// need not match error strings.
+type ErrorCategory int
+
+const (
+	// CategoryInternal is a failure of the server itself, and the category
+	// of any error not otherwise classified.
+	CategoryInternal ErrorCategory = iota
+	// CategoryValidation is an invalid argument, rejected locally or by
+	// the API.
+	CategoryValidation
+	// CategoryAuth is a missing, rejected or unauthorized API key.
+	CategoryAuth
+	// CategoryRateLimited is a call refused for exceeding a quota.
+	CategoryRateLimited
+	// CategoryNetworkTimeout is a call that timed out or failed to reach
+	// the API.
+	CategoryNetworkTimeout
+	// CategoryUpstream is an API-side failure, such as a 5xx response or
+	// an open circuit breaker.
+	CategoryUpstream
+	// CategoryNoRoute is a successful call that found no route or place.
+	CategoryNoRoute
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryValidation:
+		return "validation"
+	case CategoryAuth:
+		return "auth"
+	case CategoryRateLimited:
+		return "rate_limited"
+	case CategoryNetworkTimeout:
+		return "network_timeout"
+	case CategoryUpstream:
+		return "upstream"
+	case CategoryNoRoute:
+		return "no_route"
+	default:
+		return "internal"
+	}
+}
+
+// CategorizedError is an error tagged with its ErrorCategory. The tool
+// handlers and ComputeDistance return errors of this type.
+type CategorizedError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+func (e *CategorizedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CategorizedError) Unwrap() error {
+	return e.Err
+}
+
+// CategoryOf returns the category of a non-nil err: the one it was tagged
+// with, or else one inferred from the sentinel errors and API responses
+// in its chain.
+func CategoryOf(err error) ErrorCategory {
+	var categorized *CategorizedError
+	if errors.As(err, &categorized) {
+		return categorized.Category
+	}
+
+	switch {
+	case errors.Is(err, ErrMissingAPIKey), errors.Is(err, ErrUnauthorized):
+		return CategoryAuth
+	case errors.Is(err, ErrIdenticalAddresses):
+		return CategoryValidation
+	case errors.Is(err, ErrNoRoute), errors.Is(err, errNoGeocodeResults):
+		return CategoryNoRoute
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrResponseTooLarge), errors.Is(err, ErrSuspiciousZeroDistance):
+		return CategoryUpstream
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryNetworkTimeout
+	}
+
+	// Gateways may wrap an API error in a 200, so the envelope status is
+	// consulted before the HTTP status
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case "UNAUTHENTICATED", "PERMISSION_DENIED":
+			return CategoryAuth
+		case "RESOURCE_EXHAUSTED":
+			return CategoryRateLimited
+		case "INVALID_ARGUMENT", "FAILED_PRECONDITION", "OUT_OF_RANGE":
+			return CategoryValidation
+		}
+	}
+	switch status := errorStatus(err); {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return CategoryAuth
+	case status == http.StatusTooManyRequests:
+		return CategoryRateLimited
+	case status >= http.StatusInternalServerError:
+		return CategoryUpstream
+	case status >= http.StatusBadRequest:
+		return CategoryValidation
+	case status != 0:
+		return CategoryUpstream
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return CategoryNetworkTimeout
+	}
+	return CategoryInternal
+}
+
+// invalidArgument tags a locally detected input error.
+func invalidArgument(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CategorizedError{Category: CategoryValidation, Err: err}
+}
+
+// categorize tags err with its inferred category, leaving nil and already
+// tagged errors unchanged.
+func categorize(err error) error {
+	var categorized *CategorizedError
+	if err == nil || errors.As(err, &categorized) {
+		return err
+	}
+	return &CategorizedError{Category: CategoryOf(err), Err: err}
+}
+
+// withErrorCategory makes a tool handler return only categorized errors.
+func withErrorCategory(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		return result, categorize(err)
+	}
+}
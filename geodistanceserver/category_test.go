@@ -0,0 +1,120 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCategoryOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCategory
+	}{
+		{name: "tagged", err: invalidArgument(fmt.Errorf("origin address cannot be empty")), expected: CategoryValidation},
+		{name: "identical addresses", err: fmt.Errorf("%w: %q", ErrIdenticalAddresses, "Omaha"), expected: CategoryValidation},
+		{name: "missing key", err: ErrMissingAPIKey, expected: CategoryAuth},
+		{name: "unauthorized status", err: &httpStatusError{StatusCode: http.StatusUnauthorized}, expected: CategoryAuth},
+		{name: "permission denied", err: &APIError{Status: "PERMISSION_DENIED", HTTPStatus: http.StatusForbidden}, expected: CategoryAuth},
+		{name: "throttled", err: &APIError{Status: "RESOURCE_EXHAUSTED", HTTPStatus: http.StatusTooManyRequests}, expected: CategoryRateLimited},
+		{name: "throttled inside a 200", err: &APIError{Status: "RESOURCE_EXHAUSTED", HTTPStatus: http.StatusOK}, expected: CategoryRateLimited},
+		{name: "invalid argument", err: &APIError{Status: "INVALID_ARGUMENT", HTTPStatus: http.StatusBadRequest}, expected: CategoryValidation},
+		{name: "server error", err: &httpStatusError{StatusCode: http.StatusBadGateway}, expected: CategoryUpstream},
+		{name: "circuit open", err: ErrCircuitOpen, expected: CategoryUpstream},
+		{name: "no route", err: fmt.Errorf("outbound leg: %w", ErrNoRoute), expected: CategoryNoRoute},
+		{name: "deadline", err: fmt.Errorf("failed to execute request: %w", context.DeadlineExceeded), expected: CategoryNetworkTimeout},
+		{name: "network", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, expected: CategoryNetworkTimeout},
+		{name: "unclassified", err: errors.New("failed to marshal json"), expected: CategoryInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CategoryOf(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithErrorCategory(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		status   int
+		body     string
+		expected ErrorCategory
+	}{
+		{
+			name:     "bad input",
+			args:     map[string]interface{}{"originAddress": "Omaha, NE", "destinationAddress": "Lincoln, NE", "units": "FURLONGS"},
+			expected: CategoryValidation,
+		},
+		{
+			name:     "rejected key",
+			status:   http.StatusUnauthorized,
+			body:     `{"error":{"code":401,"message":"API key not valid.","status":"UNAUTHENTICATED"}}`,
+			expected: CategoryAuth,
+		},
+		{
+			name:     "quota exceeded",
+			status:   http.StatusTooManyRequests,
+			body:     `{"error":{"code":429,"message":"Quota exceeded.","status":"RESOURCE_EXHAUSTED"}}`,
+			expected: CategoryRateLimited,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			body:     `{"error":{"code":500,"message":"Internal error.","status":"INTERNAL"}}`,
+			expected: CategoryUpstream,
+		},
+		{
+			name:     "empty routes",
+			status:   http.StatusOK,
+			body:     `{"routes":[]}`,
+			expected: CategoryNoRoute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(tt.status, tt.body), nil
+				}},
+			}
+
+			args := tt.args
+			if args == nil {
+				args = map[string]interface{}{"originAddress": "Omaha, NE", "destinationAddress": "Lincoln, NE"}
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: args},
+			}
+
+			_, err := withErrorCategory(handler.handleDistanceCalculation)(context.Background(), request)
+			var categorized *CategorizedError
+			if !errors.As(err, &categorized) {
+				t.Fatalf("expected a *CategorizedError, got %T: %v", err, err)
+			}
+			if categorized.Category != tt.expected {
+				t.Errorf("expected category %v, got %v (%v)", tt.expected, categorized.Category, err)
+			}
+		})
+	}
+}
+
+func TestErrorCategory_String(t *testing.T) {
+	if got := CategoryRateLimited.String(); got != "rate_limited" {
+		t.Errorf("expected rate_limited, got %s", got)
+	}
+	if got := ErrorCategory(99).String(); got != "internal" {
+		t.Errorf("expected internal for an unknown category, got %s", got)
+	}
+}
@@ -0,0 +1,26 @@
+package geodistanceserver
+
+// idleConnectionCloser is implemented by *http.Client.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// Close releases the handler's resources: cached routes are dropped and,
+// when the handler built its own HTTP client, idle keep-alive connections
+// are closed. Requests already in flight complete normally; later API
+// calls fail with ErrHandlerClosed. Close is safe to call more than once.
+func (gh *GeodistanceHandler) Close() error {
+	if !gh.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	if gh.cache != nil {
+		gh.cache.clear()
+	}
+	if gh.ownsClient {
+		if closer, ok := gh.client.(idleConnectionCloser); ok {
+			closer.CloseIdleConnections()
+		}
+	}
+	return nil
+}
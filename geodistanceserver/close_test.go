@@ -0,0 +1,100 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGeodistanceHandler_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(createValidAPIResponse()))
+	}))
+	defer server.Close()
+
+	baseline := runtime.NumGoroutine()
+
+	handler, err := NewGeodistanceHandler(WithAPIKey("test-key"), WithBaseURL(server.URL), WithCache(10, time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Boston"}}
+	if _, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.cache.len() != 1 {
+		t.Fatalf("expected a cached route, got %d", handler.cache.len())
+	}
+
+	if err := handler.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+	if err := handler.Close(); err != nil {
+		t.Fatalf("second Close should be a no-op, got %v", err)
+	}
+
+	if handler.cache.len() != 0 {
+		t.Errorf("expected Close to drop cached routes, got %d", handler.cache.len())
+	}
+
+	// Keep-alive connections hold reader/writer goroutines until closed
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("goroutines leaked after Close: %d running, %d before", n, baseline)
+	}
+
+	_, err = handler.callDistanceMatrix(context.Background(), origins, []Destination{{Address: "Denver"}}, routeOptions{})
+	if !errors.Is(err, ErrHandlerClosed) {
+		t.Errorf("expected ErrHandlerClosed after Close, got %v", err)
+	}
+}
+
+func TestGeodistanceHandler_Close_SuppliedClient(t *testing.T) {
+	closer := &closeRecordingClient{}
+	handler, err := NewGeodistanceHandlerWithKey("test-key", closer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := handler.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if closer.closed {
+		t.Error("Close should not close connections of a caller-supplied client")
+	}
+}
+
+type closeRecordingClient struct {
+	MockHTTPClient
+	closed bool
+}
+
+func (c *closeRecordingClient) CloseIdleConnections() {
+	c.closed = true
+}
+
+func TestNewGeodistanceServer_Close(t *testing.T) {
+	s, err := NewGeodistanceServer(WithAPIKey("test-key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.MCPServer == nil {
+		t.Fatal("expected an MCP server")
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !s.handler.closed.Load() {
+		t.Error("expected Close to close the handler")
+	}
+}
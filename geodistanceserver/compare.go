@@ -0,0 +1,135 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// modeComparison is the outcome of one travel mode of compare_travel_modes.
+type modeComparison struct {
+	mode   string
+	result RouteResult
+	err    error
+}
+
+func (gh *GeodistanceHandler) handleCompareTravelModes(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	ctx = contextWithRequestIDArgument(ctx, request)
+
+	origin, destination, err := parseEndpoints(request, toolCompareTravelModes)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	modes, err := parseTravelModes(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	opts, err := gh.parseRouteOptions(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	if err := gh.validateEndpoints(origin, destination); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	origins := []Origin{{Address: origin.Address, Location: origin.Location}}
+	destinations := []Destination{{Address: destination.Address, Location: destination.Location}}
+
+	comparisons := gh.compareTravelModes(ctx, origins, destinations, modes, opts)
+
+	failed := 0
+	lines := make([]string, 0, len(comparisons))
+	for _, c := range comparisons {
+		if c.err != nil {
+			failed++
+			lines = append(lines, fmt.Sprintf("%s: error: %v", c.mode, c.err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s, Duration: %s",
+			c.mode, formatDistance(roundMeters(c.result.DistanceMeters, opts.RoundMeters), opts.Units, opts.NumberLocale), formatParsedDuration(c.result.Duration, opts.DurationFormat)))
+	}
+	if failed == len(comparisons) {
+		return nil, fmt.Errorf("every travel mode failed:\n%s", strings.Join(lines, "\n"))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: strings.Join(lines, "\n"),
+			},
+		},
+	}, nil
+}
+
+// parseTravelModes reads the travelModes argument, rejecting unknown and
+// repeated modes.
+func parseTravelModes(request mcp.CallToolRequest) ([]string, error) {
+	lists, err := requireStringSliceArguments(request, toolCompareTravelModes, "travelModes")
+	if err != nil {
+		return nil, err
+	}
+	modes := lists[0]
+	for i := range modes {
+		modes[i] = normalizeEnum(modes[i])
+	}
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("at least one travel mode is required")
+	}
+
+	seen := make(map[string]bool, len(modes))
+	for _, mode := range modes {
+		if err := validateTravelMode(mode); err != nil {
+			return nil, err
+		}
+		if seen[mode] {
+			return nil, fmt.Errorf("travel mode %s listed more than once", mode)
+		}
+		seen[mode] = true
+	}
+	return modes, nil
+}
+
+// compareTravelModes routes the same trip once per mode, concurrently. A
+// failing mode is reported in its own comparison rather than aborting the
+// others; results keep the order of modes.
+func (gh *GeodistanceHandler) compareTravelModes(
+	ctx context.Context,
+	origins []Origin,
+	destinations []Destination,
+	modes []string,
+	opts routeOptions,
+) []modeComparison {
+	comparisons := make([]modeComparison, len(modes))
+
+	var wg sync.WaitGroup
+	for i, mode := range modes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			modeOpts := opts
+			modeOpts.TravelMode = mode
+			comparisons[i] = modeComparison{mode: mode}
+
+			responseBody, err := gh.callDistanceMatrix(ctx, origins, destinations, modeOpts)
+			if err != nil {
+				comparisons[i].err = err
+				return
+			}
+			comparisons[i].result, comparisons[i].err = responseBody.BestRoute()
+		}()
+	}
+	wg.Wait()
+
+	return comparisons
+}
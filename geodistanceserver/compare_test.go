@@ -0,0 +1,100 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleCompareTravelModes(t *testing.T) {
+	// DRIVE succeeds, TRANSIT fails and WALK returns no routes
+	doFunc := func(req *http.Request) (*http.Response, error) {
+		var body RequestBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		switch body.TravelMode {
+		case travelModeDrive:
+			return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":94475,"duration":"3288s","routeLabels":["DEFAULT_ROUTE"]}]}`), nil
+		case travelModeWalk:
+			return createMockResponse(http.StatusOK, `{"routes":[]}`), nil
+		default:
+			return createMockResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"No transit service","status":"INVALID_ARGUMENT"}}`), nil
+		}
+	}
+
+	tests := []struct {
+		name         string
+		modes        []interface{}
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name:  "one mode fails",
+			modes: []interface{}{"DRIVE", "TRANSIT"},
+			expectedText: strings.Join([]string{
+				"DRIVE: 94.47 km (94475 meters), Duration: 54m48s",
+				"TRANSIT: error: routes API error (INVALID_ARGUMENT): No transit service",
+			}, "\n"),
+		},
+		{
+			name:      "every mode fails",
+			modes:     []interface{}{"TRANSIT", "WALK"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown mode",
+			modes:     []interface{}{"DRIVE", "HOVERCRAFT"},
+			expectErr: true,
+		},
+		{
+			name:      "repeated mode",
+			modes:     []interface{}{"DRIVE", "DRIVE"},
+			expectErr: true,
+		},
+		{
+			name:      "no modes",
+			modes:     []interface{}{},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: doFunc},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: toolCompareTravelModes,
+					Arguments: map[string]interface{}{
+						"originAddress":      "Omaha, Nebraska",
+						"destinationAddress": "Lincoln, Nebraska",
+						"travelModes":        tt.modes,
+					},
+				},
+			}
+
+			result, err := handler.handleCompareTravelModes(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != tt.expectedText {
+				t.Errorf("expected text:\n%s\ngot:\n%s", tt.expectedText, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CallOption configures a single ComputeDistance call.
+type CallOption func(*routeOptions)
+
+// WithTravelMode sets the travel mode: DRIVE (default), BICYCLE, WALK or
+// TRANSIT.
+func WithTravelMode(mode string) CallOption {
+	return func(o *routeOptions) {
+		o.TravelMode = mode
+	}
+}
+
+// WithRoutingPreference sets the routing preference of a DRIVE or
+// TWO_WHEELER call: TRAFFIC_AWARE (default), TRAFFIC_AWARE_OPTIMAL or
+// TRAFFIC_UNAWARE.
+func WithRoutingPreference(preference string) CallOption {
+	return func(o *routeOptions) {
+		o.RoutingPreference = preference
+	}
+}
+
+// WithDepartureTime requests a traffic-aware ETA for a future departure.
+func WithDepartureTime(departure time.Time) CallOption {
+	return func(o *routeOptions) {
+		o.DepartureTime = departure
+	}
+}
+
+// WithRouteModifiers sets the features the route should avoid.
+func WithRouteModifiers(modifiers RouteModifiers) CallOption {
+	return func(o *routeOptions) {
+		o.RouteModifiers = modifiers
+	}
+}
+
+// WithLanguageCode sets the BCP-47 language of place names and labels.
+func WithLanguageCode(code string) CallOption {
+	return func(o *routeOptions) {
+		o.LanguageCode = code
+	}
+}
+
+// ComputeDistance returns the default route between two addresses. It
+// applies the same validation, caching, rate limiting and fallback as the
+// calculate_distance tool, for Go programs embedding the package.
+func (gh *GeodistanceHandler) ComputeDistance(ctx context.Context, origin, destination string, opts ...CallOption) (*RouteResult, error) {
+	gh.metrics.incCalculations()
+
+	options := routeOptions{
+		TravelMode:        gh.travelModeDefault(),
+		RoutingPreference: routingPreferenceTrafficAware,
+		LanguageCode:      defaultLanguageCode,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.TravelMode = normalizeEnum(options.TravelMode)
+	options.RoutingPreference = normalizeEnum(options.RoutingPreference)
+	if err := validateCallOptions(options); err != nil {
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
+	}
+
+	origin, destination = normalizeAddress(origin), normalizeAddress(destination)
+	if err := gh.validateAddresses(origin, destination); err != nil {
+		if errors.Is(err, ErrIdenticalAddresses) && gh.identicalAddresses == IdenticalAddressesZeroDistance {
+			return bestRoute(zeroDistanceResponse())
+		}
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
+	}
+
+	responseBody, err := gh.callDistanceMatrix(ctx, []Origin{{Address: origin}}, []Destination{{Address: destination}}, options)
+	if err != nil {
+		return nil, categorize(err)
+	}
+	result, err := bestRoute(responseBody)
+	return result, categorize(err)
+}
+
+func validateCallOptions(opts routeOptions) error {
+	if err := validateTravelMode(opts.TravelMode); err != nil {
+		return err
+	}
+	if err := validateRouteModifiers(opts.RouteModifiers, opts.TravelMode); err != nil {
+		return err
+	}
+	if err := validateRoutingPreference(opts.RoutingPreference); err != nil {
+		return err
+	}
+	if err := validateLanguageCode(opts.LanguageCode); err != nil {
+		return err
+	}
+	if !opts.DepartureTime.IsZero() {
+		return validateDepartureTime(opts.DepartureTime, opts.RoutingPreference, time.Now())
+	}
+	return nil
+}
+
+func bestRoute(responseBody *ResponseBody) (*RouteResult, error) {
+	result, err := responseBody.BestRoute()
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,142 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGeodistanceHandler_ComputeDistance(t *testing.T) {
+	const response = `{"routes":[
+		{"distanceMeters":87865,"duration":"4903s","routeLabels":["SHORTER_DISTANCE"]},
+		{"distanceMeters":94475,"duration":"3288s","routeLabels":["DEFAULT_ROUTE"]}
+	]}`
+
+	tests := []struct {
+		name        string
+		origin      string
+		destination string
+		opts        []CallOption
+		handlerOpts []Option
+		mockFunc    func(req *http.Request) (*http.Response, error)
+		expected    *RouteResult
+		expectErr   error
+	}{
+		{
+			name:        "default route",
+			origin:      "  Omaha, Nebraska ",
+			destination: "Lincoln, Nebraska",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				var body RequestBody
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+				if body.Origins[0].Address != "Omaha, Nebraska" || body.TravelMode != travelModeDrive {
+					return nil, fmt.Errorf("unexpected request body: %+v", body)
+				}
+				return createMockResponse(http.StatusOK, response), nil
+			},
+			expected: &RouteResult{DistanceMeters: 94475, Duration: 3288 * time.Second, Labels: []string{"DEFAULT_ROUTE"}},
+		},
+		{
+			name:        "call options",
+			origin:      "Omaha, Nebraska",
+			destination: "Lincoln, Nebraska",
+			opts: []CallOption{
+				WithTravelMode(travelModeBicycle),
+				WithRouteModifiers(RouteModifiers{AvoidFerries: true}),
+				WithLanguageCode("de-DE"),
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				var body RequestBody
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+				if body.TravelMode != travelModeBicycle || body.RoutingPreference != "" || body.LanguageCode != "de-DE" ||
+					body.RouteModifiers == nil || !body.RouteModifiers.AvoidFerries {
+					return nil, fmt.Errorf("call options not applied: %+v", body)
+				}
+				return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":90000,"duration":"16000s"}]}`), nil
+			},
+			expected: &RouteResult{DistanceMeters: 90000, Duration: 16000 * time.Second},
+		},
+		{
+			name:        "identical addresses",
+			origin:      "Omaha",
+			destination: "omaha",
+			expectErr:   ErrIdenticalAddresses,
+		},
+		{
+			name:        "identical addresses in zero-distance mode",
+			origin:      "Omaha",
+			destination: "omaha",
+			handlerOpts: []Option{WithIdenticalAddressBehavior(IdenticalAddressesZeroDistance)},
+			expected:    &RouteResult{Labels: []string{"DEFAULT_ROUTE"}},
+		},
+		{
+			name:        "invalid travel mode",
+			origin:      "Omaha",
+			destination: "Lincoln",
+			opts:        []CallOption{WithTravelMode("HOVERCRAFT")},
+		},
+		{
+			name:        "avoid indoor while driving",
+			origin:      "Omaha",
+			destination: "Lincoln",
+			opts:        []CallOption{WithRouteModifiers(RouteModifiers{AvoidIndoor: true})},
+		},
+		{
+			name:        "past departure time",
+			origin:      "Omaha",
+			destination: "Lincoln",
+			opts:        []CallOption{WithDepartureTime(time.Now().Add(-time.Hour))},
+		},
+		{
+			name:        "API failure",
+			origin:      "Omaha",
+			destination: "Lincoln",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"bad","status":"INVALID_ARGUMENT"}}`), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				t.Error("unexpected API call")
+				return nil, fmt.Errorf("unexpected API call")
+			}}
+			if tt.mockFunc != nil {
+				client.DoFunc = tt.mockFunc
+			}
+			handler, err := NewGeodistanceHandlerWithKey("test-key", client, tt.handlerOpts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := handler.ComputeDistance(context.Background(), tt.origin, tt.destination, tt.opts...)
+
+			if tt.expected == nil {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if tt.expectErr != nil && !errors.Is(err, tt.expectErr) {
+					t.Errorf("expected %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
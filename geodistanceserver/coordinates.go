@@ -0,0 +1,29 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"math"
+)
+
+// validateCoordinates checks that a latitude/longitude pair is finite and
+// within the WGS84 ranges. Every tool accepting coordinates runs it before
+// a request reaches the API, so callers get a specific message instead of
+// an opaque INVALID_ARGUMENT.
+func validateCoordinates(latitude, longitude float64) error {
+	if err := validateCoordinate("latitude", latitude, 90); err != nil {
+		return err
+	}
+	return validateCoordinate("longitude", longitude, 180)
+}
+
+func validateCoordinate(name string, value, bound float64) error {
+	switch {
+	case math.IsNaN(value):
+		return fmt.Errorf("invalid %s: must be a number, got NaN", name)
+	case math.IsInf(value, 0):
+		return fmt.Errorf("invalid %s %v: must be finite", name, value)
+	case value < -bound || value > bound:
+		return fmt.Errorf("invalid %s %v: must be between %v and %v", name, value, -bound, bound)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package geodistanceserver
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidateCoordinates(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  float64
+		longitude float64
+		expectErr string
+	}{
+		{name: "origin", latitude: 0, longitude: 0},
+		{name: "typical", latitude: 41.2565, longitude: -95.9345},
+		{name: "north pole", latitude: 90, longitude: 0},
+		{name: "south pole", latitude: -90, longitude: 0},
+		{name: "antimeridian east", latitude: 0, longitude: 180},
+		{name: "antimeridian west", latitude: 0, longitude: -180},
+		{name: "latitude above range", latitude: 90.000001, longitude: 0, expectErr: "invalid latitude 90.000001: must be between -90 and 90"},
+		{name: "latitude below range", latitude: -91, longitude: 0, expectErr: "invalid latitude -91: must be between -90 and 90"},
+		{name: "longitude above range", latitude: 0, longitude: 180.5, expectErr: "invalid longitude 180.5: must be between -180 and 180"},
+		{name: "longitude below range", latitude: 0, longitude: -181, expectErr: "invalid longitude -181: must be between -180 and 180"},
+		{name: "NaN latitude", latitude: math.NaN(), longitude: 0, expectErr: "invalid latitude: must be a number, got NaN"},
+		{name: "NaN longitude", latitude: 0, longitude: math.NaN(), expectErr: "invalid longitude: must be a number, got NaN"},
+		{name: "+Inf latitude", latitude: math.Inf(1), longitude: 0, expectErr: "invalid latitude +Inf: must be finite"},
+		{name: "-Inf longitude", latitude: 0, longitude: math.Inf(-1), expectErr: "invalid longitude -Inf: must be finite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCoordinates(tt.latitude, tt.longitude)
+
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
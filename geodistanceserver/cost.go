@@ -0,0 +1,74 @@
+package geodistanceserver
+
+import "fmt"
+
+// Routes API billing tiers, from cheapest to most expensive. Every element
+// of a call is billed at the tier of the most expensive feature it uses.
+const (
+	costTierEssentials = "Essentials"
+	costTierPro        = "Pro"
+	costTierEnterprise = "Enterprise"
+)
+
+// maxEssentialsIntermediates is the most intermediate waypoints a route
+// may have before it is billed at the Pro tier.
+const maxEssentialsIntermediates = 10
+
+// costFeatures are the request features that move a call into a higher
+// billing tier.
+type costFeatures struct {
+	RoutingPreference string
+	Intermediates     int
+	RouteModifiers    bool
+	Tolls             bool
+}
+
+// newCostFeatures describes the billable features of a call made with
+// opts, mirroring what buildRequestBody sends.
+func newCostFeatures(opts routeOptions) costFeatures {
+	features := costFeatures{
+		Intermediates:  len(opts.Waypoints),
+		RouteModifiers: opts.RouteModifiers != (RouteModifiers{}),
+		Tolls:          opts.IncludeTolls,
+	}
+	if supportsRoutingPreference(opts.TravelMode) {
+		features.RoutingPreference = opts.RoutingPreference
+		if features.RoutingPreference == "" {
+			features.RoutingPreference = routingPreferenceTrafficAware
+		}
+	}
+	return features
+}
+
+func (f costFeatures) tier() string {
+	switch {
+	case f.RoutingPreference == routingPreferenceTrafficAwareOptimal, f.Tolls:
+		return costTierEnterprise
+	case f.RoutingPreference == routingPreferenceTrafficAware,
+		f.Intermediates > maxEssentialsIntermediates, f.RouteModifiers:
+		return costTierPro
+	default:
+		return costTierEssentials
+	}
+}
+
+// costEstimate approximates the quota a call consumes. It ignores cache
+// hits and retries, so it is an upper bound for a single attempt.
+type costEstimate struct {
+	Elements int
+	Tier     string
+}
+
+// estimateElements returns the elements billed for a matrix of origins by
+// destinations; a single route counts as one element.
+func estimateElements(origins, destinations int, features costFeatures) costEstimate {
+	return costEstimate{Elements: origins * destinations, Tier: features.tier()}
+}
+
+func (c costEstimate) String() string {
+	unit := "elements"
+	if c.Elements == 1 {
+		unit = "element"
+	}
+	return fmt.Sprintf("Estimated usage: %d %s billed at the %s tier (approximate)", c.Elements, unit, c.Tier)
+}
@@ -0,0 +1,165 @@
+package geodistanceserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEstimateElements(t *testing.T) {
+	tests := []struct {
+		name             string
+		origins          int
+		destinations     int
+		opts             routeOptions
+		expectedElements int
+		expectedTier     string
+	}{
+		{
+			name:             "single route defaults to traffic aware",
+			origins:          1,
+			destinations:     1,
+			expectedElements: 1,
+			expectedTier:     costTierPro,
+		},
+		{
+			name:             "2x3 matrix traffic unaware",
+			origins:          2,
+			destinations:     3,
+			opts:             routeOptions{RoutingPreference: routingPreferenceTrafficUnaware},
+			expectedElements: 6,
+			expectedTier:     costTierEssentials,
+		},
+		{
+			name:             "10x10 matrix traffic aware optimal",
+			origins:          10,
+			destinations:     10,
+			opts:             routeOptions{RoutingPreference: routingPreferenceTrafficAwareOptimal},
+			expectedElements: 100,
+			expectedTier:     costTierEnterprise,
+		},
+		{
+			name:             "walking ignores routing preference",
+			origins:          1,
+			destinations:     4,
+			opts:             routeOptions{TravelMode: travelModeWalk, RoutingPreference: routingPreferenceTrafficAwareOptimal},
+			expectedElements: 4,
+			expectedTier:     costTierEssentials,
+		},
+		{
+			name:         "route modifiers",
+			origins:      1,
+			destinations: 1,
+			opts: routeOptions{
+				RoutingPreference: routingPreferenceTrafficUnaware,
+				RouteModifiers:    RouteModifiers{AvoidTolls: true},
+			},
+			expectedElements: 1,
+			expectedTier:     costTierPro,
+		},
+		{
+			name:             "toll estimates",
+			origins:          1,
+			destinations:     1,
+			opts:             routeOptions{RoutingPreference: routingPreferenceTrafficUnaware, IncludeTolls: true},
+			expectedElements: 1,
+			expectedTier:     costTierEnterprise,
+		},
+		{
+			name:             "ten waypoints",
+			origins:          1,
+			destinations:     1,
+			opts:             routeOptions{RoutingPreference: routingPreferenceTrafficUnaware, Waypoints: make([]string, 10)},
+			expectedElements: 1,
+			expectedTier:     costTierEssentials,
+		},
+		{
+			name:             "eleven waypoints",
+			origins:          1,
+			destinations:     1,
+			opts:             routeOptions{RoutingPreference: routingPreferenceTrafficUnaware, Waypoints: make([]string, 11)},
+			expectedElements: 1,
+			expectedTier:     costTierPro,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateElements(tt.origins, tt.destinations, newCostFeatures(tt.opts))
+			if got.Elements != tt.expectedElements {
+				t.Errorf("expected %d elements, got %d", tt.expectedElements, got.Elements)
+			}
+			if got.Tier != tt.expectedTier {
+				t.Errorf("expected tier %s, got %s", tt.expectedTier, got.Tier)
+			}
+		})
+	}
+}
+
+func TestCostEstimate_String(t *testing.T) {
+	tests := []struct {
+		estimate costEstimate
+		expected string
+	}{
+		{costEstimate{Elements: 1, Tier: costTierPro}, "Estimated usage: 1 element billed at the Pro tier (approximate)"},
+		{costEstimate{Elements: 6, Tier: costTierEssentials}, "Estimated usage: 6 elements billed at the Essentials tier (approximate)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.estimate.String(); got != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceMatrix_DebugEstimate(t *testing.T) {
+	var requests int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+	}
+
+	tests := []struct {
+		name     string
+		debug    bool
+		expected string
+	}{
+		{name: "debug off"},
+		{name: "debug on", debug: true, expected: "Estimated usage: 6 elements billed at the Enterprise tier (approximate)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: toolCalculateDistanceMatrix,
+					Arguments: map[string]interface{}{
+						"originAddresses":      []interface{}{"o1", "o2"},
+						"destinationAddresses": []interface{}{"d1", "d2", "d3"},
+						"routingPreference":    routingPreferenceTrafficAwareOptimal,
+						"debug":                tt.debug,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceMatrix(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var texts []string
+			for _, content := range result.Content {
+				texts = append(texts, content.(mcp.TextContent).Text)
+			}
+			all := strings.Join(texts, "\n")
+			if tt.expected != "" && !strings.Contains(all, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, all)
+			}
+			if tt.expected == "" && strings.Contains(all, "Estimated usage") {
+				t.Errorf("expected no estimate without debug, got %q", all)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultMaxCSVRows bounds the pairs accepted by calculate_distances_csv,
+// since each pair costs one matrix element of quota.
+const defaultMaxCSVRows = 100
+
+// csvPair is one origin/destination row of a CSV batch.
+type csvPair struct {
+	Origin      string
+	Destination string
+}
+
+func (gh *GeodistanceHandler) maxCSVRowCount() int {
+	if gh.maxCSVRows <= 0 {
+		return defaultMaxCSVRows
+	}
+	return gh.maxCSVRows
+}
+
+func (gh *GeodistanceHandler) handleDistancesCSV(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	ctx = contextWithRequestIDArgument(ctx, request)
+
+	args, err := requireStringArguments(request, toolCalculateDistancesCSV, "csv")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	pairs, err := parseCSVPairs(args[0], gh.maxCSVRowCount())
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	opts, err := gh.parseRouteOptions(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	for i, pair := range pairs {
+		if err := gh.validateMatrixAddresses([]string{pair.Origin}, []string{pair.Destination}); err != nil {
+			return nil, invalidArgument(fmt.Errorf("row %d: %w", i+1, err))
+		}
+	}
+
+	elements := make([]*MatrixElement, len(pairs))
+	for i, pair := range pairs {
+		result, err := gh.callRouteMatrix(ctx, []string{pair.Origin}, []string{pair.Destination}, opts)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		grid, err := indexMatrix(result, 1, 1)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		elements[i] = grid[0][0]
+	}
+
+	text, err := formatCSVResult(pairs, elements)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+// parseCSVPairs reads origin,destination rows from text. Fields may be
+// quoted per RFC 4180, and a leading origin,destination header row is
+// skipped. Rows are numbered from 1 in errors, counting the header.
+func parseCSVPairs(text string, maxRows int) ([]csvPair, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var pairs []csvPair
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("malformed CSV on line %d: %w", parseErr.Line, parseErr.Err)
+			}
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		origin, destination := normalizeAddress(record[0]), normalizeAddress(record[1])
+		if row == 1 && strings.EqualFold(origin, "origin") && strings.EqualFold(destination, "destination") {
+			continue
+		}
+		if origin == "" || destination == "" {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("malformed CSV on line %d: origin and destination cannot be empty", line)
+		}
+		if len(pairs) == maxRows {
+			return nil, fmt.Errorf("CSV has more than %d rows", maxRows)
+		}
+		pairs = append(pairs, csvPair{Origin: origin, Destination: destination})
+	}
+
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("CSV contains no origin,destination rows")
+	}
+	return pairs, nil
+}
+
+// formatCSVResult renders the pairs with distanceMeters and duration
+// columns appended. Both columns stay empty for pairs without a route, and
+// the duration keeps the API's seconds format (e.g. "180s").
+func formatCSVResult(pairs []csvPair, elements []*MatrixElement) (string, error) {
+	var b strings.Builder
+	writer := csv.NewWriter(&b)
+
+	records := [][]string{{"origin", "destination", "distanceMeters", "duration"}}
+	for i, pair := range pairs {
+		distance, duration := "", ""
+		if element := elements[i]; elementReachable(element) {
+			distance = strconv.Itoa(element.DistanceMeters)
+			duration = element.Duration
+		}
+		records = append(records, []string{pair.Origin, pair.Destination, distance, duration})
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,182 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestParseCSVPairs(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxRows   int
+		expected  []csvPair
+		expectErr string
+	}{
+		{
+			name: "plain rows",
+			text: "New York,Boston\nDenver,Boulder\n",
+			expected: []csvPair{
+				{Origin: "New York", Destination: "Boston"},
+				{Origin: "Denver", Destination: "Boulder"},
+			},
+		},
+		{
+			name: "header row",
+			text: "Origin, Destination\nNew York, Boston",
+			expected: []csvPair{
+				{Origin: "New York", Destination: "Boston"},
+			},
+		},
+		{
+			name: "quoted fields",
+			text: `"1600 Amphitheatre Pkwy, Mountain View, CA","1 Infinite Loop, ""Cupertino"", CA"`,
+			expected: []csvPair{
+				{Origin: "1600 Amphitheatre Pkwy, Mountain View, CA", Destination: `1 Infinite Loop, "Cupertino", CA`},
+			},
+		},
+		{
+			name:      "malformed line",
+			text:      "New York,Boston\nDenver\n",
+			expectErr: "line 2",
+		},
+		{
+			name:      "unterminated quote",
+			text:      "\"New York,Boston\n",
+			expectErr: "malformed CSV",
+		},
+		{
+			name:      "empty field",
+			text:      "New York,Boston\n ,Boulder\n",
+			expectErr: "line 2",
+		},
+		{
+			name:      "header only",
+			text:      "origin,destination\n",
+			expectErr: "no origin,destination rows",
+		},
+		{
+			name:    "row limit with header",
+			text:    "origin,destination\na,b\nc,d",
+			maxRows: 2,
+			expected: []csvPair{
+				{Origin: "a", Destination: "b"},
+				{Origin: "c", Destination: "d"},
+			},
+		},
+		{
+			name:      "too many rows",
+			text:      "a,b\nc,d\ne,f",
+			maxRows:   2,
+			expectErr: "more than 2 rows",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxRows := tt.maxRows
+			if maxRows == 0 {
+				maxRows = defaultMaxCSVRows
+			}
+
+			pairs, err := parseCSVPairs(tt.text, maxRows)
+
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, pairs)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistancesCSV(t *testing.T) {
+	var requests int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: toolCalculateDistancesCSV,
+			Arguments: map[string]interface{}{
+				"csv": "origin,destination\no1,d2\n\"o3\",d4\n",
+			},
+		},
+	}
+
+	result, err := handler.handleDistancesCSV(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"origin,destination,distanceMeters,duration",
+		"o1,d2,1002,60s",
+		"o3,d4,3004,60s",
+	}, "\n")
+	if text := result.Content[0].(mcp.TextContent).Text; text != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, text)
+	}
+	if requests != 2 {
+		t.Errorf("expected one request per row, got %d", requests)
+	}
+}
+
+func TestFormatCSVResult(t *testing.T) {
+	pairs := []csvPair{
+		{Origin: "Omaha, NE", Destination: "Lincoln, NE"},
+		{Origin: "A", Destination: "B"},
+		{Origin: "C", Destination: "D"},
+	}
+	elements := []*MatrixElement{
+		{DistanceMeters: 94475, Duration: "3288s", Condition: conditionRouteExists},
+		{Condition: "ROUTE_NOT_FOUND"},
+		nil,
+	}
+
+	text, err := formatCSVResult(pairs, elements)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"origin,destination,distanceMeters,duration",
+		`"Omaha, NE","Lincoln, NE",94475,3288s`,
+		"A,B,,",
+		"C,D,,",
+	}, "\n")
+	if text != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, text)
+	}
+}
+
+func TestGeodistanceHandler_handleDistancesCSV_APIError(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusInternalServerError, "boom"), nil
+		}},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]interface{}{"csv": "a,b"}},
+	}
+
+	if _, err := handler.handleDistancesCSV(context.Background(), request); err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Errorf("expected row-annotated error, got %v", err)
+	}
+}
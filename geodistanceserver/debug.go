@@ -0,0 +1,27 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDebugBodyBytes bounds the raw response echoed by the debug argument,
+// keeping tool results readable for large alternative-route responses.
+const maxDebugBodyBytes = 4096
+
+// debugResponseText renders the raw API response for the debug argument.
+// The API keys are redacted should the response ever echo one back.
+func (gh *GeodistanceHandler) debugResponseText(responseBody *ResponseBody) string {
+	if len(responseBody.raw) == 0 {
+		return "Raw response: unavailable"
+	}
+
+	raw := string(responseBody.raw)
+	for _, key := range gh.allAPIKeys() {
+		raw = strings.ReplaceAll(raw, key, "[REDACTED]")
+	}
+	if len(raw) > maxDebugBodyBytes {
+		return fmt.Sprintf("Raw response (truncated, %d of %d bytes): %s", maxDebugBodyBytes, len(raw), raw[:maxDebugBodyBytes])
+	}
+	return "Raw response: " + raw
+}
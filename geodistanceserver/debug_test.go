@@ -0,0 +1,102 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleDistanceCalculation_Debug(t *testing.T) {
+	// A response echoing the key must never surface it
+	const response = `{"routes":[{"distanceMeters":1000,"duration":"300s","routeLabels":["DEFAULT_ROUTE"]}],"echo":"secret-key"}`
+
+	tests := []struct {
+		name  string
+		debug bool
+	}{
+		{name: "debug off", debug: false},
+		{name: "debug on", debug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "secret-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(http.StatusOK, response), nil
+				}},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance",
+					Arguments: map[string]interface{}{
+						"originAddress":      "New York",
+						"destinationAddress": "Boston",
+						"debug":              tt.debug,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var texts []string
+			for _, content := range result.Content {
+				texts = append(texts, content.(mcp.TextContent).Text)
+			}
+			all := strings.Join(texts, "\n")
+
+			if strings.Contains(all, "Raw response") != tt.debug {
+				t.Errorf("expected raw response present=%v, got %q", tt.debug, all)
+			}
+			if strings.Contains(all, "Estimated usage: 1 element") != tt.debug {
+				t.Errorf("expected usage estimate present=%v, got %q", tt.debug, all)
+			}
+			if tt.debug && !strings.Contains(all, `"distanceMeters":1000`) {
+				t.Errorf("expected raw JSON in output, got %q", all)
+			}
+			if strings.Contains(all, "secret-key") {
+				t.Errorf("API key leaked into output: %q", all)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_debugResponseText(t *testing.T) {
+	handler := &GeodistanceHandler{apiKey: "test-key"}
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		contains string
+		maxLen   int
+	}{
+		{name: "unavailable", raw: nil, contains: "unavailable"},
+		{name: "short body", raw: []byte(`{"routes":[]}`), contains: `Raw response: {"routes":[]}`},
+		{
+			name:     "truncated body",
+			raw:      []byte(`{"routes":[` + strings.Repeat(" ", 2*maxDebugBodyBytes) + `]}`),
+			contains: "truncated",
+			maxLen:   maxDebugBodyBytes + 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := handler.debugResponseText(&ResponseBody{raw: tt.raw})
+
+			if !strings.Contains(text, tt.contains) {
+				t.Errorf("expected %q in %q", tt.contains, text)
+			}
+			if tt.maxLen > 0 && len(text) > tt.maxLen {
+				t.Errorf("expected at most %d bytes, got %d", tt.maxLen, len(text))
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheFileVersion is bumped whenever the file layout changes; files of
+// another version are ignored and rebuilt.
+const cacheFileVersion = 1
+
+type cacheFileContents struct {
+	Version int              `json:"version"`
+	Entries []cacheFileEntry `json:"entries"`
+}
+
+// cacheFileEntry is a cached response as persisted. Its TrafficUnavailable
+// lists the indices of routes labelled as missing traffic data, which the
+// JSON form of a Route does not carry.
+type cacheFileEntry struct {
+	Key                string        `json:"key"`
+	Value              *ResponseBody `json:"value"`
+	StoredAt           time.Time     `json:"storedAt"`
+	TrafficUnavailable []int         `json:"trafficUnavailable,omitempty"`
+}
+
+// persistTo makes c write through to path, first loading the entries a
+// previous process left there. A missing file starts an empty cache; an
+// unreadable or corrupt one is ignored and replaced on the next write.
+func (c *routeCache) persistTo(path string, logger *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.file = path
+	c.logger = logger
+
+	entries, err := readCacheFile(path)
+	if err != nil {
+		c.logPersistError("ignoring unreadable route cache file", err)
+		return
+	}
+
+	// Entries are stored oldest first, so pushing each to the front
+	// restores the LRU order
+	for _, entry := range entries {
+		if entry.Value == nil || (c.ttl > 0 && c.now().Sub(entry.StoredAt) > c.ttl) {
+			continue
+		}
+		for _, i := range entry.TrafficUnavailable {
+			if i >= 0 && i < len(entry.Value.Routes) {
+				entry.Value.Routes[i].trafficUnavailable = true
+			}
+		}
+		if elem, ok := c.items[entry.Key]; ok {
+			c.order.Remove(elem)
+		}
+		c.items[entry.Key] = c.order.PushFront(&cacheEntry{key: entry.Key, value: entry.Value, storedAt: entry.StoredAt})
+	}
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func readCacheFile(path string) ([]cacheFileEntry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var contents cacheFileContents
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal route cache file: %w", err)
+	}
+	if contents.Version != cacheFileVersion {
+		return nil, fmt.Errorf("unsupported route cache file version %d", contents.Version)
+	}
+	return contents.Entries, nil
+}
+
+// save writes every entry to c.file. It replaces the file atomically, so a
+// process that crashes mid-write leaves the previous contents behind. The
+// caller must hold c.mu.
+func (c *routeCache) save() {
+	if c.file == "" {
+		return
+	}
+
+	contents := cacheFileContents{Version: cacheFileVersion, Entries: make([]cacheFileEntry, 0, c.order.Len())}
+	for elem := c.order.Back(); elem != nil; elem = elem.Prev() {
+		entry := elem.Value.(*cacheEntry)
+		persisted := cacheFileEntry{Key: entry.key, Value: entry.value, StoredAt: entry.storedAt}
+		for i, route := range entry.value.Routes {
+			if route.trafficUnavailable {
+				persisted.TrafficUnavailable = append(persisted.TrafficUnavailable, i)
+			}
+		}
+		contents.Entries = append(contents.Entries, persisted)
+	}
+
+	if err := writeCacheFile(c.file, contents); err != nil {
+		c.logPersistError("failed to write route cache file", err)
+	}
+}
+
+func writeCacheFile(path string, contents cacheFileContents) error {
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return fmt.Errorf("failed to marshal route cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *routeCache) logPersistError(msg string, err error) {
+	if c.logger != nil {
+		c.logger.Warn(msg, slog.String("path", c.file), slog.String("error", err.Error()))
+	}
+}
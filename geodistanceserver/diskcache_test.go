@@ -0,0 +1,147 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithCacheFile_SharedAcrossHandlers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	newHandler := func(client HTTPClient) *GeodistanceHandler {
+		t.Helper()
+		handler, err := NewGeodistanceHandler(
+			WithAPIKey("test-key"),
+			WithHTTPClient(client),
+			WithCache(10, time.Hour),
+			WithCacheFile(path),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return handler
+	}
+	call := func(handler *GeodistanceHandler) *ResponseBody {
+		t.Helper()
+		responseBody, err := handler.callDistanceMatrix(context.Background(),
+			[]Origin{{Address: "New York"}}, []Destination{{Address: "Los Angeles"}}, routeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return responseBody
+	}
+
+	var calls int
+	call(newHandler(&MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+	}}))
+	if calls != 1 {
+		t.Fatalf("expected 1 API call, got %d", calls)
+	}
+
+	// A new handler stands in for a restarted process
+	second := newHandler(&MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		t.Error("expected the route to be served from the cache file")
+		return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+	}})
+	responseBody := call(second)
+	if len(responseBody.Routes) != 1 || responseBody.Routes[0].DistanceMeters != 1000 {
+		t.Errorf("unexpected cached response: %+v", responseBody)
+	}
+
+	// Closing drops the in-memory copy but not the file
+	second.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the cache file to survive Close: %v", err)
+	}
+}
+
+func TestRouteCache_PersistTo(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "routes.json")
+
+	cache := newRouteCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.persistTo(path, nil)
+	cache.add("old", &ResponseBody{Routes: []Route{{DistanceMeters: 1}}})
+	now = now.Add(45 * time.Second)
+	cache.add("new", &ResponseBody{Routes: []Route{{DistanceMeters: 2, Duration: "60s", trafficUnavailable: true}}})
+
+	now = now.Add(30 * time.Second)
+	reloaded := newRouteCache(10, time.Minute)
+	reloaded.now = func() time.Time { return now }
+	reloaded.persistTo(path, nil)
+
+	if _, ok := reloaded.get("old"); ok {
+		t.Error("expected the entry past its TTL to be dropped on load")
+	}
+	value, ok := reloaded.get("new")
+	if !ok {
+		t.Fatal("expected the fresh entry to be loaded")
+	}
+	if !value.Routes[0].trafficUnavailable {
+		t.Error("expected the missing-traffic label to be restored")
+	}
+}
+
+func TestRouteCache_PersistToCapacity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+
+	cache := newRouteCache(3, 0)
+	cache.persistTo(path, nil)
+	for _, key := range []string{"a", "b", "c"} {
+		cache.add(key, &ResponseBody{})
+	}
+
+	reloaded := newRouteCache(2, 0)
+	reloaded.persistTo(path, nil)
+	if reloaded.len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", reloaded.len())
+	}
+	if _, ok := reloaded.get("a"); ok {
+		t.Error("expected the least recently used entry to be dropped")
+	}
+}
+
+func TestRouteCache_PersistToCorruptFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{name: "invalid json", contents: "{not json"},
+		{name: "unknown version", contents: `{"version":99,"entries":[{"key":"a","value":{"routes":[]}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "routes.json")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cache := newRouteCache(10, 0)
+			cache.persistTo(path, nil)
+			if cache.len() != 0 {
+				t.Fatalf("expected the corrupt file to be ignored, got %d entries", cache.len())
+			}
+
+			cache.add("b", &ResponseBody{Routes: []Route{{DistanceMeters: 2}}})
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var contents cacheFileContents
+			if err := json.Unmarshal(data, &contents); err != nil {
+				t.Fatalf("expected the file to be rebuilt: %v", err)
+			}
+			if contents.Version != cacheFileVersion || len(contents.Entries) != 1 || contents.Entries[0].Key != "b" {
+				t.Errorf("unexpected rebuilt contents: %+v", contents)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseDuration converts a Routes API duration such as "180s" or "12.5s"
+// into a time.Duration. Values without the trailing "s" are treated as
+// seconds, and Go duration syntax (e.g. "5m") is accepted as a fallback.
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("duration cannot be empty")
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64)
+	if err != nil {
+		d, perr := time.ParseDuration(value)
+		if perr != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("duration %q cannot be negative", value)
+		}
+		return d, nil
+	}
+
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("duration %q cannot be negative", value)
+	}
+
+	return time.Duration(math.Round(seconds * float64(time.Second))), nil
+}
+
+const (
+	durationFormatSeconds      = "SECONDS"
+	durationFormatMinutes      = "MINUTES"
+	durationFormatHoursMinutes = "HOURS_MINUTES"
+	durationFormatHumanized    = "HUMANIZED"
+)
+
+var durationFormats = []string{
+	durationFormatHumanized, durationFormatSeconds, durationFormatMinutes, durationFormatHoursMinutes,
+}
+
+func validateDurationFormat(format string) error {
+	switch format {
+	case durationFormatSeconds, durationFormatMinutes, durationFormatHoursMinutes, durationFormatHumanized:
+		return nil
+	default:
+		return fmt.Errorf("invalid duration format %q: must be %s, %s, %s or %s", format,
+			durationFormatSeconds, durationFormatMinutes, durationFormatHoursMinutes, durationFormatHumanized)
+	}
+}
+
+// formatDuration renders a raw API duration in the requested format,
+// falling back to the raw value when it cannot be parsed.
+func formatDuration(raw string, format string) string {
+	d, err := parseDuration(raw)
+	if err != nil {
+		return raw
+	}
+	return formatParsedDuration(d, format)
+}
+
+// formatParsedDuration renders d in the requested format. Minute-based
+// formats round to the nearest minute.
+func formatParsedDuration(d time.Duration, format string) string {
+	switch format {
+	case durationFormatSeconds:
+		return fmt.Sprintf("%d seconds", int64(d.Round(time.Second)/time.Second))
+	case durationFormatMinutes:
+		return fmt.Sprintf("%d minutes", int64(d.Round(time.Minute)/time.Minute))
+	case durationFormatHoursMinutes:
+		minutes := int64(d.Round(time.Minute) / time.Minute)
+		return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+	default:
+		return d.String()
+	}
+}
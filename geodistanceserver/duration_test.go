@@ -0,0 +1,137 @@
+package geodistanceserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{
+			name:     "google seconds format",
+			input:    "180s",
+			expected: 3 * time.Minute,
+		},
+		{
+			name:     "fractional seconds",
+			input:    "12.5s",
+			expected: 12500 * time.Millisecond,
+		},
+		{
+			name:     "missing trailing s",
+			input:    "3288",
+			expected: 3288 * time.Second,
+		},
+		{
+			name:     "zero seconds",
+			input:    "0s",
+			expected: 0,
+		},
+		{
+			name:     "surrounding whitespace",
+			input:    " 60s ",
+			expected: time.Minute,
+		},
+		{
+			name:     "go duration syntax",
+			input:    "5m",
+			expected: 5 * time.Minute,
+		},
+		{
+			name:      "empty string",
+			input:     "",
+			expectErr: true,
+		},
+		{
+			name:      "malformed input",
+			input:     "abc",
+			expectErr: true,
+		},
+		{
+			name:      "negative seconds",
+			input:     "-5s",
+			expectErr: true,
+		},
+		{
+			name:      "not a number",
+			input:     "NaNs",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDuration(tt.input)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error for %q but got none", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if d != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		format   string
+		expected string
+	}{
+		{name: "humanized", raw: "3288s", format: durationFormatHumanized, expected: "54m48s"},
+		{name: "default is humanized", raw: "3288s", format: "", expected: "54m48s"},
+		{name: "seconds", raw: "3288s", format: durationFormatSeconds, expected: "3288 seconds"},
+		{name: "minutes", raw: "3288s", format: durationFormatMinutes, expected: "55 minutes"},
+		{name: "hours minutes", raw: "3288s", format: durationFormatHoursMinutes, expected: "0:55"},
+		{name: "hours minutes over an hour", raw: "4903s", format: durationFormatHoursMinutes, expected: "1:22"},
+		{name: "unparseable falls back to raw", raw: "garbage", format: durationFormatMinutes, expected: "garbage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDuration(tt.raw, tt.format); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatParsedDuration(t *testing.T) {
+	d := time.Hour + 2*time.Minute + 3*time.Second
+	tests := map[string]string{
+		durationFormatHumanized:    "1h2m3s",
+		durationFormatSeconds:      "3723 seconds",
+		durationFormatMinutes:      "62 minutes",
+		durationFormatHoursMinutes: "1:02",
+	}
+
+	for format, expected := range tests {
+		if got := formatParsedDuration(d, format); got != expected {
+			t.Errorf("%s: expected %q, got %q", format, expected, got)
+		}
+	}
+}
+
+func TestValidateDurationFormat(t *testing.T) {
+	for _, format := range []string{durationFormatSeconds, durationFormatMinutes, durationFormatHoursMinutes, durationFormatHumanized} {
+		if err := validateDurationFormat(format); err != nil {
+			t.Errorf("unexpected error for %s: %v", format, err)
+		}
+	}
+	if err := validateDurationFormat("FORTNIGHTS"); err == nil {
+		t.Error("expected error for invalid duration format")
+	}
+}
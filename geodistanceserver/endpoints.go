@@ -0,0 +1,125 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// buildWaypoint builds a route endpoint from either an address or a
+// latitude/longitude pair. Supplying both, neither, or only one coordinate
+// is rejected.
+func buildWaypoint(address string, lat, lng *float64) (Waypoint, error) {
+	hasCoordinates := lat != nil || lng != nil
+
+	switch {
+	case address != "" && hasCoordinates:
+		return Waypoint{}, fmt.Errorf("specify either an address or coordinates, not both")
+	case hasCoordinates:
+		if lat == nil || lng == nil {
+			return Waypoint{}, fmt.Errorf("both latitude and longitude are required")
+		}
+		if err := validateCoordinates(*lat, *lng); err != nil {
+			return Waypoint{}, err
+		}
+		return Waypoint{Location: &Location{LatLng: LatLng{Latitude: *lat, Longitude: *lng}}}, nil
+	case address != "":
+		return Waypoint{Address: address}, nil
+	default:
+		return Waypoint{}, fmt.Errorf("an address or coordinates are required")
+	}
+}
+
+// parseEndpoints reads the origin and destination of a route, each given as
+// <prefix>Address or <prefix>Latitude/<prefix>Longitude. Endpoints with no
+// arguments at all are reported together, like other missing arguments.
+func parseEndpoints(request mcp.CallToolRequest, tool string) (Waypoint, Waypoint, error) {
+	args := request.GetArguments()
+
+	var missing []string
+	for _, prefix := range []string{"origin", "destination"} {
+		_, hasAddress := args[prefix+"Address"]
+		_, hasLat := args[prefix+"Latitude"]
+		_, hasLng := args[prefix+"Longitude"]
+		if !hasAddress && !hasLat && !hasLng {
+			missing = append(missing, prefix+"Address")
+		}
+	}
+	if len(missing) > 0 {
+		return Waypoint{}, Waypoint{}, fmt.Errorf("missing required arguments for %s: %s", tool, strings.Join(missing, ", "))
+	}
+
+	origin, err := parseEndpoint(request, tool, "origin")
+	if err != nil {
+		return Waypoint{}, Waypoint{}, err
+	}
+	destination, err := parseEndpoint(request, tool, "destination")
+	if err != nil {
+		return Waypoint{}, Waypoint{}, err
+	}
+	return origin, destination, nil
+}
+
+func parseEndpoint(request mcp.CallToolRequest, tool string, prefix string) (Waypoint, error) {
+	args := request.GetArguments()
+
+	var address string
+	if value, ok := args[prefix+"Address"]; ok {
+		str, ok := value.(string)
+		if !ok {
+			return Waypoint{}, fmt.Errorf("argument %q for %s must be a string", prefix+"Address", tool)
+		}
+		address = normalizeAddress(str)
+	}
+
+	lat, err := optionalFloatArgument(request, tool, prefix+"Latitude")
+	if err != nil {
+		return Waypoint{}, err
+	}
+	lng, err := optionalFloatArgument(request, tool, prefix+"Longitude")
+	if err != nil {
+		return Waypoint{}, err
+	}
+
+	// A present but blank address is left to validateEndpoints so it reports
+	// the empty address rather than a missing endpoint
+	if address == "" && lat == nil && lng == nil {
+		return Waypoint{}, nil
+	}
+
+	waypoint, err := buildWaypoint(address, lat, lng)
+	if err != nil {
+		return Waypoint{}, fmt.Errorf("%s: %w", prefix, err)
+	}
+	return waypoint, nil
+}
+
+// validateEndpoints applies validateAddresses when both endpoints are
+// addresses and validateAddress to an address paired with coordinates, and
+// treats identical coordinates like identical addresses.
+func (gh *GeodistanceHandler) validateEndpoints(origin, destination Waypoint) error {
+	if origin.Location == nil && destination.Location == nil {
+		return gh.validateAddresses(origin.Address, destination.Address)
+	}
+	if origin.Location == nil {
+		if origin.Address == "" {
+			return fmt.Errorf("origin address cannot be empty")
+		}
+		if err := gh.validateAddress("origin address", origin.Address); err != nil {
+			return err
+		}
+	}
+	if destination.Location == nil {
+		if destination.Address == "" {
+			return fmt.Errorf("destination address cannot be empty")
+		}
+		if err := gh.validateAddress("destination address", destination.Address); err != nil {
+			return err
+		}
+	}
+	if origin.Location != nil && destination.Location != nil && *origin.Location == *destination.Location {
+		return fmt.Errorf("%w: %v,%v", ErrIdenticalAddresses, origin.Location.LatLng.Latitude, origin.Location.LatLng.Longitude)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBuildWaypoint(t *testing.T) {
+	lat, lng := 41.2565, -95.9345
+	badLat := 95.0
+
+	tests := []struct {
+		name      string
+		address   string
+		lat, lng  *float64
+		expected  Waypoint
+		expectErr bool
+	}{
+		{
+			name:     "address only",
+			address:  "Omaha, NE",
+			expected: Waypoint{Address: "Omaha, NE"},
+		},
+		{
+			name:     "coordinates only",
+			lat:      &lat,
+			lng:      &lng,
+			expected: Waypoint{Location: &Location{LatLng: LatLng{Latitude: lat, Longitude: lng}}},
+		},
+		{
+			name:      "both set",
+			address:   "Omaha, NE",
+			lat:       &lat,
+			lng:       &lng,
+			expectErr: true,
+		},
+		{
+			name:      "none set",
+			expectErr: true,
+		},
+		{
+			name:      "latitude without longitude",
+			lat:       &lat,
+			expectErr: true,
+		},
+		{
+			name:      "out of range coordinates",
+			lat:       &badLat,
+			lng:       &lng,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildWaypoint(tt.address, tt.lat, tt.lng)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_Coordinates(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]interface{}
+		expectErr   bool
+		identical   bool
+		checkOrigin func(t *testing.T, origin Origin)
+	}{
+		{
+			name: "coordinates to address",
+			args: map[string]interface{}{
+				"originLatitude":     41.2565,
+				"originLongitude":    -95.9345,
+				"destinationAddress": "Lincoln, NE",
+			},
+			checkOrigin: func(t *testing.T, origin Origin) {
+				if origin.Address != "" || origin.Location == nil || origin.Location.LatLng.Latitude != 41.2565 {
+					t.Errorf("expected origin location, got %+v", origin)
+				}
+			},
+		},
+		{
+			name: "address and coordinates for the origin",
+			args: map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"originLatitude":     41.2565,
+				"originLongitude":    -95.9345,
+				"destinationAddress": "Lincoln, NE",
+			},
+			expectErr: true,
+		},
+		{
+			name: "identical coordinates",
+			args: map[string]interface{}{
+				"originLatitude":       41.2565,
+				"originLongitude":      -95.9345,
+				"destinationLatitude":  41.2565,
+				"destinationLongitude": -95.9345,
+			},
+			expectErr: true,
+			identical: true,
+		},
+		{
+			name: "origin address over the length limit",
+			args: map[string]interface{}{
+				"originAddress":        strings.Repeat("a", defaultMaxAddressLength+1),
+				"destinationLatitude":  40.8136,
+				"destinationLongitude": -96.7026,
+			},
+			expectErr: true,
+		},
+		{
+			name: "destination address over the length limit",
+			args: map[string]interface{}{
+				"originLatitude":     41.2565,
+				"originLongitude":    -95.9345,
+				"destinationAddress": strings.Repeat("a", defaultMaxAddressLength+1),
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing destination",
+			args: map[string]interface{}{
+				"originLatitude":  41.2565,
+				"originLongitude": -95.9345,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					var body RequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if tt.checkOrigin != nil {
+						tt.checkOrigin(t, body.Origins[0])
+					}
+					return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+				}},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: tt.args},
+			}
+
+			_, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if !tt.expectErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if errors.Is(err, ErrIdenticalAddresses) != tt.identical {
+				t.Errorf("expected ErrIdenticalAddresses=%v, got %v", tt.identical, err)
+			}
+		})
+	}
+}
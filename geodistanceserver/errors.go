@@ -0,0 +1,89 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrIdenticalAddresses is returned when the origin and destination refer
+// to the same address.
+var ErrIdenticalAddresses = errors.New("origin and destination addresses are identical")
+
+// ErrMissingAPIKey is returned when a handler is constructed without a
+// Google API key.
+var ErrMissingAPIKey = errors.New("missing Google API key")
+
+// ErrUnauthorized is returned when the API rejects the configured key.
+var ErrUnauthorized = errors.New("Google API key rejected")
+
+// ErrNoRoute is returned when the API finds no route between the
+// requested places.
+var ErrNoRoute = errors.New("no routes available")
+
+// ErrSuspiciousZeroDistance is returned, when enabled with
+// WithStrictZeroDistance, for a zero-meter route between different
+// places, which usually means an address was geocoded to the wrong place.
+var ErrSuspiciousZeroDistance = errors.New("suspicious zero distance between different places")
+
+// ErrHandlerClosed is returned by API calls made after Close.
+var ErrHandlerClosed = errors.New("geodistance handler is closed")
+
+// ErrCircuitOpen is returned without calling the API while the circuit
+// breaker configured with WithCircuitBreaker is open.
+var ErrCircuitOpen = errors.New("routes API circuit breaker is open")
+
+// APIError is the error object Google APIs return inside an
+// {"error": {...}} envelope.
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+
+	// HTTPStatus is the HTTP status code of the response that carried the
+	// error, which may differ from Code when a gateway wraps the error.
+	HTTPStatus int `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("routes API error (%s): %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("routes API error (code %d): %s", e.Code, e.Message)
+}
+
+// parseAPIError extracts the error object from a response body, returning
+// nil when the body does not carry a well-formed error envelope.
+func parseAPIError(body []byte) *APIError {
+	var envelope struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+	if envelope.Error == nil || (envelope.Error.Message == "" && envelope.Error.Status == "" && envelope.Error.Code == 0) {
+		return nil
+	}
+	return envelope.Error
+}
+
+// httpStatusError is a non-200 response whose body is not a Google error
+// envelope.
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// errorFromResponse builds the error for a non-200 response, preferring the
+// structured Google error envelope and falling back to the raw body.
+func errorFromResponse(statusCode int, body []byte) error {
+	if apiErr := parseAPIError(body); apiErr != nil {
+		apiErr.HTTPStatus = statusCode
+		return apiErr
+	}
+	return &httpStatusError{StatusCode: statusCode, Body: string(body)}
+}
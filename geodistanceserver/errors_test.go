@@ -0,0 +1,146 @@
+package geodistanceserver
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestParseAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *APIError
+	}{
+		{
+			name: "error envelope",
+			body: `{"error": {"code": 403, "message": "API key not valid.", "status": "PERMISSION_DENIED"}}`,
+			expected: &APIError{
+				Code:    403,
+				Message: "API key not valid.",
+				Status:  "PERMISSION_DENIED",
+			},
+		},
+		{
+			name:     "successful routes body",
+			body:     createValidAPIResponse(),
+			expected: nil,
+		},
+		{
+			name:     "string error",
+			body:     `{"error": "Invalid request"}`,
+			expected: nil,
+		},
+		{
+			name:     "empty error object",
+			body:     `{"error": {}}`,
+			expected: nil,
+		},
+		{
+			name:     "not JSON",
+			body:     "<html>Bad Gateway</html>",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAPIError([]byte(tt.body))
+
+			if tt.expected == nil {
+				if got != nil {
+					t.Errorf("expected no API error, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("expected API error but got none")
+			}
+			if *got != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_processResponse_ErrorInOKBody(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	resp := createMockResponse(http.StatusOK, `{"error": {"code": 429, "message": "Quota exceeded.", "status": "RESOURCE_EXHAUSTED"}}`)
+
+	result, err := handler.processResponse(resp)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if result != nil {
+		t.Error("expected nil result when error occurs")
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Code != 429 || apiErr.Status != "RESOURCE_EXHAUSTED" {
+		t.Errorf("unexpected API error: %+v", apiErr)
+	}
+	if err.Error() != "routes API error (RESOURCE_EXHAUSTED): Quota exceeded." {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+}
+
+func TestGeodistanceHandler_processResponse_ErrorStatus(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		expectedError string
+		expectAPIErr  bool
+	}{
+		{
+			name:       "google error envelope",
+			statusCode: http.StatusBadRequest,
+			body: `{
+  "error": {
+    "code": 400,
+    "message": "Invalid JSON payload received. Unknown name \"foo\": Cannot find field.",
+    "status": "INVALID_ARGUMENT",
+    "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest"}]
+  }
+}`,
+			expectedError: `routes API error (INVALID_ARGUMENT): Invalid JSON payload received. Unknown name "foo": Cannot find field.`,
+			expectAPIErr:  true,
+		},
+		{
+			name:          "non-JSON error body",
+			statusCode:    http.StatusBadGateway,
+			body:          "<html>502 Bad Gateway</html>",
+			expectedError: "API request failed with status 502: <html>502 Bad Gateway</html>",
+			expectAPIErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.processResponse(createMockResponse(tt.statusCode, tt.body))
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if result != nil {
+				t.Error("expected nil result when error occurs")
+			}
+			if err.Error() != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, err.Error())
+			}
+
+			var apiErr *APIError
+			if errors.As(err, &apiErr) != tt.expectAPIErr {
+				t.Fatalf("expected *APIError=%v, got %T", tt.expectAPIErr, err)
+			}
+			if tt.expectAPIErr && apiErr.HTTPStatus != tt.statusCode {
+				t.Errorf("expected HTTP status %d, got %d", tt.statusCode, apiErr.HTTPStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+)
+
+// fakeAPIKey stands in for the API key in fake mode, where none is needed.
+const fakeAPIKey = "fake"
+
+// fakeModeFromEnvironment reports whether GEODISTANCE_FAKE enables fake
+// mode. Unset means disabled; any other value must parse as a boolean.
+func fakeModeFromEnvironment() (bool, error) {
+	return boolFromEnvironment("GEODISTANCE_FAKE")
+}
+
+// fakeClient answers Routes and Geocoding API requests with synthetic
+// results derived from a hash of the inputs, without any network call.
+// The same request always gets the same answer, and a place is always 0
+// meters from itself.
+type fakeClient struct{}
+
+// fakeSpeeds are the average speeds, in meters per second, used to derive
+// durations from synthetic distances.
+var fakeSpeeds = map[string]int{
+	travelModeDrive:      15,
+	travelModeBicycle:    5,
+	travelModeWalk:       1,
+	travelModeTwoWheeler: 12,
+	travelModeTransit:    10,
+}
+
+// fakeRequest covers the single-route, matrix and computeRoutes request
+// shapes: matrix origins wrap a waypoint, the others name it directly.
+type fakeRequest struct {
+	Origins []struct {
+		Waypoint
+		Nested *Waypoint `json:"waypoint"`
+	} `json:"origins"`
+	Destinations []struct {
+		Waypoint
+		Nested *Waypoint `json:"waypoint"`
+	} `json:"destinations"`
+	Origin        *Waypoint  `json:"origin"`
+	Destination   *Waypoint  `json:"destination"`
+	Intermediates []Waypoint `json:"intermediates"`
+	TravelMode    string     `json:"travelMode"`
+}
+
+func (fakeClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		return fakeGeocode(req)
+	}
+
+	var body fakeRequest
+	if req.Body != nil {
+		defer req.Body.Close()
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return fakeResponse(http.StatusBadRequest, fmt.Sprintf(`{"error":{"code":400,"message":%q}}`, err.Error())), nil
+		}
+	}
+	mode := travelModeOrDefault(body.TravelMode)
+
+	// Matrix requests are answered with one element per pair
+	if len(body.Origins) > 0 && body.Origins[0].Nested != nil {
+		var elements []MatrixElement
+		for i, origin := range body.Origins {
+			for j, destination := range body.Destinations {
+				meters := fakeMeters(fakeWaypointKey(origin.Nested), fakeWaypointKey(destination.Nested), mode)
+				elements = append(elements, MatrixElement{
+					OriginIndex:      i,
+					DestinationIndex: j,
+					DistanceMeters:   meters,
+					Duration:         fakeDuration(meters, mode),
+					Condition:        conditionRouteExists,
+				})
+			}
+		}
+		return fakeJSONResponse(elements)
+	}
+
+	// A single route, possibly through intermediates, is the sum of its legs
+	var stops []string
+	switch {
+	case body.Origin != nil && body.Destination != nil:
+		stops = append(stops, fakeWaypointKey(body.Origin))
+		for i := range body.Intermediates {
+			stops = append(stops, fakeWaypointKey(&body.Intermediates[i]))
+		}
+		stops = append(stops, fakeWaypointKey(body.Destination))
+	case len(body.Origins) > 0 && len(body.Destinations) > 0:
+		stops = append(stops, fakeWaypointKey(&body.Origins[0].Waypoint))
+		for i := range body.Intermediates {
+			stops = append(stops, fakeWaypointKey(&body.Intermediates[i]))
+		}
+		stops = append(stops, fakeWaypointKey(&body.Destinations[0].Waypoint))
+	default:
+		return fakeResponse(http.StatusBadRequest, `{"error":{"code":400,"message":"origin and destination are required"}}`), nil
+	}
+
+	meters := 0
+	for i := 1; i < len(stops); i++ {
+		meters += fakeMeters(stops[i-1], stops[i], mode)
+	}
+	return fakeJSONResponse(ResponseBody{Routes: []Route{{
+		DistanceMeters: meters,
+		Duration:       fakeDuration(meters, mode),
+		RouteLabels:    []string{routeLabelDefault},
+	}}})
+}
+
+// fakeGeocode answers forward and reverse geocoding requests.
+func fakeGeocode(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	var result GeocodeResult
+	if address := query.Get("address"); address != "" {
+		hash := fakeHash(canonicalAddress(address))
+		result = GeocodeResult{
+			FormattedAddress: address,
+			PlaceID:          fmt.Sprintf("fake-%016x", hash),
+			Geometry: Geometry{Location: GeocodeLocation{
+				Lat: float64(hash%180_000)/1000 - 90,
+				Lng: float64(hash/180_000%360_000)/1000 - 180,
+			}},
+		}
+	} else if latlng := query.Get("latlng"); latlng != "" {
+		var lat, lng float64
+		fmt.Sscanf(latlng, "%g,%g", &lat, &lng)
+		result = GeocodeResult{
+			FormattedAddress: fmt.Sprintf("Synthetic address near %s", latlng),
+			PlaceID:          fmt.Sprintf("fake-%016x", fakeHash(latlng)),
+			Geometry:         Geometry{Location: GeocodeLocation{Lat: lat, Lng: lng}},
+		}
+	} else {
+		return fakeJSONResponse(GeocodeResponse{Status: "INVALID_REQUEST"})
+	}
+	return fakeJSONResponse(GeocodeResponse{Status: "OK", Results: []GeocodeResult{result}})
+}
+
+// fakeWaypointKey identifies a waypoint the way the route cache does.
+func fakeWaypointKey(waypoint *Waypoint) string {
+	if waypoint == nil {
+		return ""
+	}
+	return waypointCacheKey(waypoint.Address, waypoint.Location)
+}
+
+// fakeMeters returns a distance between 1 and 500 km for two different
+// places, the same in both directions.
+func fakeMeters(from, to, mode string) int {
+	if from == to {
+		return 0
+	}
+	if from > to {
+		from, to = to, from
+	}
+	return 1000 + int(fakeHash(from+"\x00"+to+"\x00"+mode)%499_000)
+}
+
+func fakeDuration(meters int, mode string) string {
+	speed := fakeSpeeds[mode]
+	if speed == 0 {
+		speed = fakeSpeeds[travelModeDrive]
+	}
+	return fmt.Sprintf("%ds", meters/speed)
+}
+
+func fakeHash(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return h.Sum64()
+}
+
+func fakeJSONResponse(value any) (*http.Response, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return fakeResponse(http.StatusOK, string(data)), nil
+}
+
+func fakeResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
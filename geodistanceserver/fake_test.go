@@ -0,0 +1,178 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newFakeHandler builds a handler in fake mode around a client that fails
+// the test on any call.
+func newFakeHandler(t *testing.T) *GeodistanceHandler {
+	t.Helper()
+	t.Setenv("GEODISTANCE_FAKE", "true")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GEODISTANCE_PROVIDER", "mapbox")
+
+	client := &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected HTTP call to %s", req.URL)
+		return nil, errors.New("network disabled")
+	}}
+	handler, err := NewGeodistanceHandlerWithClient(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return handler
+}
+
+func TestFakeMode_Tools(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "distance",
+			tool:     toolCalculateDistance,
+			args:     map[string]interface{}{"originAddress": "Springfield", "destinationAddress": "Chicago"},
+			expected: "Route distance:",
+		},
+		{
+			name:     "distance through waypoints",
+			tool:     toolCalculateDistance,
+			args:     map[string]interface{}{"originAddress": "Springfield", "destinationAddress": "Chicago", "waypoints": []interface{}{"Peoria"}},
+			expected: "Route distance:",
+		},
+		{
+			name: "matrix",
+			tool: toolCalculateDistanceMatrix,
+			args: map[string]interface{}{
+				"originAddresses":      []interface{}{"Springfield", "Peoria"},
+				"destinationAddresses": []interface{}{"Chicago", "Springfield"},
+			},
+			expected: "Springfield -> Springfield: 0.00 km (0 meters)",
+		},
+		{
+			name:     "geocode",
+			tool:     toolGeocodeAddress,
+			args:     map[string]interface{}{"address": "Springfield"},
+			expected: "Springfield",
+		},
+		{
+			name:     "geocode many",
+			tool:     toolGeocodeAddresses,
+			args:     map[string]interface{}{"addresses": []interface{}{"Springfield", "Chicago"}},
+			expected: "2. Chicago: Address: Chicago",
+		},
+		{
+			name:     "reverse geocode",
+			tool:     toolReverseGeocode,
+			args:     map[string]interface{}{"latitude": 41.8781, "longitude": -87.6298},
+			expected: "Synthetic address near",
+		},
+		{
+			name:     "ping",
+			tool:     toolPing,
+			args:     map[string]interface{}{},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newFakeHandler(t)
+			handlers := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+				toolCalculateDistance:       handler.handleDistanceCalculation,
+				toolCalculateDistanceMatrix: handler.handleDistanceMatrix,
+				toolGeocodeAddress:          handler.handleGeocode,
+				toolGeocodeAddresses:        handler.handleGeocodeAddresses,
+				toolReverseGeocode:          handler.handleReverseGeocode,
+				toolPing:                    handler.handlePing,
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: tt.tool, Arguments: tt.args},
+			}
+
+			first, err := handlers[tt.tool](context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			second, err := handlers[tt.tool](context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text := first.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, text)
+			}
+			if again := second.Content[0].(mcp.TextContent).Text; again != text {
+				t.Errorf("expected deterministic output, got %q then %q", text, again)
+			}
+		})
+	}
+}
+
+func TestFakeMeters(t *testing.T) {
+	if got := fakeMeters("springfield", "springfield", travelModeDrive); got != 0 {
+		t.Errorf("expected 0 meters from a place to itself, got %d", got)
+	}
+	there := fakeMeters("springfield", "chicago", travelModeDrive)
+	if back := fakeMeters("chicago", "springfield", travelModeDrive); back != there {
+		t.Errorf("expected the same distance both ways, got %d and %d", there, back)
+	}
+	if there < 1000 || there >= 500_000 {
+		t.Errorf("expected a distance between 1 and 500 km, got %d", there)
+	}
+	if other := fakeMeters("springfield", "peoria", travelModeDrive); other == there {
+		t.Errorf("expected different places to get different distances, both got %d", there)
+	}
+}
+
+func TestFakeModeFromEnvironment(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  bool
+		expectErr bool
+	}{
+		{value: "", expected: false},
+		{value: "true", expected: true},
+		{value: "1", expected: true},
+		{value: "false", expected: false},
+		{value: "maybe", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("GEODISTANCE_FAKE", tt.value)
+
+			enabled, err := fakeModeFromEnvironment()
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if enabled != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, enabled)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandler_FakeModeDisabledNeedsKey(t *testing.T) {
+	t.Setenv("GEODISTANCE_FAKE", "false")
+	t.Setenv("GOOGLE_API_KEY", "")
+
+	if _, err := NewGeodistanceHandler(); !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("expected ErrMissingAPIKey, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package geodistanceserver
+
+import "strings"
+
+const (
+	polylineFieldMask = "routes.polyline.encodedPolyline"
+	tollsFieldMask    = "routes.travelAdvisory.tollInfo"
+)
+
+// routeBaseFields are requested on every computeRoutes call because the
+// text and JSON output always report them.
+var routeBaseFields = []string{
+	"routes.duration",
+	"routes.staticDuration",
+	"routes.routeLabels",
+	"routes.distanceMeters",
+	"routes.description",
+	"routes.warnings",
+}
+
+// distanceOnlyFields replace routeBaseFields when only the distance is
+// reported.
+var distanceOnlyFields = []string{"routes.distanceMeters"}
+
+// fieldMaskFeatures selects the optional response fields of a call.
+type fieldMaskFeatures struct {
+	DistanceOnly     bool
+	Polyline         bool
+	Tolls            bool
+	GeocodingResults bool
+}
+
+// fieldMask assembles the X-Goog-FieldMask value for a computeRoutes call.
+// Optional fields are only requested when enabled, since the Routes API
+// bills some of them at a higher SKU.
+func fieldMask(features fieldMaskFeatures) string {
+	base := routeBaseFields
+	if features.DistanceOnly {
+		base = distanceOnlyFields
+	}
+	fields := append([]string(nil), base...)
+	if features.Polyline {
+		fields = append(fields, polylineFieldMask)
+	}
+	if features.Tolls {
+		fields = append(fields, tollsFieldMask)
+	}
+	if features.GeocodingResults {
+		fields = append(fields, geocodingResultsFieldMask)
+	}
+	return strings.Join(fields, ",")
+}
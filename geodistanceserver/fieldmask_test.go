@@ -0,0 +1,81 @@
+package geodistanceserver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		features fieldMaskFeatures
+		include  []string
+		exclude  []string
+	}{
+		{
+			name:     "base fields only",
+			features: fieldMaskFeatures{},
+			include:  routeBaseFields,
+			exclude:  []string{polylineFieldMask, tollsFieldMask, geocodingResultsFieldMask},
+		},
+		{
+			name:     "geocoding results",
+			features: fieldMaskFeatures{GeocodingResults: true},
+			include:  append([]string{geocodingResultsFieldMask}, routeBaseFields...),
+			exclude:  []string{polylineFieldMask, tollsFieldMask},
+		},
+		{
+			name:     "polyline",
+			features: fieldMaskFeatures{Polyline: true},
+			include:  append([]string{polylineFieldMask}, routeBaseFields...),
+			exclude:  []string{tollsFieldMask},
+		},
+		{
+			name:     "tolls",
+			features: fieldMaskFeatures{Tolls: true},
+			include:  append([]string{tollsFieldMask}, routeBaseFields...),
+			exclude:  []string{polylineFieldMask},
+		},
+		{
+			name:     "distance only",
+			features: fieldMaskFeatures{DistanceOnly: true},
+			include:  []string{"routes.distanceMeters"},
+			exclude:  []string{"routes.duration", "routes.staticDuration", "routes.routeLabels", "routes.description"},
+		},
+		{
+			name:     "distance only with polyline",
+			features: fieldMaskFeatures{DistanceOnly: true, Polyline: true},
+			include:  []string{"routes.distanceMeters", polylineFieldMask},
+			exclude:  []string{"routes.duration"},
+		},
+		{
+			name:     "all features",
+			features: fieldMaskFeatures{Polyline: true, Tolls: true},
+			include:  append([]string{polylineFieldMask, tollsFieldMask}, routeBaseFields...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := strings.Split(fieldMask(tt.features), ",")
+			set := make(map[string]bool, len(fields))
+			for _, field := range fields {
+				if set[field] {
+					t.Errorf("field %q requested twice", field)
+				}
+				set[field] = true
+			}
+
+			for _, field := range tt.include {
+				if !set[field] {
+					t.Errorf("expected mask to include %q, got %v", field, fields)
+				}
+			}
+			for _, field := range tt.exclude {
+				if set[field] {
+					t.Errorf("expected mask to exclude %q, got %v", field, fields)
+				}
+			}
+		})
+	}
+}
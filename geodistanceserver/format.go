@@ -0,0 +1,98 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+var outputFormats = []string{outputFormatText, outputFormatJSON}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputFormatText, outputFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid format %q: must be %s or %s", format, outputFormatText, outputFormatJSON)
+	}
+}
+
+// RouteJSON is the machine-readable form of a route returned when the
+// json output format is requested.
+type RouteJSON struct {
+	DistanceMeters        int      `json:"distanceMeters"`
+	DurationSeconds       *float64 `json:"durationSeconds,omitempty"`
+	StaticDurationSeconds *float64 `json:"staticDurationSeconds,omitempty"`
+	TrafficUnavailable    bool     `json:"trafficUnavailable,omitempty"`
+	RouteLabels           []string `json:"routeLabels"`
+	Warnings              []string `json:"warnings,omitempty"`
+	Polyline              string   `json:"polyline,omitempty"`
+	EstimatedTolls        string   `json:"estimatedTolls,omitempty"`
+}
+
+func newRouteJSON(route Route, opts routeOptions) (RouteJSON, error) {
+	labels := route.RouteLabels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	r := RouteJSON{
+		DistanceMeters: route.DistanceMeters,
+		RouteLabels:    labels,
+		Warnings:       route.Warnings,
+	}
+	// Distance-only calls do not request the duration
+	if !opts.DistanceOnly {
+		d, err := parseDuration(route.Duration)
+		if err != nil {
+			return RouteJSON{}, fmt.Errorf("invalid route duration: %w", err)
+		}
+		seconds := d.Seconds()
+		r.DurationSeconds = &seconds
+		r.TrafficUnavailable = route.trafficUnavailable
+
+		if route.StaticDuration != "" {
+			static, err := parseDuration(route.StaticDuration)
+			if err != nil {
+				return RouteJSON{}, fmt.Errorf("invalid route static duration: %w", err)
+			}
+			staticSeconds := static.Seconds()
+			r.StaticDurationSeconds = &staticSeconds
+		}
+	}
+	if opts.IncludePolyline && route.Polyline != nil {
+		r.Polyline = route.Polyline.EncodedPolyline
+	}
+	if opts.IncludeTolls {
+		r.EstimatedTolls = formatTolls(route)
+	}
+	return r, nil
+}
+
+// formatJSONResponse renders each route as a JSON object, one text content
+// per route in the same order as the text format.
+func formatJSONResponse(routes []Route, opts routeOptions) (*mcp.CallToolResult, error) {
+	content := make([]mcp.Content, 0, len(routes))
+	for _, route := range routes {
+		r, err := newRouteJSON(route, opts)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal route: %w", err)
+		}
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: string(data),
+		})
+	}
+
+	return &mcp.CallToolResult{Content: content}, nil
+}
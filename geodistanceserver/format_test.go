@@ -0,0 +1,207 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_formatResponse_JSON(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	responseBody := &ResponseBody{
+		Routes: []Route{
+			{DistanceMeters: 94475, Duration: "3288s", RouteLabels: []string{"DEFAULT_ROUTE"}},
+			{DistanceMeters: 87865, Duration: "4903.5s", RouteLabels: []string{"SHORTER_DISTANCE"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     routeOptions
+		expected []RouteJSON
+	}{
+		{
+			name: "reference routes",
+			opts: routeOptions{Format: outputFormatJSON},
+			expected: []RouteJSON{
+				{DistanceMeters: 94475, DurationSeconds: floatPtr(3288), RouteLabels: []string{"DEFAULT_ROUTE"}},
+				{DistanceMeters: 87865, DurationSeconds: floatPtr(4903.5), RouteLabels: []string{"SHORTER_DISTANCE"}},
+			},
+		},
+		{
+			name: "alternative routes",
+			opts: routeOptions{Format: outputFormatJSON, ComputeAlternativeRoutes: true},
+			expected: []RouteJSON{
+				{DistanceMeters: 94475, DurationSeconds: floatPtr(3288), RouteLabels: []string{"DEFAULT_ROUTE"}},
+				{DistanceMeters: 87865, DurationSeconds: floatPtr(4903.5), RouteLabels: []string{"SHORTER_DISTANCE"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.formatResponse(responseBody, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != len(tt.expected) {
+				t.Fatalf("expected %d content items, got %d", len(tt.expected), len(result.Content))
+			}
+
+			for i, content := range result.Content {
+				text, ok := content.(mcp.TextContent)
+				if !ok {
+					t.Fatalf("expected TextContent, got %T", content)
+				}
+
+				var got RouteJSON
+				if err := json.Unmarshal([]byte(text.Text), &got); err != nil {
+					t.Fatalf("content is not valid JSON: %v: %s", err, text.Text)
+				}
+				if !reflect.DeepEqual(got, tt.expected[i]) {
+					t.Errorf("route %d: expected %+v, got %+v", i, tt.expected[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatResponse_JSONFields(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	result, err := handler.formatResponse(&ResponseBody{
+		Routes: []Route{{DistanceMeters: 1000, Duration: "300s"}},
+	}, routeOptions{Format: outputFormatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &raw); err != nil {
+		t.Fatalf("content is not valid JSON: %v", err)
+	}
+	for _, field := range []string{"distanceMeters", "durationSeconds", "routeLabels"} {
+		if _, ok := raw[field]; !ok {
+			t.Errorf("expected field %q in %v", field, raw)
+		}
+	}
+	if labels, ok := raw["routeLabels"].([]interface{}); !ok || len(labels) != 0 {
+		t.Errorf("expected empty routeLabels array, got %v", raw["routeLabels"])
+	}
+	if _, ok := raw["warnings"]; ok {
+		t.Errorf("expected warnings to be omitted without warnings, got %v", raw["warnings"])
+	}
+}
+
+func TestGeodistanceHandler_formatResponse_JSONWarnings(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	result, err := handler.formatResponse(&ResponseBody{
+		Routes: []Route{{DistanceMeters: 1000, Duration: "300s", Warnings: []string{"This route has tolls."}}},
+	}, routeOptions{Format: outputFormatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var route RouteJSON
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &route); err != nil {
+		t.Fatalf("content is not valid JSON: %v", err)
+	}
+	if len(route.Warnings) != 1 || route.Warnings[0] != "This route has tolls." {
+		t.Errorf("expected the route warning, got %v", route.Warnings)
+	}
+}
+
+func TestGeodistanceHandler_formatResponse_JSONStaticDuration(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name     string
+		route    Route
+		expected *float64
+	}{
+		{
+			name:     "with static duration",
+			route:    Route{DistanceMeters: 1000, Duration: "1500s", StaticDuration: "1080s"},
+			expected: floatPtr(1080),
+		},
+		{
+			name:  "without static duration",
+			route: Route{DistanceMeters: 1000, Duration: "1500s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.formatResponse(&ResponseBody{Routes: []Route{tt.route}}, routeOptions{Format: outputFormatJSON})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got RouteJSON
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got); err != nil {
+				t.Fatalf("content is not valid JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got.StaticDurationSeconds, tt.expected) {
+				t.Errorf("expected staticDurationSeconds %v, got %v", tt.expected, got.StaticDurationSeconds)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatResponse_JSONInvalidDuration(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	_, err := handler.formatResponse(&ResponseBody{
+		Routes: []Route{{DistanceMeters: 1000, Duration: "soon"}},
+	}, routeOptions{Format: outputFormatJSON})
+	if err == nil {
+		t.Error("expected error for an unparseable duration")
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	tests := []struct {
+		format    string
+		expectErr bool
+	}{
+		{format: outputFormatText, expectErr: false},
+		{format: outputFormatJSON, expectErr: false},
+		{format: "xml", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			err := validateOutputFormat(tt.format)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestGeodistanceHandler_formatResponse_JSONDistanceOnly(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	result, err := handler.formatResponse(&ResponseBody{
+		Routes: []Route{{DistanceMeters: 1000}},
+	}, routeOptions{Format: outputFormatJSON, DistanceOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if text != `{"distanceMeters":1000,"routeLabels":[]}` {
+		t.Errorf("unexpected distance-only JSON: %s", text)
+	}
+}
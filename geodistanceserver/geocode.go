@@ -0,0 +1,206 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const defaultGeocodeURL = "https://maps.googleapis.com/maps/api/geocode/json"
+
+var errNoGeocodeResults = errors.New("no geocoding results found")
+
+type GeocodeResponse struct {
+	Results      []GeocodeResult `json:"results"`
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+}
+
+type GeocodeResult struct {
+	FormattedAddress string   `json:"formatted_address"`
+	Geometry         Geometry `json:"geometry"`
+	PlaceID          string   `json:"place_id"`
+}
+
+type Geometry struct {
+	Location GeocodeLocation `json:"location"`
+}
+
+type GeocodeLocation struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func (gh *GeodistanceHandler) handleGeocode(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	args, err := requireStringArguments(request, toolGeocodeAddress, "address")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	address := normalizeAddress(args[0])
+
+	if address == "" {
+		return nil, invalidArgument(fmt.Errorf("address cannot be empty"))
+	}
+	if err := gh.validateAddress("address", address); err != nil {
+		return nil, invalidArgument(err)
+	}
+	precision, err := parsePrecision(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	geocodeResponse, err := gh.callGeocode(ctx, url.Values{"address": {address}})
+	if err != nil {
+		return nil, err
+	}
+
+	result := geocodeResponse.Results[0]
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Address: %s, Latitude: %.*f, Longitude: %.*f",
+					result.FormattedAddress, precision, result.Geometry.Location.Lat, precision, result.Geometry.Location.Lng),
+			},
+		},
+	}, nil
+}
+
+const (
+	// defaultCoordinatePrecision is the decimal places of geocoded
+	// coordinates, about 0.1 m.
+	defaultCoordinatePrecision = 6
+	maxCoordinatePrecision     = 10
+)
+
+// parsePrecision reads the precision argument: the whole number of
+// decimal places, 0 to 10, used for geocoded coordinates.
+func parsePrecision(request mcp.CallToolRequest) (int, error) {
+	value, err := optionalFloatArgument(request, request.Params.Name, "precision")
+	if err != nil {
+		return 0, err
+	}
+	if value == nil {
+		return defaultCoordinatePrecision, nil
+	}
+	if *value != math.Trunc(*value) || *value < 0 || *value > maxCoordinatePrecision {
+		return 0, fmt.Errorf("invalid precision %v: must be a whole number from 0 to %d", *value, maxCoordinatePrecision)
+	}
+	return int(*value), nil
+}
+
+func (gh *GeodistanceHandler) handleReverseGeocode(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	args, err := requireFloatArguments(request, toolReverseGeocode, "latitude", "longitude")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	latitude, longitude := args[0], args[1]
+
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	latlng := strconv.FormatFloat(latitude, 'f', -1, 64) + "," + strconv.FormatFloat(longitude, 'f', -1, 64)
+	geocodeResponse, err := gh.callGeocode(ctx, url.Values{"latlng": {latlng}})
+	if errors.Is(err, errNoGeocodeResults) {
+		return nil, fmt.Errorf("no address found near %s", latlng)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Address: %s", geocodeResponse.Results[0].FormattedAddress),
+			},
+		},
+	}, nil
+}
+
+func (gh *GeodistanceHandler) createGeocodeRequest(ctx context.Context, params url.Values) (*http.Request, error) {
+	endpoint := gh.geocodeURL
+	if endpoint == "" {
+		endpoint = defaultGeocodeURL
+	}
+
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("key", gh.nextAPIKey())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", gh.userAgentOrDefault())
+	if id := RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	return req, nil
+}
+
+func (gh *GeodistanceHandler) processGeocodeResponse(resp *http.Response) (*GeocodeResponse, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes := gh.readErrorBody(resp.Body)
+		return nil, fmt.Errorf("geocoding request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	bodyBytes, err := gh.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var geocodeResponse GeocodeResponse
+	if err := gh.decodeResponse(bodyBytes, &geocodeResponse); err != nil {
+		return nil, err
+	}
+
+	switch geocodeResponse.Status {
+	case "OK":
+	case "ZERO_RESULTS":
+		return nil, errNoGeocodeResults
+	default:
+		if geocodeResponse.ErrorMessage != "" {
+			return nil, fmt.Errorf("geocoding API error (%s): %s", geocodeResponse.Status, geocodeResponse.ErrorMessage)
+		}
+		return nil, fmt.Errorf("geocoding API error (%s)", geocodeResponse.Status)
+	}
+
+	if len(geocodeResponse.Results) == 0 {
+		return nil, errNoGeocodeResults
+	}
+
+	return &geocodeResponse, nil
+}
+
+func (gh *GeodistanceHandler) callGeocode(ctx context.Context, params url.Values) (*GeocodeResponse, error) {
+	req, err := gh.createGeocodeRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := gh.do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	return gh.processGeocodeResponse(resp)
+}
@@ -0,0 +1,328 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const validGeocodeResponse = `{
+  "results": [
+    {
+      "formatted_address": "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+      "geometry": {"location": {"lat": 37.4224764, "lng": -122.0842499}},
+      "place_id": "ChIJ2eUgeAK6j4ARbn5u_wAGqWA"
+    }
+  ],
+  "status": "OK"
+}`
+
+func TestGeodistanceHandler_processGeocodeResponse(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expectErr  bool
+	}{
+		{
+			name:       "successful response",
+			statusCode: http.StatusOK,
+			body:       validGeocodeResponse,
+			expectErr:  false,
+		},
+		{
+			name:       "HTTP error",
+			statusCode: http.StatusInternalServerError,
+			body:       "internal error",
+			expectErr:  true,
+		},
+		{
+			name:       "invalid JSON",
+			statusCode: http.StatusOK,
+			body:       "invalid json",
+			expectErr:  true,
+		},
+		{
+			name:       "zero results",
+			statusCode: http.StatusOK,
+			body:       `{"results": [], "status": "ZERO_RESULTS"}`,
+			expectErr:  true,
+		},
+		{
+			name:       "request denied",
+			statusCode: http.StatusOK,
+			body:       `{"results": [], "status": "REQUEST_DENIED", "error_message": "The provided API key is invalid."}`,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := createMockResponse(tt.statusCode, tt.body)
+
+			result, err := handler.processGeocodeResponse(resp)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if result == nil || len(result.Results) == 0 {
+					t.Error("expected geocoding results")
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleGeocode(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestArgs  map[string]interface{}
+		mockFunc     func(req *http.Request) (*http.Response, error)
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name: "successful geocode",
+			requestArgs: map[string]interface{}{
+				"address": "1600 Amphitheatre Parkway, Mountain View, CA",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Method != "GET" {
+					return nil, fmt.Errorf("expected GET method, got %s", req.Method)
+				}
+				if req.URL.Query().Get("address") != "1600 Amphitheatre Parkway, Mountain View, CA" {
+					return nil, fmt.Errorf("unexpected address query: %s", req.URL.RawQuery)
+				}
+				if req.URL.Query().Get("key") != "test-key" {
+					return nil, fmt.Errorf("API key not set on request")
+				}
+				return createMockResponse(http.StatusOK, validGeocodeResponse), nil
+			},
+			expectErr:    false,
+			expectedText: "Address: 1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA, Latitude: 37.422476, Longitude: -122.084250",
+		},
+		{
+			name: "zero precision",
+			requestArgs: map[string]interface{}{
+				"address":   "1600 Amphitheatre Parkway, Mountain View, CA",
+				"precision": 0,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, validGeocodeResponse), nil
+			},
+			expectedText: "Address: 1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA, Latitude: 37, Longitude: -122",
+		},
+		{
+			name: "two decimal places round",
+			requestArgs: map[string]interface{}{
+				"address":   "1600 Amphitheatre Parkway, Mountain View, CA",
+				"precision": 2,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, validGeocodeResponse), nil
+			},
+			expectedText: "Address: 1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA, Latitude: 37.42, Longitude: -122.08",
+		},
+		{
+			name: "maximum precision",
+			requestArgs: map[string]interface{}{
+				"address":   "1600 Amphitheatre Parkway, Mountain View, CA",
+				"precision": 10,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, validGeocodeResponse), nil
+			},
+			expectedText: "Address: 1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA, Latitude: 37.4224764000, Longitude: -122.0842499000",
+		},
+		{
+			name: "precision out of range",
+			requestArgs: map[string]interface{}{
+				"address":   "1600 Amphitheatre Parkway, Mountain View, CA",
+				"precision": 11,
+			},
+			expectErr: true,
+		},
+		{
+			name: "fractional precision",
+			requestArgs: map[string]interface{}{
+				"address":   "1600 Amphitheatre Parkway, Mountain View, CA",
+				"precision": 2.5,
+			},
+			expectErr: true,
+		},
+		{
+			name:        "missing address",
+			requestArgs: map[string]interface{}{},
+			expectErr:   true,
+		},
+		{
+			name: "empty address",
+			requestArgs: map[string]interface{}{
+				"address": "",
+			},
+			expectErr: true,
+		},
+		{
+			name: "no results",
+			requestArgs: map[string]interface{}{
+				"address": "nowhere in particular",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{"results": [], "status": "ZERO_RESULTS"}`), nil
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: tt.mockFunc},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "geocode_address",
+					Arguments: tt.requestArgs,
+				},
+			}
+
+			result, err := handler.handleGeocode(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatal("expected text content")
+			}
+			if textContent.Text != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, textContent.Text)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleReverseGeocode(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestArgs   map[string]interface{}
+		mockFunc      func(req *http.Request) (*http.Response, error)
+		expectedText  string
+		expectedError string
+	}{
+		{
+			name: "successful reverse geocode",
+			requestArgs: map[string]interface{}{
+				"latitude":  37.4224764,
+				"longitude": -122.0842499,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Query().Get("latlng") != "37.4224764,-122.0842499" {
+					return nil, fmt.Errorf("unexpected latlng query: %s", req.URL.RawQuery)
+				}
+				if req.URL.Query().Get("key") != "test-key" {
+					return nil, fmt.Errorf("API key not set on request")
+				}
+				return createMockResponse(http.StatusOK, validGeocodeResponse), nil
+			},
+			expectedText: "Address: 1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+		},
+		{
+			name:          "missing coordinates",
+			requestArgs:   map[string]interface{}{},
+			expectedError: "missing required arguments for reverse_geocode: latitude, longitude",
+		},
+		{
+			name: "latitude out of range",
+			requestArgs: map[string]interface{}{
+				"latitude":  91.0,
+				"longitude": 0.0,
+			},
+			expectedError: "invalid latitude 91: must be between -90 and 90",
+		},
+		{
+			name: "longitude out of range",
+			requestArgs: map[string]interface{}{
+				"latitude":  0.0,
+				"longitude": -180.5,
+			},
+			expectedError: "invalid longitude -180.5: must be between -180 and 180",
+		},
+		{
+			name: "no results",
+			requestArgs: map[string]interface{}{
+				"latitude":  0.0,
+				"longitude": 0.0,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{"results": [], "status": "ZERO_RESULTS"}`), nil
+			},
+			expectedError: "no address found near 0,0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: tt.mockFunc},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "reverse_geocode",
+					Arguments: tt.requestArgs,
+				},
+			}
+
+			result, err := handler.handleReverseGeocode(context.Background(), request)
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if err.Error() != tt.expectedError {
+					t.Errorf("expected error %q, got %q", tt.expectedError, err.Error())
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatal("expected text content")
+			}
+			if textContent.Text != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, textContent.Text)
+			}
+		})
+	}
+}
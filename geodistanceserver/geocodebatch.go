@@ -0,0 +1,122 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// maxGeocodeBatchSize bounds the addresses accepted by
+	// geocode_addresses, since each one costs a Geocoding API call.
+	maxGeocodeBatchSize = 100
+	// maxGeocodeConcurrency bounds the geocoding requests in flight for
+	// one geocode_addresses call.
+	maxGeocodeConcurrency = 4
+)
+
+// geocodeOutcome is the result of geocoding one address of a batch.
+type geocodeOutcome struct {
+	result *GeocodeResult
+	err    error
+}
+
+func (gh *GeodistanceHandler) handleGeocodeAddresses(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	args, err := requireStringSliceArguments(request, toolGeocodeAddresses, "addresses")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	addresses := args[0]
+
+	if len(addresses) == 0 {
+		return nil, invalidArgument(fmt.Errorf("addresses cannot be empty"))
+	}
+	if len(addresses) > maxGeocodeBatchSize {
+		return nil, invalidArgument(fmt.Errorf("too many addresses: %d exceeds the limit of %d", len(addresses), maxGeocodeBatchSize))
+	}
+	precision, err := parsePrecision(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	outcomes := gh.geocodeBatch(ctx, addresses)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, len(addresses))
+	for i, outcome := range outcomes {
+		if outcome.err != nil {
+			lines[i] = fmt.Sprintf("%d. %s: Error: %v", i+1, addresses[i], outcome.err)
+			continue
+		}
+		location := outcome.result.Geometry.Location
+		lines[i] = fmt.Sprintf("%d. %s: Address: %s, Latitude: %.*f, Longitude: %.*f",
+			i+1, addresses[i], outcome.result.FormattedAddress, precision, location.Lat, precision, location.Lng)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: strings.Join(lines, "\n"),
+			},
+		},
+	}, nil
+}
+
+// geocodeBatch geocodes addresses with at most maxGeocodeConcurrency
+// requests in flight, returning one outcome per address in input order.
+// An address that fails validation or geocoding gets its own error and
+// does not affect the others.
+func (gh *GeodistanceHandler) geocodeBatch(ctx context.Context, addresses []string) []geocodeOutcome {
+	outcomes := make([]geocodeOutcome, len(addresses))
+
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for w := 0; w < min(maxGeocodeConcurrency, len(addresses)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				outcomes[i] = gh.geocodeOne(ctx, addresses[i])
+			}
+		}()
+	}
+
+dispatch:
+	for i := range addresses {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	return outcomes
+}
+
+func (gh *GeodistanceHandler) geocodeOne(ctx context.Context, address string) geocodeOutcome {
+	address = normalizeAddress(address)
+	if address == "" {
+		return geocodeOutcome{err: fmt.Errorf("address cannot be empty")}
+	}
+	if err := gh.validateAddress("address", address); err != nil {
+		return geocodeOutcome{err: err}
+	}
+
+	geocodeResponse, err := gh.callGeocode(ctx, url.Values{"address": {address}})
+	if err != nil {
+		return geocodeOutcome{err: err}
+	}
+	return geocodeOutcome{result: &geocodeResponse.Results[0]}
+}
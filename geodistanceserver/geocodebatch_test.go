@@ -0,0 +1,130 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleGeocodeAddresses(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  []string
+		expectErr string
+	}{
+		{
+			name: "mixed results keep input order",
+			args: map[string]interface{}{"addresses": []interface{}{"Boston", "Nowhere", "Denver", "", "Broken"}},
+			expected: []string{
+				"1. Boston: Address: Boston, USA, Latitude: 10.000000, Longitude: 20.000000",
+				"2. Nowhere: Error: no geocoding results found",
+				"3. Denver: Address: Denver, USA, Latitude: 10.000000, Longitude: 20.000000",
+				"4. : Error: address cannot be empty",
+				"5. Broken: Error: geocoding request failed with status 500",
+			},
+		},
+		{
+			name:     "precision",
+			args:     map[string]interface{}{"addresses": []interface{}{"Boston"}, "precision": 2.0},
+			expected: []string{"1. Boston: Address: Boston, USA, Latitude: 10.00, Longitude: 20.00"},
+		},
+		{
+			name:      "missing addresses",
+			args:      map[string]interface{}{},
+			expectErr: "missing required arguments for geocode_addresses: addresses",
+		},
+		{
+			name:      "empty addresses",
+			args:      map[string]interface{}{"addresses": []interface{}{}},
+			expectErr: "addresses cannot be empty",
+		},
+		{
+			name:      "too many addresses",
+			args:      map[string]interface{}{"addresses": toAny(syntheticAddresses("a", maxGeocodeBatchSize+1))},
+			expectErr: "exceeds the limit of 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					switch address := req.URL.Query().Get("address"); address {
+					case "Nowhere":
+						return createMockResponse(http.StatusOK, `{"status":"ZERO_RESULTS","results":[]}`), nil
+					case "Broken":
+						return createMockResponse(http.StatusInternalServerError, `{"error":"internal"}`), nil
+					default:
+						return createMockResponse(http.StatusOK, fmt.Sprintf(
+							`{"status":"OK","results":[{"formatted_address":"%s, USA","geometry":{"location":{"lat":10,"lng":20}}}]}`, address)), nil
+					}
+				}},
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolGeocodeAddresses, Arguments: tt.args},
+			}
+
+			result, err := handler.handleGeocodeAddresses(context.Background(), request)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lines := strings.Split(result.Content[0].(mcp.TextContent).Text, "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("expected %d lines, got %d: %q", len(tt.expected), len(lines), lines)
+			}
+			for i, expected := range tt.expected {
+				if !strings.HasPrefix(lines[i], expected) {
+					t.Errorf("line %d: expected prefix %q, got %q", i+1, expected, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_geocodeBatch_Concurrency(t *testing.T) {
+	var inFlight, peak, calls int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+			return createMockResponse(http.StatusOK, `{"status":"OK","results":[{"formatted_address":"Somewhere","geometry":{"location":{"lat":1,"lng":2}}}]}`), nil
+		}},
+	}
+
+	outcomes := handler.geocodeBatch(context.Background(), syntheticAddresses("a", 12))
+	for i, outcome := range outcomes {
+		if outcome.err != nil {
+			t.Errorf("address %d: unexpected error: %v", i, outcome.err)
+		}
+	}
+	if calls != 12 {
+		t.Errorf("expected 12 calls, got %d", calls)
+	}
+	if peak > maxGeocodeConcurrency {
+		t.Errorf("expected at most %d requests in flight, got %d", maxGeocodeConcurrency, peak)
+	}
+}
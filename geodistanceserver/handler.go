@@ -4,98 +4,559 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/time/rate"
 )
 
 type Origin struct {
-	Address string `json:"address"`
+	Address  string    `json:"address,omitempty"`
+	Location *Location `json:"location,omitempty"`
 }
 
 type Destination struct {
-	Address string `json:"address"`
+	Address  string    `json:"address,omitempty"`
+	Location *Location `json:"location,omitempty"`
 }
 
+type RouteModifiers struct {
+	AvoidTolls    bool         `json:"avoidTolls,omitempty"`
+	AvoidHighways bool         `json:"avoidHighways,omitempty"`
+	AvoidFerries  bool         `json:"avoidFerries,omitempty"`
+	AvoidIndoor   bool         `json:"avoidIndoor,omitempty"`
+	VehicleInfo   *VehicleInfo `json:"vehicleInfo,omitempty"`
+}
+
+// RequestBody is the computeRouteMatrix request of a single route. Every
+// optional field is omitted when unset, since the API rejects some empty
+// values; fields whose zero value is meaningful are pointers.
 type RequestBody struct {
-	Origins                  []Origin      `json:"origins"`
-	Destinations             []Destination `json:"destinations"`
-	TravelMode               string        `json:"travelMode"`
-	RoutingPreference        string        `json:"routingPreference"`
-	RequestedReferenceRoutes []string      `json:"requestedReferenceRoutes"`
-	LanguageCode             string        `json:"languageCode"`
+	Origins                  []Origin            `json:"origins"`
+	Destinations             []Destination       `json:"destinations"`
+	TravelMode               string              `json:"travelMode,omitempty"`
+	RoutingPreference        string              `json:"routingPreference,omitempty"`
+	RequestedReferenceRoutes []string            `json:"requestedReferenceRoutes,omitempty"`
+	LanguageCode             string              `json:"languageCode,omitempty"`
+	RegionCode               string              `json:"regionCode,omitempty"`
+	RouteModifiers           *RouteModifiers     `json:"routeModifiers,omitempty"`
+	ComputeAlternativeRoutes bool                `json:"computeAlternativeRoutes,omitempty"`
+	DepartureTime            string              `json:"departureTime,omitempty"`
+	ArrivalTime              string              `json:"arrivalTime,omitempty"`
+	Intermediates            []Waypoint          `json:"intermediates,omitempty"`
+	ExtraComputations        []string            `json:"extraComputations,omitempty"`
+	TransitPreferences       *TransitPreferences `json:"transitPreferences,omitempty"`
 }
 
 type ResponseBody struct {
-	Routes []Route `json:"routes"`
+	Routes           []Route           `json:"routes"`
+	GeocodingResults *GeocodingResults `json:"geocodingResults,omitempty"`
+
+	// raw is the response as received, kept for the debug argument
+	raw []byte
+	// elapsed is how long the upstream call took, including retries;
+	// cached is set instead when the response came from the route cache.
+	elapsed time.Duration
+	cached  bool
 }
 
 type Route struct {
-	DistanceMeters int      `json:"distanceMeters"`
-	Duration       string   `json:"duration"`
-	RouteLabels    []string `json:"routeLabels"`
+	DistanceMeters int             `json:"distanceMeters"`
+	Duration       string          `json:"duration"`
+	StaticDuration string          `json:"staticDuration,omitempty"`
+	RouteLabels    []string        `json:"routeLabels"`
+	Description    string          `json:"description,omitempty"`
+	Warnings       []string        `json:"warnings,omitempty"`
+	Polyline       *Polyline       `json:"polyline,omitempty"`
+	TravelAdvisory *TravelAdvisory `json:"travelAdvisory,omitempty"`
+
+	// trafficUnavailable is set when traffic-aware routing was requested
+	// but Duration does not reflect traffic.
+	trafficUnavailable bool
 }
 
+// Polyline is the encoded geometry of a route.
+type Polyline struct {
+	EncodedPolyline string `json:"encodedPolyline"`
+}
+
+const (
+	defaultBaseURL   = "https://routes.googleapis.com/distanceMatrix/v2:computeRouteMatrix"
+	defaultRoutesURL = "https://routes.googleapis.com/directions/v2:computeRoutes"
+	defaultTimeout   = 30 * time.Second
+)
+
 // HTTPClient interface for testability
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// routeOptions holds the per-call settings parsed from tool arguments.
+type routeOptions struct {
+	TravelMode               string
+	Units                    string
+	DurationFormat           string
+	RoutingPreference        string
+	RouteModifiers           RouteModifiers
+	ComputeAlternativeRoutes bool
+	// PreferLabel is the route label whose route is reported first; empty
+	// keeps the API's order.
+	PreferLabel             string
+	DepartureTime           time.Time
+	ArrivalTime             time.Time
+	IncludeTrafficFreshness bool
+	LanguageCode            string
+	// NumberLocale is the languageCode given by the caller, whose
+	// separators format distances; empty keeps Go's default formatting.
+	NumberLocale string
+	// RoundMeters is the granularity text output rounds distances to;
+	// 0 reports exact meters. JSON output is never rounded.
+	RoundMeters        int
+	RegionCode         string
+	Format             string
+	Waypoints          []string
+	IncludePolyline    bool
+	IncludeTolls       bool
+	DistanceOnly       bool
+	Debug              bool
+	ShowTiming         bool
+	ResolvedAddresses  bool
+	TransitPreferences *TransitPreferences
+}
+
+// IdenticalAddressBehavior controls how a request whose origin and
+// destination are the same address is handled.
+type IdenticalAddressBehavior int
+
+const (
+	// IdenticalAddressesReject fails the request with ErrIdenticalAddresses.
+	IdenticalAddressesReject IdenticalAddressBehavior = iota
+	// IdenticalAddressesZeroDistance answers with a zero-distance route
+	// without calling the API.
+	IdenticalAddressesZeroDistance
+)
+
 type GeodistanceHandler struct {
-	apiKey string
-	client HTTPClient
+	apiKey     string
+	apiKeys    *apiKeyRing
+	client     HTTPClient
+	baseURL    string
+	routesURL  string
+	geocodeURL string
+	logger     *slog.Logger
+	timeout    time.Duration
+	pool       connectionPool
+	metrics    *Metrics
+	cache      *routeCache
+	limiter    *rate.Limiter
+	provider   Provider
+	fallback   Provider
+	breaker    *circuitBreaker
+	retry      *throttleRetry
+	middleware []Middleware
+
+	cacheFile        string
+	maxResponseBytes int64
+	maxRoutes        int
+	strictDecoding   bool
+	strictZero       bool
+	strictArguments  bool
+	maxCSVRows       int
+	maxAddressLen    int
+	userAgent        string
+
+	// defaultTravelMode applies when a call gives no travelMode; empty
+	// means DRIVE.
+	defaultTravelMode string
+
+	// ownsClient is set when the handler built its HTTP client, so Close
+	// may release the client's idle connections.
+	ownsClient bool
+	closed     atomic.Bool
+
+	identicalAddresses IdenticalAddressBehavior
+	missingTraffic     MissingTrafficBehavior
 }
 
-func NewGeodistanceHandler() (*GeodistanceHandler, error) {
-	return NewGeodistanceHandlerWithClient(&http.Client{
-		Timeout: 30 * time.Second,
-	})
+// NewGeodistanceHandler creates a handler configured by opts. Unless
+// WithAPIKey is given, the key is read from the environment; unless
+// WithHTTPClient is given, a default client honouring WithTimeout,
+// WithConnectionPool and GEODISTANCE_TIMEOUT is used.
+func NewGeodistanceHandler(opts ...Option) (*GeodistanceHandler, error) {
+	transport := newDefaultTransport()
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   defaultTimeout,
+	}
+
+	gh, err := newGeodistanceHandler(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolved after options so WithLogger can report a bad env value
+	if gh.client == client {
+		client.Timeout = gh.resolveTimeout()
+		gh.pool.apply(transport)
+		gh.ownsClient = true
+	}
+
+	return gh, nil
 }
 
-func NewGeodistanceHandlerWithClient(client HTTPClient) (*GeodistanceHandler, error) {
-	// Load API key from environment variable
+// resolveTimeout picks the default client timeout: WithTimeout first, then
+// GEODISTANCE_TIMEOUT, then defaultTimeout.
+func (gh *GeodistanceHandler) resolveTimeout() time.Duration {
+	if gh.timeout > 0 {
+		return gh.timeout
+	}
+
+	value := os.Getenv("GEODISTANCE_TIMEOUT")
+	if value == "" {
+		return defaultTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		if gh.logger != nil {
+			gh.logger.Warn("ignoring invalid GEODISTANCE_TIMEOUT",
+				slog.String("value", value), slog.Duration("fallback", defaultTimeout))
+		}
+		return defaultTimeout
+	}
+
+	return timeout
+}
+
+// NewGeodistanceHandlerWithClient is equivalent to NewGeodistanceHandler
+// with WithHTTPClient(client).
+func NewGeodistanceHandlerWithClient(client HTTPClient, opts ...Option) (*GeodistanceHandler, error) {
+	return newGeodistanceHandler(client, opts)
+}
+
+// loadAPIKeys reads the API key from the file named by GOOGLE_API_KEY_FILE
+// when set, as with mounted container secrets. Otherwise it reads the
+// comma-separated GOOGLE_API_KEYS list, and then the single GOOGLE_API_KEY.
+func loadAPIKeys() ([]string, error) {
+	if path := os.Getenv("GOOGLE_API_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GOOGLE_API_KEY_FILE: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return nil, fmt.Errorf("GOOGLE_API_KEY_FILE %s is empty: %w", path, ErrMissingAPIKey)
+		}
+		return []string{key}, nil
+	}
+
+	if value, ok := os.LookupEnv("GOOGLE_API_KEYS"); ok && strings.TrimSpace(value) != "" {
+		keys := parseAPIKeys(value)
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("GOOGLE_API_KEYS lists no keys: %w", ErrMissingAPIKey)
+		}
+		return keys, nil
+	}
+
 	googleApiKey := os.Getenv("GOOGLE_API_KEY")
 	if googleApiKey == "" {
-		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable not set")
+		return nil, fmt.Errorf("GOOGLE_API_KEY environment variable not set: %w", ErrMissingAPIKey)
+	}
+	return []string{googleApiKey}, nil
+}
+
+// boolFromEnvironment reads a boolean setting from the environment. Unset
+// means false; any other value must parse as a boolean.
+func boolFromEnvironment(name string) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", name, value)
+	}
+	return enabled, nil
+}
+
+// NewGeodistanceHandlerWithKey creates a handler using an explicitly supplied
+// API key instead of reading GOOGLE_API_KEY from the environment.
+func NewGeodistanceHandlerWithKey(apiKey string, client HTTPClient, opts ...Option) (*GeodistanceHandler, error) {
+	if apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	return newGeodistanceHandler(client, append([]Option{WithAPIKey(apiKey)}, opts...))
+}
+
+// newGeodistanceHandler applies opts over the defaults, falling back to
+// the environment for the API key when no option supplied one.
+func newGeodistanceHandler(client HTTPClient, opts []Option) (*GeodistanceHandler, error) {
+	strictArguments, err := boolFromEnvironment("GEODISTANCE_STRICT_ARGUMENTS")
+	if err != nil {
+		return nil, err
+	}
+
+	gh := &GeodistanceHandler{
+		client:          client,
+		baseURL:         defaultBaseURL,
+		routesURL:       defaultRoutesURL,
+		geocodeURL:      defaultGeocodeURL,
+		retry:           newThrottleRetry(defaultThrottleRetries, defaultMaxThrottleDelay),
+		strictArguments: strictArguments,
+	}
+	for _, opt := range opts {
+		opt(gh)
+	}
+	gh.defaultTravelMode = gh.resolveDefaultTravelMode()
+	fake, err := fakeModeFromEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	if fake {
+		// Replaces any client so that no request leaves the process
+		gh.client = fakeClient{}
+		if gh.apiKey == "" {
+			gh.apiKey = fakeAPIKey
+		}
+		if gh.logger != nil {
+			gh.logger.Warn("GEODISTANCE_FAKE is set: returning synthetic results without calling any API")
+		}
+	}
+	if gh.cache != nil && gh.cacheFile != "" {
+		gh.cache.persistTo(gh.cacheFile, gh.logger)
+	}
+
+	if gh.provider == nil && !fake {
+		provider, err := providerFromEnvironment(gh.httpClient())
+		if err != nil {
+			return nil, err
+		}
+		gh.provider = provider
+	}
+	gh.bindProviders()
+
+	if gh.apiKey == "" {
+		keys, err := loadAPIKeys()
+		if err != nil {
+			return nil, err
+		}
+		gh.setAPIKeys(keys)
 	}
 
-	return &GeodistanceHandler{
-		apiKey: googleApiKey,
-		client: client,
-	}, nil
+	return gh, nil
 }
 
 func (gh *GeodistanceHandler) handleDistanceCalculation(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
-	originAddress, err := request.RequireString("originAddress")
+	gh.metrics.incCalculations()
+	ctx = contextWithRequestIDArgument(ctx, request)
+
+	origin, destination, err := parseEndpoints(request, toolCalculateDistance)
 	if err != nil {
-		return nil, fmt.Errorf("missing origin address: %w", err)
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
 	}
 
-	destinationAddress, err := request.RequireString("destinationAddress")
+	opts, err := gh.parseRouteOptions(request)
 	if err != nil {
-		return nil, fmt.Errorf("missing destination address: %w", err)
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
 	}
 
-	if err := gh.validateAddresses(originAddress, destinationAddress); err != nil {
-		return nil, err
+	opts.Waypoints, err = parseWaypoints(request, opts)
+	if err != nil {
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
+	}
+	for i, waypoint := range opts.Waypoints {
+		if err := gh.validateAddress(fmt.Sprintf("waypoint %d", i+1), waypoint); err != nil {
+			gh.metrics.incError(errorCategoryValidation)
+			return nil, invalidArgument(err)
+		}
+	}
+
+	roundTrip, err := parseRoundTrip(request, opts)
+	if err != nil {
+		gh.metrics.incError(errorCategoryValidation)
+		return nil, invalidArgument(err)
+	}
+
+	if err := gh.validateEndpoints(origin, destination); err != nil {
+		// A trip through waypoints may legitimately start and end in the same place
+		identical := errors.Is(err, ErrIdenticalAddresses)
+		if !identical || len(opts.Waypoints) == 0 {
+			if identical && gh.identicalAddresses == IdenticalAddressesZeroDistance {
+				return gh.formatResponse(zeroDistanceResponse(), opts)
+			}
+			gh.metrics.incError(errorCategoryValidation)
+			return nil, invalidArgument(err)
+		}
 	}
 
-	origins := []Origin{{Address: originAddress}}
-	destinations := []Destination{{Address: destinationAddress}}
+	if roundTrip {
+		return gh.handleRoundTrip(ctx, origin, destination, opts)
+	}
+
+	origins := []Origin{{Address: origin.Address, Location: origin.Location}}
+	destinations := []Destination{{Address: destination.Address, Location: destination.Location}}
 
-	responseBody, err := gh.callDistanceMatrix(ctx, origins, destinations)
+	responseBody, err := gh.callDistanceMatrix(ctx, origins, destinations, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return gh.formatResponse(responseBody)
+	result, err := gh.formatResponse(responseBody, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ResolvedAddresses {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: gh.resolvedAddressesText(ctx, responseBody.GeocodingResults),
+		})
+	}
+	if opts.ShowTiming {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: timingText(responseBody.elapsed, responseBody.cached),
+		})
+	}
+	if opts.Debug {
+		result.Content = append(result.Content,
+			mcp.TextContent{
+				Type: "text",
+				Text: estimateElements(1, 1, newCostFeatures(opts)).String(),
+			},
+			mcp.TextContent{
+				Type: "text",
+				Text: gh.debugResponseText(responseBody),
+			},
+		)
+	}
+	return result, nil
+}
+
+func (gh *GeodistanceHandler) parseRouteOptions(request mcp.CallToolRequest) (routeOptions, error) {
+	opts := routeOptions{
+		TravelMode:        enumArgument(request, "travelMode", gh.travelModeDefault()),
+		Units:             enumArgument(request, "units", unitsMetric),
+		DurationFormat:    enumArgument(request, "durationFormat", durationFormatHumanized),
+		RoutingPreference: enumArgument(request, "routingPreference", routingPreferenceTrafficAware),
+		RouteModifiers: RouteModifiers{
+			AvoidTolls:    request.GetBool("avoidTolls", false),
+			AvoidHighways: request.GetBool("avoidHighways", false),
+			AvoidFerries:  request.GetBool("avoidFerries", false),
+			AvoidIndoor:   request.GetBool("avoidIndoor", false),
+		},
+		ComputeAlternativeRoutes: request.GetBool("computeAlternativeRoutes", false),
+		PreferLabel:              enumArgument(request, "preferLabel", ""),
+		IncludeTrafficFreshness:  request.GetBool("includeTrafficFreshness", false),
+		LanguageCode:             request.GetString("languageCode", defaultLanguageCode),
+		Format:                   request.GetString("format", outputFormatText),
+		IncludePolyline:          request.GetBool("includePolyline", false),
+		IncludeTolls:             request.GetBool("includeTolls", false),
+		Debug:                    request.GetBool("debug", false),
+		ShowTiming:               request.GetBool("showTiming", false),
+		ResolvedAddresses:        request.GetBool("includeResolvedAddresses", false),
+		DistanceOnly:             request.GetBool("distanceOnly", false),
+	}
+
+	if err := validateTravelMode(opts.TravelMode); err != nil {
+		return routeOptions{}, err
+	}
+	if err := applyAvoidList(&opts.RouteModifiers, request.GetString("avoid", "")); err != nil {
+		return routeOptions{}, err
+	}
+	vehicle, err := parseEmissionType(enumArgument(request, "emissionType", ""))
+	if err != nil {
+		return routeOptions{}, err
+	}
+	opts.RouteModifiers.VehicleInfo = vehicle
+	if err := validateRouteModifiers(opts.RouteModifiers, opts.TravelMode); err != nil {
+		return routeOptions{}, err
+	}
+	if _, ok := request.GetArguments()["routingPreference"]; ok && !supportsRoutingPreference(opts.TravelMode) {
+		return routeOptions{}, fmt.Errorf("routingPreference is only supported for %s and %s, got travel mode %s", travelModeDrive, travelModeTwoWheeler, opts.TravelMode)
+	}
+	if err := validateUnits(opts.Units); err != nil {
+		return routeOptions{}, err
+	}
+	if err := validateDurationFormat(opts.DurationFormat); err != nil {
+		return routeOptions{}, err
+	}
+	if err := validateRoutingPreference(opts.RoutingPreference); err != nil {
+		return routeOptions{}, err
+	}
+	if err := validateRouteLabel(opts.PreferLabel); err != nil {
+		return routeOptions{}, err
+	}
+	if err := validatePreferLabel(opts); err != nil {
+		return routeOptions{}, err
+	}
+	if opts.DistanceOnly {
+		// Durations are not reported, so traffic data would only add cost
+		if _, ok := request.GetArguments()["routingPreference"]; ok && opts.RoutingPreference != routingPreferenceTrafficUnaware {
+			return routeOptions{}, fmt.Errorf("distanceOnly requires routing preference %s, got %s", routingPreferenceTrafficUnaware, opts.RoutingPreference)
+		}
+		opts.RoutingPreference = routingPreferenceTrafficUnaware
+	}
+	if err := validateLanguageCode(opts.LanguageCode); err != nil {
+		return routeOptions{}, err
+	}
+	if _, ok := request.GetArguments()["languageCode"]; ok {
+		opts.NumberLocale = opts.LanguageCode
+	}
+	regionCode, err := parseRegionCode(strings.TrimSpace(request.GetString("regionCode", "")))
+	if err != nil {
+		return routeOptions{}, err
+	}
+	opts.RegionCode = regionCode
+	if opts.RoundMeters, err = parseRounding(request); err != nil {
+		return routeOptions{}, err
+	}
+	if err := validateOutputFormat(opts.Format); err != nil {
+		return routeOptions{}, err
+	}
+
+	if departureTime := request.GetString("departureTime", ""); departureTime != "" {
+		departure, err := parseDepartureTime(departureTime, opts.RoutingPreference, time.Now())
+		if err != nil {
+			return routeOptions{}, err
+		}
+		opts.DepartureTime = departure
+	}
+
+	if arrivalTime := request.GetString("arrivalTime", ""); arrivalTime != "" {
+		if !opts.DepartureTime.IsZero() {
+			return routeOptions{}, fmt.Errorf("departureTime and arrivalTime cannot both be set")
+		}
+		arrival, err := parseArrivalTime(arrivalTime, opts.TravelMode, time.Now())
+		if err != nil {
+			return routeOptions{}, err
+		}
+		opts.ArrivalTime = arrival
+	}
+
+	transit, err := parseTransitPreferences(request, opts.TravelMode)
+	if err != nil {
+		return routeOptions{}, err
+	}
+	opts.TransitPreferences = transit
+
+	return opts, nil
 }
 
 func (gh *GeodistanceHandler) validateAddresses(origin, destination string) error {
@@ -105,96 +566,540 @@ func (gh *GeodistanceHandler) validateAddresses(origin, destination string) erro
 	if destination == "" {
 		return fmt.Errorf("destination address cannot be empty")
 	}
+	if err := gh.validateAddress("origin address", origin); err != nil {
+		return err
+	}
+	if err := gh.validateAddress("destination address", destination); err != nil {
+		return err
+	}
+	if canonicalAddress(origin) == canonicalAddress(destination) {
+		return fmt.Errorf("%w: %q", ErrIdenticalAddresses, origin)
+	}
 	return nil
 }
 
-func (gh *GeodistanceHandler) buildRequestBody(origins []Origin, destinations []Destination) *RequestBody {
-	return &RequestBody{
+// defaultMaxAddressLength bounds the characters accepted in a single
+// address, since nothing that long geocodes and it only spends quota.
+const defaultMaxAddressLength = 512
+
+func (gh *GeodistanceHandler) maxAddressLength() int {
+	if gh.maxAddressLen <= 0 {
+		return defaultMaxAddressLength
+	}
+	return gh.maxAddressLen
+}
+
+// validateAddress checks the length of a single address and, when it
+// starts with a Plus Code, that code's format.
+func (gh *GeodistanceHandler) validateAddress(label, address string) error {
+	if err := gh.validateAddressLength(label, address); err != nil {
+		return err
+	}
+	return validatePlusCodeAddress(label, address)
+}
+
+// validateAddressLength rejects an address longer than the configured
+// limit, counted in characters rather than bytes.
+func (gh *GeodistanceHandler) validateAddressLength(label, address string) error {
+	if n := utf8.RuneCountInString(address); n > gh.maxAddressLength() {
+		return fmt.Errorf("%s is too long: %d characters exceeds the limit of %d", label, n, gh.maxAddressLength())
+	}
+	return nil
+}
+
+// normalizeAddress trims an address and collapses internal runs of
+// whitespace (spaces, tabs, newlines) into single spaces, so a
+// whitespace-only address becomes empty.
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(address), " ")
+}
+
+// canonicalAddress lowercases a normalized address so trivially different
+// spellings compare equal.
+func canonicalAddress(address string) string {
+	return strings.ToLower(normalizeAddress(address))
+}
+
+// zeroDistanceResponse is the synthetic result for a route from an address
+// to itself.
+func zeroDistanceResponse() *ResponseBody {
+	return &ResponseBody{
+		Routes: []Route{{DistanceMeters: 0, Duration: "0s", RouteLabels: []string{"DEFAULT_ROUTE"}}},
+	}
+}
+
+// checkZeroDistance returns ErrSuspiciousZeroDistance when a route of
+// responseBody is zero meters long although the places of body differ.
+func checkZeroDistance(body *RequestBody, responseBody *ResponseBody) error {
+	places := make(map[string]bool)
+	for _, origin := range body.Origins {
+		places[waypointCacheKey(origin.Address, origin.Location)] = true
+	}
+	for _, waypoint := range body.Intermediates {
+		places[waypointCacheKey(waypoint.Address, waypoint.Location)] = true
+	}
+	for _, destination := range body.Destinations {
+		places[waypointCacheKey(destination.Address, destination.Location)] = true
+	}
+	if len(places) < 2 {
+		return nil
+	}
+
+	for _, route := range responseBody.Routes {
+		if route.DistanceMeters == 0 {
+			return fmt.Errorf("%w: the API returned a 0 meter route; check that each address geocodes to the intended place", ErrSuspiciousZeroDistance)
+		}
+	}
+	return nil
+}
+
+func (gh *GeodistanceHandler) buildRequestBody(origins []Origin, destinations []Destination, opts routeOptions) *RequestBody {
+	body := &RequestBody{
 		Origins:                  origins,
 		Destinations:             destinations,
-		TravelMode:               "DRIVE",
-		RoutingPreference:        "TRAFFIC_AWARE",
-		RequestedReferenceRoutes: []string{"SHORTER_DISTANCE"},
-		LanguageCode:             "en-US",
+		TravelMode:               travelModeOrDefault(opts.TravelMode),
+		LanguageCode:             languageCodeOrDefault(opts.LanguageCode),
+		RegionCode:               opts.RegionCode,
+		ComputeAlternativeRoutes: opts.ComputeAlternativeRoutes,
+		TransitPreferences:       opts.TransitPreferences,
 	}
+
+	// Routing preferences apply to driving and two-wheeler routes; the
+	// shorter-distance reference route is only computed for driving
+	if supportsRoutingPreference(body.TravelMode) {
+		body.RoutingPreference = opts.RoutingPreference
+		if body.RoutingPreference == "" {
+			body.RoutingPreference = routingPreferenceTrafficAware
+		}
+		if computesShorterDistance(opts) {
+			body.RequestedReferenceRoutes = []string{"SHORTER_DISTANCE"}
+		}
+	}
+
+	if !opts.DepartureTime.IsZero() {
+		body.DepartureTime = opts.DepartureTime.UTC().Format(time.RFC3339)
+	}
+	if !opts.ArrivalTime.IsZero() {
+		body.ArrivalTime = opts.ArrivalTime.UTC().Format(time.RFC3339)
+	}
+
+	// Only send routeModifiers when at least one avoidance is requested
+	if opts.RouteModifiers != (RouteModifiers{}) {
+		modifiers := opts.RouteModifiers
+		body.RouteModifiers = &modifiers
+	}
+
+	for _, address := range opts.Waypoints {
+		body.Intermediates = append(body.Intermediates, Waypoint{Address: address})
+	}
+
+	if opts.IncludeTolls {
+		body.ExtraComputations = []string{extraComputationTolls}
+	}
+
+	return body
+}
+
+// routeFieldMask returns the response fields to request for a call with
+// the given options.
+func routeFieldMask(opts routeOptions) string {
+	return fieldMask(fieldMaskFeatures{
+		DistanceOnly:     opts.DistanceOnly,
+		Polyline:         opts.IncludePolyline,
+		Tolls:            opts.IncludeTolls,
+		GeocodingResults: opts.ResolvedAddresses,
+	})
+}
+
+func (gh *GeodistanceHandler) createRequest(ctx context.Context, body *RequestBody, fieldMask string) (*http.Request, error) {
+	if len(body.Intermediates) > 0 {
+		return gh.createComputeRoutesRequest(ctx, body, fieldMask)
+	}
+
+	endpoint := gh.baseURL
+	if endpoint == "" {
+		endpoint = defaultBaseURL
+	}
+	return gh.newAPIRequest(ctx, endpoint, body, fieldMask)
 }
 
-func (gh *GeodistanceHandler) createRequest(ctx context.Context, body *RequestBody) (*http.Request, error) {
+// newAPIRequest builds an authenticated Routes API POST request carrying
+// the JSON-encoded body and the given response field mask.
+func (gh *GeodistanceHandler) newAPIRequest(ctx context.Context, endpoint string, body interface{}, fieldMask string) (*http.Request, error) {
 	jsonData, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal json: %w", err)
 	}
 
-	url := "https://routes.googleapis.com/distanceMatrix/v2:computeRouteMatrix"
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Goog-Api-Key", gh.apiKey)
-	req.Header.Set("X-Goog-FieldMask", "routes.duration,routes.routeLabels,routes.distanceMeters")
+	req.Header.Set(apiKeyHeader, gh.nextAPIKey())
+	req.Header.Set("X-Goog-FieldMask", fieldMask)
+	req.Header.Set("User-Agent", gh.userAgentOrDefault())
+	if id := RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
 
 	return req, nil
 }
 
+// userAgentOrDefault returns the configured User-Agent, defaulting to the
+// server name and Version.
+func (gh *GeodistanceHandler) userAgentOrDefault() string {
+	if gh.userAgent == "" {
+		return serverName + "/" + Version
+	}
+	return gh.userAgent
+}
+
+// waitRateLimit blocks until the rate limiter allows another API call. It
+// is a no-op when no limit is configured.
+func (gh *GeodistanceHandler) waitRateLimit(ctx context.Context) error {
+	if gh.limiter == nil {
+		return nil
+	}
+	if err := gh.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+	return nil
+}
+
+// doOnce executes req with client and returns as soon as ctx is done, even
+// if the client itself ignores the context. A response that arrives after
+// the deadline is closed and discarded.
+func (gh *GeodistanceHandler) doOnce(ctx context.Context, client HTTPClient, req *http.Request) (*http.Response, error) {
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+
+	if gh.closed.Load() {
+		return nil, ErrHandlerClosed
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		resp, err := client.Do(req)
+		done <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return r.resp, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.resp != nil {
+				r.resp.Body.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
 func (gh *GeodistanceHandler) processResponse(resp *http.Response) (*ResponseBody, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		bodyBytes := gh.readErrorBody(resp.Body)
+		return nil, errorFromResponse(resp.StatusCode, bodyBytes)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := gh.readResponseBody(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
+	}
+
+	// Some gateways wrap upstream failures in a 200 with an error object
+	if apiErr := parseAPIError(bodyBytes); apiErr != nil {
+		apiErr.HTTPStatus = resp.StatusCode
+		return nil, apiErr
 	}
 
 	var responseBody ResponseBody
-	if err := json.Unmarshal(bodyBytes, &responseBody); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := gh.decodeResponse(bodyBytes, &responseBody); err != nil {
+		return nil, err
 	}
 
 	if len(responseBody.Routes) == 0 {
-		return nil, fmt.Errorf("no routes found in response")
+		return nil, fmt.Errorf("%w in response", ErrNoRoute)
 	}
+	responseBody.raw = bodyBytes
 
 	return &responseBody, nil
 }
 
-func (gh *GeodistanceHandler) formatResponse(responseBody *ResponseBody) (*mcp.CallToolResult, error) {
+func (gh *GeodistanceHandler) formatResponse(responseBody *ResponseBody, opts routeOptions) (*mcp.CallToolResult, error) {
 	if len(responseBody.Routes) == 0 {
-		return nil, fmt.Errorf("no routes available")
+		return nil, ErrNoRoute
+	}
+
+	routes := responseBody.Routes
+	if !opts.ComputeAlternativeRoutes {
+		routes = referenceRoutes(routes)
 	}
+	routes = preferRoute(routes, opts.PreferLabel)
 
-	route := responseBody.Routes[0]
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
+	if opts.Format == outputFormatJSON {
+		return formatJSONResponse(routes, opts)
+	}
+
+	if !opts.ComputeAlternativeRoutes {
+		content := []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
-				Text: fmt.Sprintf("Route distance: %d meters, Duration: %s", route.DistanceMeters, route.Duration),
+				Text: "Route " + gh.formatRoute(routes[0], opts),
 			},
-		},
-	}, nil
+		}
+		if len(routes) > 1 {
+			title := "Shorter distance route "
+			if !slices.Contains(routes[1].RouteLabels, routeLabelShorterDistance) && slices.Contains(routes[1].RouteLabels, routeLabelDefault) {
+				title = "Default route "
+			}
+			content = append(content, mcp.TextContent{
+				Type: "text",
+				Text: title + gh.formatRoute(routes[1], opts),
+			})
+		}
+		return &mcp.CallToolResult{Content: content}, nil
+	}
+
+	content := make([]mcp.Content, 0, len(routes))
+	for i, route := range routes {
+		prefix := fmt.Sprintf("Route %d", i+1)
+		if len(route.RouteLabels) > 0 {
+			prefix += fmt.Sprintf(" (%s)", strings.Join(route.RouteLabels, ", "))
+		}
+		content = append(content, mcp.TextContent{
+			Type: "text",
+			Text: prefix + " " + gh.formatRoute(route, opts),
+		})
+	}
+
+	return &mcp.CallToolResult{Content: content}, nil
+}
+
+const (
+	routeLabelDefault          = "DEFAULT_ROUTE"
+	routeLabelDefaultAlternate = "DEFAULT_ROUTE_ALTERNATE"
+	routeLabelShorterDistance  = "SHORTER_DISTANCE"
+)
+
+// routeLabels lists the accepted preferLabel values, as advertised in the
+// tool schema.
+var routeLabels = []string{routeLabelDefault, routeLabelShorterDistance, routeLabelDefaultAlternate}
+
+func validateRouteLabel(label string) error {
+	switch label {
+	case "", routeLabelDefault, routeLabelShorterDistance, routeLabelDefaultAlternate:
+		return nil
+	default:
+		return fmt.Errorf("invalid preferLabel %q: must be %s, %s or %s", label,
+			routeLabelDefault, routeLabelShorterDistance, routeLabelDefaultAlternate)
+	}
+}
+
+// validatePreferLabel rejects a preferLabel whose route the request does
+// not compute, since preferring it would silently have no effect.
+func validatePreferLabel(opts routeOptions) error {
+	switch opts.PreferLabel {
+	case routeLabelDefaultAlternate:
+		if !opts.ComputeAlternativeRoutes {
+			return fmt.Errorf("preferLabel %s requires computeAlternativeRoutes", routeLabelDefaultAlternate)
+		}
+	case routeLabelShorterDistance:
+		if opts.DistanceOnly {
+			return fmt.Errorf("preferLabel %s cannot be combined with distanceOnly", routeLabelShorterDistance)
+		}
+		if !computesShorterDistance(opts) {
+			return fmt.Errorf("preferLabel %s requires travel mode %s, got %s", routeLabelShorterDistance, travelModeDrive, opts.TravelMode)
+		}
+	}
+	return nil
+}
+
+// computesShorterDistance reports whether the request asks for the
+// shorter-distance reference route alongside the default route.
+func computesShorterDistance(opts routeOptions) bool {
+	return travelModeOrDefault(opts.TravelMode) == travelModeDrive && !opts.DistanceOnly
+}
+
+// preferRoute moves the first route carrying label to the front, keeping
+// the order of the others. Routes are returned unchanged when label is
+// empty or no route carries it, so the first route stays the fallback.
+func preferRoute(routes []Route, label string) []Route {
+	if label == "" {
+		return routes
+	}
+	for i, route := range routes {
+		if !slices.Contains(route.RouteLabels, label) {
+			continue
+		}
+		if i == 0 {
+			return routes
+		}
+		preferred := make([]Route, 0, len(routes))
+		preferred = append(preferred, route)
+		preferred = append(preferred, routes[:i]...)
+		return append(preferred, routes[i+1:]...)
+	}
+	return routes
+}
+
+// referenceRoutes picks the default route and, when the API returned one,
+// the requested shorter-distance reference route. Routes are matched by
+// label; when the response carries no labels at all, index order is used
+// instead, with the first route as the default.
+func referenceRoutes(routes []Route) []Route {
+	defaultIndex, shorterIndex := -1, -1
+	labeled := false
+	for i, route := range routes {
+		if len(route.RouteLabels) > 0 {
+			labeled = true
+		}
+		if defaultIndex < 0 && slices.Contains(route.RouteLabels, routeLabelDefault) {
+			defaultIndex = i
+		}
+		if shorterIndex < 0 && slices.Contains(route.RouteLabels, routeLabelShorterDistance) {
+			shorterIndex = i
+		}
+	}
+
+	if !labeled && len(routes) > 1 {
+		defaultIndex, shorterIndex = 0, 1
+	}
+	if defaultIndex < 0 {
+		defaultIndex = 0
+	}
+
+	selected := []Route{routes[defaultIndex]}
+	if shorterIndex >= 0 && shorterIndex != defaultIndex {
+		selected = append(selected, routes[shorterIndex])
+	}
+	return selected
+}
+
+func (gh *GeodistanceHandler) formatRoute(route Route, opts routeOptions) string {
+	text := fmt.Sprintf("distance: %s", formatDistance(roundMeters(route.DistanceMeters, opts.RoundMeters), opts.Units, opts.NumberLocale))
+	if !opts.DistanceOnly {
+		duration := formatDuration(route.Duration, opts.DurationFormat)
+		text += fmt.Sprintf(", Duration: %s", duration)
+		if route.trafficUnavailable {
+			text += " (" + trafficNoticeText + ")"
+		}
+		// The API also returns the free-flow duration for traffic-unaware
+		// and non-driving routes, where it matches the duration itself
+		if route.StaticDuration != "" {
+			if static := formatDuration(route.StaticDuration, opts.DurationFormat); static != duration {
+				text += fmt.Sprintf(" (no traffic: %s)", static)
+			}
+		}
+	}
+	if route.Description != "" {
+		text += fmt.Sprintf(", Via: %s", route.Description)
+	}
+	if len(route.Warnings) > 0 {
+		text += fmt.Sprintf(", Warnings: %s", strings.Join(route.Warnings, "; "))
+	}
+	if opts.IncludeTrafficFreshness && !opts.DistanceOnly {
+		fresh, source := trafficFreshness(opts)
+		flag := "no"
+		if fresh {
+			flag = "yes"
+		}
+		text += fmt.Sprintf(", Fresh traffic data: %s (%s)", flag, source)
+	}
+	if opts.IncludePolyline && route.Polyline != nil {
+		text += fmt.Sprintf(", Polyline: %s", route.Polyline.EncodedPolyline)
+	}
+	if opts.IncludeTolls {
+		text += fmt.Sprintf(", Estimated tolls: %s", formatTolls(route))
+	}
+	return text
 }
 
 func (gh *GeodistanceHandler) callDistanceMatrix(
 	ctx context.Context,
 	origins []Origin,
 	destinations []Destination,
+	opts routeOptions,
 ) (*ResponseBody, error) {
-	body := gh.buildRequestBody(origins, destinations)
+	body := gh.buildRequestBody(origins, destinations, opts)
+	fieldMask := routeFieldMask(opts)
+
+	var cacheKey string
+	if gh.cache != nil {
+		// The field mask changes the response shape, so it is part of the key
+		cacheKey = routeCacheKey(body) + fieldMask
+		if cached, ok := gh.cache.get(cacheKey); ok {
+			// A copy, so the flag does not leak into the cached entry
+			hit := *cached
+			hit.cached = true
+			return &hit, nil
+		}
+	}
 
-	req, err := gh.createRequest(ctx, body)
+	if err := gh.breaker.allow(); err != nil {
+		return nil, annotateRequestID(ctx, err)
+	}
+	start := time.Now()
+	responseBody, err := gh.computeRoutes(ctx, body, fieldMask)
+	gh.breaker.record(err)
 	if err != nil {
 		return nil, err
 	}
+	responseBody = gh.degradeMissingTraffic(ctx, body, fieldMask, opts, responseBody)
+	responseBody.elapsed = time.Since(start)
+	if gh.strictZero {
+		if err := checkZeroDistance(body, responseBody); err != nil {
+			return nil, err
+		}
+	}
+
+	if gh.cache != nil {
+		gh.cache.add(cacheKey, responseBody)
+	}
+
+	return responseBody, nil
+}
+
+// callRoutesAPI is the Google Routes API implementation behind
+// googleProvider, with rate limiting, metrics, and logging.
+func (gh *GeodistanceHandler) callRoutesAPI(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error) {
+	if err := gh.waitRateLimit(ctx); err != nil {
+		return nil, annotateRequestID(ctx, err)
+	}
 
-	resp, err := gh.client.Do(req)
+	req, err := gh.createRequest(ctx, body, fieldMask)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
 
-	return gh.processResponse(resp)
+	start := time.Now()
+	resp, err := gh.do(ctx, req)
+	gh.metrics.observeRequestDuration(time.Since(start))
+	if err != nil {
+		err = fmt.Errorf("failed to execute request: %w", err)
+		gh.metrics.incError(errorCategoryNetwork)
+		gh.logAPICall(ctx, req, body.TravelMode, 0, time.Since(start), err)
+		return nil, annotateRequestID(ctx, err)
+	}
+
+	responseBody, err := gh.processResponse(resp)
+	if err != nil {
+		gh.metrics.incError(errorCategoryAPI)
+		gh.logAPICall(ctx, req, body.TravelMode, resp.StatusCode, time.Since(start), err)
+		return nil, annotateRequestID(ctx, err)
+	}
+
+	gh.logAPICall(ctx, req, body.TravelMode, resp.StatusCode, time.Since(start), nil,
+		slog.Int("distanceMeters", responseBody.Routes[0].DistanceMeters))
+
+	return responseBody, nil
 }
@@ -3,12 +3,16 @@ package geodistanceserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -83,6 +87,9 @@ func TestNewGeodistanceHandler(t *testing.T) {
 				if handler != nil {
 					t.Error("expected nil handler when error occurs")
 				}
+				if !errors.Is(err, ErrMissingAPIKey) {
+					t.Errorf("expected ErrMissingAPIKey, got %v", err)
+				}
 			} else {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
@@ -117,6 +124,134 @@ func TestNewGeodistanceHandlerWithClient(t *testing.T) {
 	}
 }
 
+func TestNewGeodistanceHandlerWithKey(t *testing.T) {
+	mockClient := &MockHTTPClient{}
+
+	// The explicit key must not depend on the environment
+	os.Unsetenv("GOOGLE_API_KEY")
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		expectErr bool
+	}{
+		{
+			name:      "valid API key",
+			apiKey:    "explicit-key",
+			expectErr: false,
+		},
+		{
+			name:      "empty API key",
+			apiKey:    "",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewGeodistanceHandlerWithKey(tt.apiKey, mockClient)
+
+			if tt.expectErr {
+				if !errors.Is(err, ErrMissingAPIKey) {
+					t.Errorf("expected ErrMissingAPIKey, got %v", err)
+				}
+				if handler != nil {
+					t.Error("expected nil handler when error occurs")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if handler.apiKey != tt.apiKey {
+				t.Errorf("expected API key %s, got %s", tt.apiKey, handler.apiKey)
+			}
+			if handler.client != mockClient {
+				t.Error("expected mock client to be set")
+			}
+			if handler.baseURL != defaultBaseURL {
+				t.Errorf("expected default base URL, got %s", handler.baseURL)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandlerWithClient_APIKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "api-key")
+	if err := os.WriteFile(keyFile, []byte("file-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		keyFile       string
+		envKey        string
+		expectedKey   string
+		expectErr     bool
+		expectMissing bool
+	}{
+		{
+			name:        "key loaded from file",
+			keyFile:     keyFile,
+			expectedKey: "file-key",
+		},
+		{
+			name:        "file takes precedence over env",
+			keyFile:     keyFile,
+			envKey:      "env-key",
+			expectedKey: "file-key",
+		},
+		{
+			name:        "env used without file",
+			envKey:      "env-key",
+			expectedKey: "env-key",
+		},
+		{
+			name:      "missing file",
+			keyFile:   filepath.Join(dir, "does-not-exist"),
+			envKey:    "env-key",
+			expectErr: true,
+		},
+		{
+			name:          "empty file",
+			keyFile:       emptyFile,
+			expectErr:     true,
+			expectMissing: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_API_KEY_FILE", tt.keyFile)
+			t.Setenv("GOOGLE_API_KEY", tt.envKey)
+
+			handler, err := NewGeodistanceHandlerWithClient(&MockHTTPClient{})
+
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if errors.Is(err, ErrMissingAPIKey) != tt.expectMissing {
+					t.Errorf("expected ErrMissingAPIKey=%v, got %v", tt.expectMissing, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if handler.apiKey != tt.expectedKey {
+				t.Errorf("expected API key %q, got %q", tt.expectedKey, handler.apiKey)
+			}
+		})
+	}
+}
+
 func TestGeodistanceHandler_validateAddresses(t *testing.T) {
 	handler := &GeodistanceHandler{}
 
@@ -150,6 +285,54 @@ func TestGeodistanceHandler_validateAddresses(t *testing.T) {
 			destination: "",
 			expectErr:   true,
 		},
+		{
+			name:        "identical addresses",
+			origin:      "New York",
+			destination: "New York",
+			expectErr:   true,
+		},
+		{
+			name:        "identical ignoring case",
+			origin:      "new york",
+			destination: "NEW YORK",
+			expectErr:   true,
+		},
+		{
+			name:        "identical ignoring whitespace",
+			origin:      "  New   York ",
+			destination: "New York",
+			expectErr:   true,
+		},
+		{
+			name:        "identical with tabs and mixed case",
+			origin:      "1600 Amphitheatre\tParkway",
+			destination: "1600 amphitheatre parkway",
+			expectErr:   true,
+		},
+		{
+			name:        "origin at the length limit",
+			origin:      strings.Repeat("a", defaultMaxAddressLength),
+			destination: "Los Angeles",
+			expectErr:   false,
+		},
+		{
+			name:        "origin one over the length limit",
+			origin:      strings.Repeat("a", defaultMaxAddressLength+1),
+			destination: "Los Angeles",
+			expectErr:   true,
+		},
+		{
+			name:        "destination far over the length limit",
+			origin:      "New York",
+			destination: strings.Repeat("Main Street ", 1000),
+			expectErr:   true,
+		},
+		{
+			name:        "multibyte characters counted once",
+			origin:      strings.Repeat("é", defaultMaxAddressLength),
+			destination: "Los Angeles",
+			expectErr:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,179 +349,1362 @@ func TestGeodistanceHandler_validateAddresses(t *testing.T) {
 	}
 }
 
-func TestGeodistanceHandler_buildRequestBody(t *testing.T) {
-	handler := &GeodistanceHandler{}
+func TestGeodistanceHandler_handleDistanceCalculation_IdenticalAddresses(t *testing.T) {
+	tests := []struct {
+		name         string
+		behavior     IdenticalAddressBehavior
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name:      "reject by default",
+			behavior:  IdenticalAddressesReject,
+			expectErr: true,
+		},
+		{
+			name:         "zero distance short circuit",
+			behavior:     IdenticalAddressesZeroDistance,
+			expectedText: "Route distance: 0.00 km (0 meters), Duration: 0s",
+		},
+	}
 
-	origins := []Origin{{Address: "New York"}}
-	destinations := []Destination{{Address: "Los Angeles"}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					t.Error("identical addresses must not reach the API")
+					return nil, fmt.Errorf("unexpected call")
+				}},
+			}
+			WithIdenticalAddressBehavior(tt.behavior)(handler)
 
-	body := handler.buildRequestBody(origins, destinations)
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance",
+					Arguments: map[string]interface{}{
+						"originAddress":      " Omaha, Nebraska",
+						"destinationAddress": "omaha,  NEBRASKA ",
+					},
+				},
+			}
 
-	if body == nil {
-		t.Error("expected non-nil request body")
-	}
-	if len(body.Origins) != 1 || body.Origins[0].Address != "New York" {
-		t.Error("origins not set correctly")
-	}
-	if len(body.Destinations) != 1 || body.Destinations[0].Address != "Los Angeles" {
-		t.Error("destinations not set correctly")
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if tt.expectErr {
+				if !errors.Is(err, ErrIdenticalAddresses) {
+					t.Errorf("expected ErrIdenticalAddresses, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, text)
+			}
+		})
 	}
-	if body.TravelMode != "DRIVE" {
-		t.Error("travel mode not set correctly")
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "New York", expected: "New York"},
+		{input: "  New York  ", expected: "New York"},
+		{input: "New    York", expected: "New York"},
+		{input: "1600\tAmphitheatre\nParkway", expected: "1600 Amphitheatre Parkway"},
+		{input: " \t\n ", expected: ""},
+		{input: "", expected: ""},
 	}
-	if body.RoutingPreference != "TRAFFIC_AWARE" {
-		t.Error("routing preference not set correctly")
+
+	for _, tt := range tests {
+		if got := normalizeAddress(tt.input); got != tt.expected {
+			t.Errorf("normalizeAddress(%q): expected %q, got %q", tt.input, tt.expected, got)
+		}
 	}
 }
 
-func TestGeodistanceHandler_createRequest(t *testing.T) {
-	handler := &GeodistanceHandler{apiKey: "test-key"}
-	ctx := context.Background()
-
-	body := &RequestBody{
-		Origins:      []Origin{{Address: "New York"}},
-		Destinations: []Destination{{Address: "Los Angeles"}},
-		TravelMode:   "DRIVE",
+func TestGeodistanceHandler_handleDistanceCalculation_Whitespace(t *testing.T) {
+	tests := []struct {
+		name                string
+		origin              string
+		destination         string
+		expectErr           bool
+		expectedOrigin      string
+		expectedDestination string
+	}{
+		{
+			name:                "tabs newlines and repeated spaces",
+			origin:              "\t1600  Amphitheatre\nParkway ",
+			destination:         "  Mountain   View,\tCA\n",
+			expectedOrigin:      "1600 Amphitheatre Parkway",
+			expectedDestination: "Mountain View, CA",
+		},
+		{
+			name:        "whitespace-only origin",
+			origin:      " \t\n ",
+			destination: "Mountain View, CA",
+			expectErr:   true,
+		},
+		{
+			name:        "whitespace-only destination",
+			origin:      "Palo Alto, CA",
+			destination: "   ",
+			expectErr:   true,
+		},
 	}
 
-	req, err := handler.createRequest(ctx, body)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					var body RequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if body.Origins[0].Address != tt.expectedOrigin {
+						t.Errorf("expected origin %q, got %q", tt.expectedOrigin, body.Origins[0].Address)
+					}
+					if body.Destinations[0].Address != tt.expectedDestination {
+						t.Errorf("expected destination %q, got %q", tt.expectedDestination, body.Destinations[0].Address)
+					}
+					return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+				}},
+			}
 
-	if err != nil {
-		t.Errorf("unexpected error: %v", err)
-	}
-	if req == nil {
-		t.Error("expected non-nil request")
-	}
-	if req.Method != "POST" {
-		t.Errorf("expected POST method, got %s", req.Method)
-	}
-	if req.Header.Get("Content-Type") != "application/json" {
-		t.Error("content type not set correctly")
-	}
-	if req.Header.Get("X-Goog-Api-Key") != "test-key" {
-		t.Error("API key header not set correctly")
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance",
+					Arguments: map[string]interface{}{
+						"originAddress":      tt.origin,
+						"destinationAddress": tt.destination,
+					},
+				},
+			}
+
+			_, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
 	}
 }
 
-func TestGeodistanceHandler_processResponse(t *testing.T) {
-	handler := &GeodistanceHandler{}
-
+func TestGeodistanceHandler_handleDistanceCalculation_LanguageCode(t *testing.T) {
 	tests := []struct {
-		name       string
-		statusCode int
-		body       string
-		expectErr  bool
+		name             string
+		args             map[string]interface{}
+		expectErr        bool
+		expectedCode     string
+		expectedDistance string
 	}{
 		{
-			name:       "successful response",
-			statusCode: http.StatusOK,
-			body:       createValidAPIResponse(),
-			expectErr:  false,
+			name:             "default language",
+			args:             map[string]interface{}{},
+			expectedCode:     "en-US",
+			expectedDistance: "1.00 km (1000 meters)",
 		},
 		{
-			name:       "API error",
-			statusCode: http.StatusBadRequest,
-			body:       `{"error": "Invalid request"}`,
-			expectErr:  true,
+			name:         "french",
+			args:         map[string]interface{}{"languageCode": "fr-FR"},
+			expectedCode: "fr-FR",
 		},
 		{
-			name:       "invalid JSON",
-			statusCode: http.StatusOK,
-			body:       "invalid json",
-			expectErr:  true,
+			name:             "german separators",
+			args:             map[string]interface{}{"languageCode": "de-DE"},
+			expectedCode:     "de-DE",
+			expectedDistance: "1,00 km (1.000 meters)",
 		},
 		{
-			name:       "no routes",
-			statusCode: http.StatusOK,
-			body:       `{"routes": []}`,
-			expectErr:  true,
+			name:      "invalid language",
+			args:      map[string]interface{}{"languageCode": "not a language"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					called = true
+					var body RequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if body.LanguageCode != tt.expectedCode {
+						t.Errorf("expected languageCode %q, got %q", tt.expectedCode, body.LanguageCode)
+					}
+					return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+				}},
+			}
+
+			args := map[string]interface{}{
+				"originAddress":      "Paris",
+				"destinationAddress": "Lyon",
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: args},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if called {
+					t.Error("expected no API call for an invalid languageCode")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.expectedDistance != "" {
+				text := result.Content[0].(mcp.TextContent).Text
+				if !strings.Contains(text, tt.expectedDistance) {
+					t.Errorf("expected distance %q in %q", tt.expectedDistance, text)
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_Polyline(t *testing.T) {
+	const encoded = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	polylineResponse := `{"routes":[{"distanceMeters":1000,"duration":"300s","routeLabels":["DEFAULT_ROUTE"],"polyline":{"encodedPolyline":"` + encoded + `"}}]}`
+
+	tests := []struct {
+		name            string
+		includePolyline bool
+		format          string
+	}{
+		{name: "not requested", includePolyline: false, format: outputFormatText},
+		{name: "requested", includePolyline: true, format: outputFormatText},
+		{name: "requested as json", includePolyline: true, format: outputFormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					hasMask := strings.Contains(req.Header.Get("X-Goog-FieldMask"), polylineFieldMask)
+					if hasMask != tt.includePolyline {
+						t.Errorf("expected polyline in field mask=%v, got mask %q", tt.includePolyline, req.Header.Get("X-Goog-FieldMask"))
+					}
+					return createMockResponse(http.StatusOK, polylineResponse), nil
+				}},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance",
+					Arguments: map[string]interface{}{
+						"originAddress":      "New York",
+						"destinationAddress": "Boston",
+						"includePolyline":    tt.includePolyline,
+						"format":             tt.format,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if strings.Contains(text, encoded) != tt.includePolyline {
+				t.Errorf("expected polyline in output=%v, got %q", tt.includePolyline, text)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_DistanceOnly(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name:         "distance only",
+			args:         map[string]interface{}{"distanceOnly": true},
+			expectedText: "Route distance: 1.00 km (1000 meters)",
+		},
+		{
+			name: "distance only with freshness",
+			args: map[string]interface{}{
+				"distanceOnly":            true,
+				"includeTrafficFreshness": true,
+			},
+			expectedText: "Route distance: 1.00 km (1000 meters)",
+		},
+		{
+			name: "conflicting routing preference",
+			args: map[string]interface{}{
+				"distanceOnly":      true,
+				"routingPreference": "TRAFFIC_AWARE_OPTIMAL",
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if mask := req.Header.Get("X-Goog-FieldMask"); mask != "routes.distanceMeters" {
+						t.Errorf("expected distance-only field mask, got %q", mask)
+					}
+					var body RequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if body.RoutingPreference != routingPreferenceTrafficUnaware {
+						t.Errorf("expected routing preference %s, got %s", routingPreferenceTrafficUnaware, body.RoutingPreference)
+					}
+					if len(body.RequestedReferenceRoutes) != 0 {
+						t.Errorf("expected no reference routes, got %v", body.RequestedReferenceRoutes)
+					}
+					return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":1000}]}`), nil
+				}},
+			}
+
+			args := map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Boston",
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: args},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != 1 {
+				t.Fatalf("expected 1 content item, got %d", len(result.Content))
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if text != tt.expectedText {
+				t.Errorf("expected %q, got %q", tt.expectedText, text)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_buildRequestBody(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Los Angeles"}}
+
+	body := handler.buildRequestBody(origins, destinations, routeOptions{})
+
+	if body == nil {
+		t.Error("expected non-nil request body")
+	}
+	if len(body.Origins) != 1 || body.Origins[0].Address != "New York" {
+		t.Error("origins not set correctly")
+	}
+	if len(body.Destinations) != 1 || body.Destinations[0].Address != "Los Angeles" {
+		t.Error("destinations not set correctly")
+	}
+	if body.TravelMode != "DRIVE" {
+		t.Error("travel mode not set correctly")
+	}
+	if body.RoutingPreference != "TRAFFIC_AWARE" {
+		t.Error("routing preference not set correctly")
+	}
+	if body.RouteModifiers != nil {
+		t.Error("route modifiers should be omitted when no avoidance is requested")
+	}
+}
+
+func TestGeodistanceHandler_buildRequestBody_RouteModifiers(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Los Angeles"}}
+
+	tests := []struct {
+		name      string
+		modifiers RouteModifiers
+		expected  string
+	}{
+		{
+			name:      "no modifiers",
+			modifiers: RouteModifiers{},
+			expected:  "",
+		},
+		{
+			name:      "avoid tolls",
+			modifiers: RouteModifiers{AvoidTolls: true},
+			expected:  `{"avoidTolls":true}`,
+		},
+		{
+			name:      "avoid highways",
+			modifiers: RouteModifiers{AvoidHighways: true},
+			expected:  `{"avoidHighways":true}`,
+		},
+		{
+			name:      "avoid ferries",
+			modifiers: RouteModifiers{AvoidFerries: true},
+			expected:  `{"avoidFerries":true}`,
+		},
+		{
+			name:      "avoid indoor",
+			modifiers: RouteModifiers{AvoidIndoor: true},
+			expected:  `{"avoidIndoor":true}`,
+		},
+		{
+			name:      "all modifiers",
+			modifiers: RouteModifiers{AvoidTolls: true, AvoidHighways: true, AvoidFerries: true},
+			expected:  `{"avoidTolls":true,"avoidHighways":true,"avoidFerries":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := handler.buildRequestBody(origins, destinations, routeOptions{RouteModifiers: tt.modifiers})
+
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(data, &raw); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, ok := raw["routeModifiers"]
+			if tt.expected == "" {
+				if ok {
+					t.Errorf("expected routeModifiers to be omitted, got %s", got)
+				}
+				return
+			}
+			if string(got) != tt.expected {
+				t.Errorf("expected routeModifiers %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_buildRequestBody_DepartureTime(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	departure := time.Date(2030, 1, 1, 8, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	body := handler.buildRequestBody(nil, nil, routeOptions{DepartureTime: departure})
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"departureTime":"2030-01-01T13:30:00Z"`) {
+		t.Errorf("departure time not serialized as UTC RFC3339: %s", data)
+	}
+
+	data, err = json.Marshal(handler.buildRequestBody(nil, nil, routeOptions{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "departureTime") {
+		t.Error("departureTime should be omitted when not set")
+	}
+}
+
+func TestRequestBodies_OmitUnsetFields(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	// Walking has no routing preference, so only required fields are set
+	opts := routeOptions{TravelMode: travelModeWalk}
+	single := handler.buildRequestBody([]Origin{{Address: "A"}}, []Destination{{Address: "B"}}, opts)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		expected []string
+	}{
+		{
+			name:     "routes",
+			body:     single,
+			expected: []string{"destinations", "languageCode", "origins", "travelMode"},
+		},
+		{
+			name:     "route matrix",
+			body:     handler.buildMatrixRequestBody([]string{"A"}, []string{"B"}, opts),
+			expected: []string{"destinations", "languageCode", "origins", "travelMode"},
+		},
+		{
+			name:     "compute routes",
+			body:     newComputeRoutesBody(single),
+			expected: []string{"destination", "languageCode", "origin", "travelMode"},
+		},
+		{
+			name:     "zero value",
+			body:     RequestBody{},
+			expected: []string{"destinations", "origins"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var raw map[string]json.RawMessage
+			if err := json.Unmarshal(data, &raw); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			keys := make([]string, 0, len(raw))
+			for key := range raw {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			if !slices.Equal(keys, tt.expected) {
+				t.Errorf("expected fields %v, got %v in %s", tt.expected, keys, data)
+			}
+		})
+	}
+}
+
+func TestRequestBody_KeepsZeroCoordinates(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	origin := Origin{Location: &Location{LatLng: LatLng{Latitude: 0, Longitude: 0}}}
+	body := handler.buildRequestBody([]Origin{origin}, []Destination{{Address: "B"}}, routeOptions{})
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"latLng":{"latitude":0,"longitude":0}`) {
+		t.Errorf("zero coordinates should be sent: %s", data)
+	}
+	if !strings.Contains(string(data), `"origins":[{"location":`) {
+		t.Errorf("empty origin address should be omitted: %s", data)
+	}
+}
+
+func TestGeodistanceHandler_parseRouteOptions_AvoidIndoor(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "walking",
+			args: map[string]interface{}{"travelMode": "WALK", "avoidIndoor": true},
+		},
+		{
+			name:      "default travel mode",
+			args:      map[string]interface{}{"avoidIndoor": true},
+			expectErr: true,
+		},
+		{
+			name:      "cycling",
+			args:      map[string]interface{}{"travelMode": "BICYCLE", "avoidIndoor": true},
+			expectErr: true,
+		},
+		{
+			name:      "two wheeler",
+			args:      map[string]interface{}{"travelMode": "TWO_WHEELER", "avoidIndoor": true},
+			expectErr: true,
+		},
+		{
+			name: "false for driving",
+			args: map[string]interface{}{"travelMode": "DRIVE", "avoidIndoor": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			opts, err := handler.parseRouteOptions(request)
+			if tt.expectErr {
+				if err == nil || !strings.Contains(err.Error(), "avoidIndoor is only supported for WALK") {
+					t.Errorf("expected avoidIndoor error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.RouteModifiers.AvoidIndoor != tt.args["avoidIndoor"] {
+				t.Errorf("expected avoidIndoor %v, got %v", tt.args["avoidIndoor"], opts.RouteModifiers.AvoidIndoor)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_parseRouteOptions_DepartureTime(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name:      "future departure",
+			args:      map[string]interface{}{"departureTime": future},
+			expectErr: false,
+		},
+		{
+			name:      "past departure",
+			args:      map[string]interface{}{"departureTime": past},
+			expectErr: true,
+		},
+		{
+			name: "traffic unaware preference",
+			args: map[string]interface{}{
+				"departureTime":     future,
+				"routingPreference": "TRAFFIC_UNAWARE",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "invalid routing preference",
+			args:      map[string]interface{}{"routingPreference": "FASTEST"},
+			expectErr: true,
+		},
+		{
+			name:      "prefer shorter distance",
+			args:      map[string]interface{}{"preferLabel": "shorter_distance"},
+			expectErr: false,
+		},
+		{
+			name:      "invalid preferred label",
+			args:      map[string]interface{}{"preferLabel": "FASTEST"},
+			expectErr: true,
+		},
+		{
+			name:      "prefer alternate without alternatives",
+			args:      map[string]interface{}{"preferLabel": "DEFAULT_ROUTE_ALTERNATE"},
+			expectErr: true,
+		},
+		{
+			name:      "prefer alternate with alternatives",
+			args:      map[string]interface{}{"preferLabel": "DEFAULT_ROUTE_ALTERNATE", "computeAlternativeRoutes": true},
+			expectErr: false,
+		},
+		{
+			name:      "prefer shorter distance with distanceOnly",
+			args:      map[string]interface{}{"preferLabel": "SHORTER_DISTANCE", "distanceOnly": true},
+			expectErr: true,
+		},
+		{
+			name:      "prefer shorter distance when walking",
+			args:      map[string]interface{}{"preferLabel": "SHORTER_DISTANCE", "travelMode": "WALK"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+
+			_, err := handler.parseRouteOptions(request)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_parseRouteOptions_CaseInsensitive(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		travelMode string
+		preference string
+		units      string
+		expectErr  bool
+	}{
+		{
+			name:       "lowercase",
+			args:       map[string]interface{}{"travelMode": "drive", "routingPreference": "traffic_aware_optimal", "units": "imperial"},
+			travelMode: travelModeDrive,
+			preference: routingPreferenceTrafficAwareOptimal,
+			units:      unitsImperial,
+		},
+		{
+			name:       "mixed case with whitespace",
+			args:       map[string]interface{}{"travelMode": " Bicycle ", "units": "Metric"},
+			travelMode: travelModeBicycle,
+			preference: routingPreferenceTrafficAware,
+			units:      unitsMetric,
+		},
+		{
+			name:      "invalid travel mode",
+			args:      map[string]interface{}{"travelMode": "teleport"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid routing preference",
+			args:      map[string]interface{}{"routingPreference": "fastest"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid units",
+			args:      map[string]interface{}{"units": "furlongs"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+
+			opts, err := handler.parseRouteOptions(request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.TravelMode != tt.travelMode || opts.RoutingPreference != tt.preference || opts.Units != tt.units {
+				t.Errorf("expected %s/%s/%s, got %s/%s/%s", tt.travelMode, tt.preference, tt.units,
+					opts.TravelMode, opts.RoutingPreference, opts.Units)
+			}
+
+			body := handler.buildRequestBody(nil, nil, opts)
+			if body.TravelMode != tt.travelMode {
+				t.Errorf("expected request travel mode %s, got %s", tt.travelMode, body.TravelMode)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_parseRouteOptions_ArrivalTime(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expectErr bool
+	}{
+		{
+			name: "transit arrival",
+			args: map[string]interface{}{
+				"travelMode":  "TRANSIT",
+				"arrivalTime": future.Format(time.RFC3339),
+			},
+		},
+		{
+			name:      "drive arrival",
+			args:      map[string]interface{}{"arrivalTime": future.Format(time.RFC3339)},
+			expectErr: true,
+		},
+		{
+			name: "departure and arrival",
+			args: map[string]interface{}{
+				"travelMode":    "TRANSIT",
+				"departureTime": future.Format(time.RFC3339),
+				"arrivalTime":   future.Add(time.Hour).Format(time.RFC3339),
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+
+			opts, err := handler.parseRouteOptions(request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !opts.ArrivalTime.Equal(future) {
+				t.Errorf("expected arrival time %v, got %v", future, opts.ArrivalTime)
+			}
+
+			data, err := json.Marshal(handler.buildRequestBody(nil, nil, opts))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(data), `"arrivalTime":"`+future.Format(time.RFC3339)+`"`) {
+				t.Errorf("arrival time not serialized: %s", data)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_createRequest(t *testing.T) {
+	handler := &GeodistanceHandler{apiKey: "test-key"}
+	ctx := context.Background()
+
+	body := &RequestBody{
+		Origins:      []Origin{{Address: "New York"}},
+		Destinations: []Destination{{Address: "Los Angeles"}},
+		TravelMode:   "DRIVE",
+	}
+
+	req, err := handler.createRequest(ctx, body, fieldMask(fieldMaskFeatures{}))
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if req == nil {
+		t.Error("expected non-nil request")
+	}
+	if req.Method != "POST" {
+		t.Errorf("expected POST method, got %s", req.Method)
+	}
+	if req.Header.Get("Content-Type") != "application/json" {
+		t.Error("content type not set correctly")
+	}
+	if req.Header.Get("X-Goog-Api-Key") != "test-key" {
+		t.Error("API key header not set correctly")
+	}
+	if !strings.Contains(req.Header.Get("X-Goog-FieldMask"), "routes.description") {
+		t.Error("field mask should request the route description")
+	}
+	if !strings.Contains(req.Header.Get("X-Goog-FieldMask"), "routes.warnings") {
+		t.Error("field mask should request the route warnings")
+	}
+}
+
+func TestGeodistanceHandler_createRequest_UserAgent(t *testing.T) {
+	body := &RequestBody{TravelMode: "DRIVE"}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{name: "default", expected: "mcp-geodistance-server/" + Version},
+		{name: "override", opts: []Option{WithUserAgent("fleet-planner/2.1")}, expected: "fleet-planner/2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{}, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			req, err := handler.createRequest(context.Background(), body, fieldMask(fieldMaskFeatures{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := req.Header.Get("User-Agent"); got != tt.expected {
+				t.Errorf("expected User-Agent %q, got %q", tt.expected, got)
+			}
+			if tt.opts == nil && !strings.Contains(req.Header.Get("User-Agent"), Version) {
+				t.Error("default User-Agent should include the version")
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_processResponse(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expectErr  bool
+	}{
+		{
+			name:       "successful response",
+			statusCode: http.StatusOK,
+			body:       createValidAPIResponse(),
+			expectErr:  false,
+		},
+		{
+			name:       "API error",
+			statusCode: http.StatusBadRequest,
+			body:       `{"error": "Invalid request"}`,
+			expectErr:  true,
+		},
+		{
+			name:       "invalid JSON",
+			statusCode: http.StatusOK,
+			body:       "invalid json",
+			expectErr:  true,
+		},
+		{
+			name:       "no routes",
+			statusCode: http.StatusOK,
+			body:       `{"routes": []}`,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := createMockResponse(tt.statusCode, tt.body)
+
+			result, err := handler.processResponse(resp)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if result == nil {
+					t.Error("expected non-nil result")
+				}
+				if len(result.Routes) == 0 {
+					t.Error("expected routes in response")
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatResponse(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name         string
+		responseBody *ResponseBody
+		expectErr    bool
+	}{
+		{
+			name: "valid response",
+			responseBody: &ResponseBody{
+				Routes: []Route{
+					{
+						DistanceMeters: 1000,
+						Duration:       "5m",
+						RouteLabels:    []string{"DEFAULT_ROUTE"},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "empty routes",
+			responseBody: &ResponseBody{
+				Routes: []Route{},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.formatResponse(tt.responseBody, routeOptions{Units: unitsMetric})
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+			} else {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if result == nil {
+					t.Error("expected non-nil result")
+				}
+				if len(result.Content) == 0 {
+					t.Error("expected content in result")
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_validateAddressLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   *GeodistanceHandler
+		address   string
+		expectErr bool
+	}{
+		{
+			name:    "default limit",
+			handler: &GeodistanceHandler{},
+			address: strings.Repeat("a", defaultMaxAddressLength),
+		},
+		{
+			name:    "configured limit",
+			handler: &GeodistanceHandler{maxAddressLen: 10},
+			address: "Omaha, NE!",
+		},
+		{
+			name:      "over configured limit",
+			handler:   &GeodistanceHandler{maxAddressLen: 10},
+			address:   "Lincoln, NE",
+			expectErr: true,
+		},
+		{
+			name:    "non-positive limit keeps default",
+			handler: &GeodistanceHandler{maxAddressLen: -1},
+			address: strings.Repeat("a", defaultMaxAddressLength),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.handler.validateAddressLength("origin address", tt.address)
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatRoute_StaticDuration(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name     string
+		route    Route
+		opts     routeOptions
+		expected string
+		absent   string
+	}{
+		{
+			name:     "with static duration",
+			route:    Route{DistanceMeters: 1000, Duration: "1500s", StaticDuration: "1080s"},
+			opts:     routeOptions{Units: unitsMetric},
+			expected: "Duration: 25m0s (no traffic: 18m0s)",
+		},
+		{
+			name:   "without static duration",
+			route:  Route{DistanceMeters: 1000, Duration: "1500s"},
+			opts:   routeOptions{Units: unitsMetric},
+			absent: "no traffic",
+		},
+		{
+			name:   "static duration equal to duration",
+			route:  Route{DistanceMeters: 1000, Duration: "1080s", StaticDuration: "1080s"},
+			opts:   routeOptions{Units: unitsMetric},
+			absent: "no traffic",
+		},
+		{
+			name:   "distance only",
+			route:  Route{DistanceMeters: 1000, StaticDuration: "1080s"},
+			opts:   routeOptions{Units: unitsMetric, DistanceOnly: true},
+			absent: "no traffic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := handler.formatRoute(tt.route, tt.opts)
+			if tt.expected != "" && !strings.Contains(text, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, text)
+			}
+			if tt.absent != "" && strings.Contains(text, tt.absent) {
+				t.Errorf("expected no %q in %q", tt.absent, text)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatResponse_AlternativeRoutes(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	responseBody := &ResponseBody{
+		Routes: []Route{
+			{
+				DistanceMeters: 94475,
+				Duration:       "3288s",
+				RouteLabels:    []string{"DEFAULT_ROUTE"},
+			},
+			{
+				DistanceMeters: 87865,
+				Duration:       "4903s",
+				RouteLabels:    []string{"SHORTER_DISTANCE"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     routeOptions
+		expected []string
+	}{
+		{
+			name: "alternatives disabled",
+			opts: routeOptions{Units: unitsMetric},
+			expected: []string{
+				"Route distance: 94.47 km (94475 meters), Duration: 54m48s",
+				"Shorter distance route distance: 87.86 km (87865 meters), Duration: 1h21m43s",
+			},
+		},
+		{
+			name: "alternatives enabled",
+			opts: routeOptions{Units: unitsMetric, ComputeAlternativeRoutes: true},
+			expected: []string{
+				"Route 1 (DEFAULT_ROUTE) distance: 94.47 km (94475 meters), Duration: 54m48s",
+				"Route 2 (SHORTER_DISTANCE) distance: 87.86 km (87865 meters), Duration: 1h21m43s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.formatResponse(responseBody, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != len(tt.expected) {
+				t.Fatalf("expected %d content blocks, got %d", len(tt.expected), len(result.Content))
+			}
+			for i, expected := range tt.expected {
+				textContent, ok := result.Content[i].(mcp.TextContent)
+				if !ok {
+					t.Fatalf("expected text content at %d", i)
+				}
+				if textContent.Text != expected {
+					t.Errorf("expected text %q, got %q", expected, textContent.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestReferenceRoutes(t *testing.T) {
+	defaultRoute := Route{DistanceMeters: 94475, RouteLabels: []string{"DEFAULT_ROUTE"}}
+	shorterRoute := Route{DistanceMeters: 87865, RouteLabels: []string{"SHORTER_DISTANCE"}}
+	bothLabels := Route{DistanceMeters: 90000, RouteLabels: []string{"DEFAULT_ROUTE", "SHORTER_DISTANCE"}}
+	alternate := Route{DistanceMeters: 99000, RouteLabels: []string{"DEFAULT_ROUTE_ALTERNATE"}}
+
+	tests := []struct {
+		name     string
+		routes   []Route
+		expected []int
+	}{
+		{
+			name:     "default only",
+			routes:   []Route{defaultRoute},
+			expected: []int{94475},
+		},
+		{
+			name:     "default and shorter distance",
+			routes:   []Route{defaultRoute, shorterRoute},
+			expected: []int{94475, 87865},
+		},
+		{
+			name:     "shorter distance listed first",
+			routes:   []Route{shorterRoute, defaultRoute},
+			expected: []int{94475, 87865},
+		},
+		{
+			name:     "default route is also the shortest",
+			routes:   []Route{bothLabels},
+			expected: []int{90000},
+		},
+		{
+			name:     "alternates are skipped",
+			routes:   []Route{defaultRoute, alternate, shorterRoute},
+			expected: []int{94475, 87865},
+		},
+		{
+			name:     "labels missing falls back to index order",
+			routes:   []Route{{DistanceMeters: 1000}, {DistanceMeters: 900}, {DistanceMeters: 1100}},
+			expected: []int{1000, 900},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp := createMockResponse(tt.statusCode, tt.body)
-
-			result, err := handler.processResponse(resp)
-
-			if tt.expectErr {
-				if err == nil {
-					t.Error("expected error but got none")
-				}
-				if result != nil {
-					t.Error("expected nil result when error occurs")
-				}
-			} else {
-				if err != nil {
-					t.Errorf("unexpected error: %v", err)
-				}
-				if result == nil {
-					t.Error("expected non-nil result")
-				}
-				if len(result.Routes) == 0 {
-					t.Error("expected routes in response")
+			got := referenceRoutes(tt.routes)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d routes, got %d", len(tt.expected), len(got))
+			}
+			for i, meters := range tt.expected {
+				if got[i].DistanceMeters != meters {
+					t.Errorf("route %d: expected %d meters, got %d", i, meters, got[i].DistanceMeters)
 				}
 			}
 		})
 	}
 }
 
-func TestGeodistanceHandler_formatResponse(t *testing.T) {
+func TestGeodistanceHandler_formatResponse_PreferLabel(t *testing.T) {
 	handler := &GeodistanceHandler{}
+	responseBody := &ResponseBody{
+		Routes: []Route{
+			{DistanceMeters: 94475, Duration: "3288s", RouteLabels: []string{"DEFAULT_ROUTE"}},
+			{DistanceMeters: 99000, Duration: "3000s", RouteLabels: []string{"DEFAULT_ROUTE_ALTERNATE"}},
+			{DistanceMeters: 87865, Duration: "4903s", RouteLabels: []string{"SHORTER_DISTANCE"}},
+		},
+	}
 
 	tests := []struct {
-		name         string
-		responseBody *ResponseBody
-		expectErr    bool
+		name     string
+		opts     routeOptions
+		expected []string
 	}{
 		{
-			name: "valid response",
-			responseBody: &ResponseBody{
-				Routes: []Route{
-					{
-						DistanceMeters: 1000,
-						Duration:       "5m",
-						RouteLabels:    []string{"DEFAULT_ROUTE"},
-					},
-				},
+			name: "default route",
+			opts: routeOptions{Units: unitsMetric, PreferLabel: routeLabelDefault},
+			expected: []string{
+				"Route distance: 94.47 km (94475 meters), Duration: 54m48s",
+				"Shorter distance route distance: 87.86 km (87865 meters), Duration: 1h21m43s",
 			},
-			expectErr: false,
 		},
 		{
-			name: "empty routes",
-			responseBody: &ResponseBody{
-				Routes: []Route{},
+			name: "shorter distance",
+			opts: routeOptions{Units: unitsMetric, PreferLabel: routeLabelShorterDistance},
+			expected: []string{
+				"Route distance: 87.86 km (87865 meters), Duration: 1h21m43s",
+				"Default route distance: 94.47 km (94475 meters), Duration: 54m48s",
+			},
+		},
+		{
+			name: "alternate without alternatives falls back to the first route",
+			opts: routeOptions{Units: unitsMetric, PreferLabel: routeLabelDefaultAlternate},
+			expected: []string{
+				"Route distance: 94.47 km (94475 meters), Duration: 54m48s",
+				"Shorter distance route distance: 87.86 km (87865 meters), Duration: 1h21m43s",
+			},
+		},
+		{
+			name: "alternate with alternatives",
+			opts: routeOptions{Units: unitsMetric, PreferLabel: routeLabelDefaultAlternate, ComputeAlternativeRoutes: true},
+			expected: []string{
+				"Route 1 (DEFAULT_ROUTE_ALTERNATE) distance: 99.00 km (99000 meters), Duration: 50m0s",
+				"Route 2 (DEFAULT_ROUTE) distance: 94.47 km (94475 meters), Duration: 54m48s",
+				"Route 3 (SHORTER_DISTANCE) distance: 87.86 km (87865 meters), Duration: 1h21m43s",
 			},
-			expectErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := handler.formatResponse(tt.responseBody)
-
-			if tt.expectErr {
-				if err == nil {
-					t.Error("expected error but got none")
-				}
-				if result != nil {
-					t.Error("expected nil result when error occurs")
-				}
-			} else {
-				if err != nil {
-					t.Errorf("unexpected error: %v", err)
-				}
-				if result == nil {
-					t.Error("expected non-nil result")
-				}
-				if len(result.Content) == 0 {
-					t.Error("expected content in result")
+			result, err := handler.formatResponse(responseBody, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != len(tt.expected) {
+				t.Fatalf("expected %d content blocks, got %d", len(tt.expected), len(result.Content))
+			}
+			for i, expected := range tt.expected {
+				if text := result.Content[i].(mcp.TextContent).Text; text != expected {
+					t.Errorf("expected text %q, got %q", expected, text)
 				}
 			}
 		})
 	}
 }
 
+func TestPreferRoute(t *testing.T) {
+	defaultRoute := Route{DistanceMeters: 1, RouteLabels: []string{"DEFAULT_ROUTE"}}
+	shorterRoute := Route{DistanceMeters: 2, RouteLabels: []string{"SHORTER_DISTANCE"}}
+	unlabeled := Route{DistanceMeters: 3}
+
+	tests := []struct {
+		name     string
+		routes   []Route
+		label    string
+		expected []int
+	}{
+		{name: "no preference", routes: []Route{defaultRoute, shorterRoute}, expected: []int{1, 2}},
+		{name: "already first", routes: []Route{defaultRoute, shorterRoute}, label: routeLabelDefault, expected: []int{1, 2}},
+		{name: "moved to front", routes: []Route{defaultRoute, unlabeled, shorterRoute}, label: routeLabelShorterDistance, expected: []int{2, 1, 3}},
+		{name: "label missing", routes: []Route{unlabeled, defaultRoute}, label: routeLabelShorterDistance, expected: []int{3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := preferRoute(tt.routes, tt.label)
+			distances := make([]int, len(got))
+			for i, route := range got {
+				distances[i] = route.DistanceMeters
+			}
+			if !slices.Equal(distances, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, distances)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_buildRequestBody_AlternativeRoutes(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	body := handler.buildRequestBody(nil, nil, routeOptions{ComputeAlternativeRoutes: true})
+	if !body.ComputeAlternativeRoutes {
+		t.Error("computeAlternativeRoutes not set on request body")
+	}
+
+	data, err := json.Marshal(handler.buildRequestBody(nil, nil, routeOptions{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "computeAlternativeRoutes") {
+		t.Error("computeAlternativeRoutes should be omitted when not requested")
+	}
+}
+
 func TestGeodistanceHandler_callDistanceMatrix(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -379,7 +1745,7 @@ func TestGeodistanceHandler_callDistanceMatrix(t *testing.T) {
 			origins := []Origin{{Address: "New York"}}
 			destinations := []Destination{{Address: "Los Angeles"}}
 
-			result, err := handler.callDistanceMatrix(context.Background(), origins, destinations)
+			result, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{})
 
 			if tt.expectErr {
 				if err == nil {
@@ -400,6 +1766,56 @@ func TestGeodistanceHandler_callDistanceMatrix(t *testing.T) {
 	}
 }
 
+func TestGeodistanceHandler_callDistanceMatrix_ContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name   string
+		doFunc func(req *http.Request) (*http.Response, error)
+	}{
+		{
+			name: "client honors context",
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			},
+		},
+		{
+			name: "client ignores context",
+			doFunc: func(req *http.Request) (*http.Response, error) {
+				<-release
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: tt.doFunc},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			result, err := handler.callDistanceMatrix(ctx, []Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+			if result != nil {
+				t.Error("expected nil result when the deadline fires")
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("expected call to return at the deadline, took %s", elapsed)
+			}
+		})
+	}
+}
+
 func TestGeodistanceHandler_handleDistanceCalculation(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -418,7 +1834,7 @@ func TestGeodistanceHandler_handleDistanceCalculation(t *testing.T) {
 				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
 			},
 			expectErr:    false,
-			expectedText: "Route distance: 1000 meters, Duration: 5m",
+			expectedText: "Route distance: 1.00 km (1000 meters), Duration: 5m0s",
 		},
 		{
 			name: "missing origin address",
@@ -445,6 +1861,124 @@ func TestGeodistanceHandler_handleDistanceCalculation(t *testing.T) {
 			mockFunc:  nil,
 			expectErr: true,
 		},
+		{
+			name: "imperial units",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+				"units":              "IMPERIAL",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 0.62 miles (1000 meters), Duration: 5m0s",
+		},
+		{
+			name: "avoid flags reach request body",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+				"avoidTolls":         true,
+				"avoidFerries":       true,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				var body RequestBody
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					return nil, err
+				}
+				if body.RouteModifiers == nil || !body.RouteModifiers.AvoidTolls || !body.RouteModifiers.AvoidFerries || body.RouteModifiers.AvoidHighways {
+					return nil, fmt.Errorf("unexpected route modifiers: %+v", body.RouteModifiers)
+				}
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 1.00 km (1000 meters), Duration: 5m0s",
+		},
+		{
+			name: "route summary",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "San Francisco",
+				"destinationAddress": "San Jose",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":77000,"duration":"3000s","description":"I-280 S"}]}`), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 77.00 km (77000 meters), Duration: 50m0s, Via: I-280 S",
+		},
+		{
+			name: "route warnings",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "San Francisco",
+				"destinationAddress": "San Jose",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":77000,"duration":"3000s","warnings":["This route has restricted usage roads.","This route includes a ferry."]}]}`), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 77.00 km (77000 meters), Duration: 50m0s, Warnings: This route has restricted usage roads.; This route includes a ferry.",
+		},
+		{
+			name: "hours minutes duration format",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+				"durationFormat":     "HOURS_MINUTES",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 1.00 km (1000 meters), Duration: 0:05",
+		},
+		{
+			name: "invalid duration format",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+				"durationFormat":     "FORTNIGHTS",
+			},
+			mockFunc:  nil,
+			expectErr: true,
+		},
+		{
+			name: "traffic freshness for traffic aware routing",
+			requestArgs: map[string]interface{}{
+				"originAddress":           "New York",
+				"destinationAddress":      "Los Angeles",
+				"includeTrafficFreshness": true,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 1.00 km (1000 meters), Duration: 5m0s, Fresh traffic data: yes (live traffic)",
+		},
+		{
+			name: "traffic freshness for traffic unaware routing",
+			requestArgs: map[string]interface{}{
+				"originAddress":           "New York",
+				"destinationAddress":      "Los Angeles",
+				"routingPreference":       "TRAFFIC_UNAWARE",
+				"includeTrafficFreshness": true,
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectErr:    false,
+			expectedText: "Route distance: 1.00 km (1000 meters), Duration: 5m0s, Fresh traffic data: no (traffic-unaware routing)",
+		},
+		{
+			name: "invalid units",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+				"units":              "FURLONGS",
+			},
+			mockFunc:  nil,
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -499,3 +2033,119 @@ func TestGeodistanceHandler_handleDistanceCalculation(t *testing.T) {
 		})
 	}
 }
+
+func TestGeodistanceHandler_handleDistanceCalculation_StrictZeroDistance(t *testing.T) {
+	zeroResponse := `{"routes":[{"distanceMeters":0,"duration":"0s","routeLabels":["DEFAULT_ROUTE"]}]}`
+
+	tests := []struct {
+		name        string
+		strict      bool
+		args        map[string]interface{}
+		identical   IdenticalAddressBehavior
+		expectErr   bool
+		expectedOut string
+	}{
+		{
+			name:        "strict off reports zero",
+			args:        map[string]interface{}{"originAddress": "Springfield", "destinationAddress": "Shelbyville"},
+			expectedOut: "Route distance: 0.00 km (0 meters)",
+		},
+		{
+			name:      "strict on rejects zero between different addresses",
+			strict:    true,
+			args:      map[string]interface{}{"originAddress": "Springfield", "destinationAddress": "Shelbyville"},
+			expectErr: true,
+		},
+		{
+			name:        "strict on keeps the identical address answer",
+			strict:      true,
+			args:        map[string]interface{}{"originAddress": "Springfield", "destinationAddress": "springfield"},
+			identical:   IdenticalAddressesZeroDistance,
+			expectedOut: "Route distance: 0.00 km (0 meters)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(http.StatusOK, zeroResponse), nil
+				}},
+				identicalAddresses: tt.identical,
+			}
+			WithStrictZeroDistance(tt.strict)(handler)
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+
+			if tt.expectErr {
+				if !errors.Is(err, ErrSuspiciousZeroDistance) {
+					t.Fatalf("expected ErrSuspiciousZeroDistance, got %v", err)
+				}
+				if CategoryOf(err) != CategoryUpstream {
+					t.Errorf("expected an upstream error, got %s", CategoryOf(err))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; !strings.HasPrefix(text, tt.expectedOut) {
+				t.Errorf("expected %q, got %q", tt.expectedOut, text)
+			}
+		})
+	}
+}
+
+func TestCheckZeroDistance(t *testing.T) {
+	zero := &ResponseBody{Routes: []Route{{DistanceMeters: 0}}}
+
+	tests := []struct {
+		name      string
+		body      *RequestBody
+		response  *ResponseBody
+		expectErr bool
+	}{
+		{
+			name:      "different addresses",
+			body:      &RequestBody{Origins: []Origin{{Address: "A"}}, Destinations: []Destination{{Address: "B"}}},
+			response:  zero,
+			expectErr: true,
+		},
+		{
+			name:     "same place spelled differently",
+			body:     &RequestBody{Origins: []Origin{{Address: "Main St"}}, Destinations: []Destination{{Address: "  main   st "}}},
+			response: zero,
+		},
+		{
+			name: "loop through a different waypoint",
+			body: &RequestBody{
+				Origins:       []Origin{{Address: "A"}},
+				Intermediates: []Waypoint{{Address: "B"}},
+				Destinations:  []Destination{{Address: "A"}},
+			},
+			response:  zero,
+			expectErr: true,
+		},
+		{
+			name:     "nonzero distance",
+			body:     &RequestBody{Origins: []Origin{{Address: "A"}}, Destinations: []Destination{{Address: "B"}}},
+			response: &ResponseBody{Routes: []Route{{DistanceMeters: 10}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkZeroDistance(tt.body, tt.response)
+			if tt.expectErr && !errors.Is(err, ErrSuspiciousZeroDistance) {
+				t.Errorf("expected ErrSuspiciousZeroDistance, got %v", err)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
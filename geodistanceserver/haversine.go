@@ -0,0 +1,63 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// earthRadiusMeters is the IUGG mean radius of the Earth.
+const earthRadiusMeters = 6371008.8
+
+// haversineMeters returns the great-circle distance between two points
+// given in decimal degrees, treating the Earth as a sphere. The result is
+// within about 0.5% of the true ellipsoidal distance.
+func haversineMeters(from, to LatLng) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	// Rounding can push a just past 1 for antipodal points
+	a = math.Min(a, 1)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// handleHaversineDistance computes the straight-line distance between two
+// coordinate pairs locally, without calling any API.
+func (gh *GeodistanceHandler) handleHaversineDistance(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	args, err := requireFloatArguments(request, toolHaversineDistance,
+		"originLatitude", "originLongitude", "destinationLatitude", "destinationLongitude")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	origin := LatLng{Latitude: args[0], Longitude: args[1]}
+	destination := LatLng{Latitude: args[2], Longitude: args[3]}
+
+	if err := validateCoordinates(origin.Latitude, origin.Longitude); err != nil {
+		return nil, invalidArgument(fmt.Errorf("origin: %w", err))
+	}
+	if err := validateCoordinates(destination.Latitude, destination.Longitude); err != nil {
+		return nil, invalidArgument(fmt.Errorf("destination: %w", err))
+	}
+	units := enumArgument(request, "units", unitsMetric)
+	if err := validateUnits(units); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	meters := int(math.Round(haversineMeters(origin, destination)))
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: fmt.Sprintf("Great-circle distance: %s", formatDistance(meters, units, "")),
+			},
+		},
+	}, nil
+}
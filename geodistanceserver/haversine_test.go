@@ -0,0 +1,164 @@
+package geodistanceserver
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHaversineMeters(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLng
+		expected float64
+		// tolerance is a fraction of expected, or meters when expected is 0
+		tolerance float64
+	}{
+		{
+			name:      "london to paris",
+			from:      LatLng{Latitude: 51.5074, Longitude: -0.1278},
+			to:        LatLng{Latitude: 48.8566, Longitude: 2.3522},
+			expected:  343_500,
+			tolerance: 0.01,
+		},
+		{
+			name:      "new york to los angeles",
+			from:      LatLng{Latitude: 40.7128, Longitude: -74.0060},
+			to:        LatLng{Latitude: 34.0522, Longitude: -118.2437},
+			expected:  3_936_000,
+			tolerance: 0.01,
+		},
+		{
+			name:      "sydney to tokyo across the equator",
+			from:      LatLng{Latitude: -33.8688, Longitude: 151.2093},
+			to:        LatLng{Latitude: 35.6762, Longitude: 139.6503},
+			expected:  7_826_000,
+			tolerance: 0.01,
+		},
+		{
+			name:      "across the antimeridian",
+			from:      LatLng{Latitude: 0, Longitude: 179.5},
+			to:        LatLng{Latitude: 0, Longitude: -179.5},
+			expected:  math.Pi * earthRadiusMeters / 180,
+			tolerance: 1e-9,
+		},
+		{
+			name:      "antipodal points",
+			from:      LatLng{Latitude: 0, Longitude: 0},
+			to:        LatLng{Latitude: 0, Longitude: 180},
+			expected:  math.Pi * earthRadiusMeters,
+			tolerance: 1e-9,
+		},
+		{
+			name:      "pole to pole",
+			from:      LatLng{Latitude: 90, Longitude: 0},
+			to:        LatLng{Latitude: -90, Longitude: 0},
+			expected:  math.Pi * earthRadiusMeters,
+			tolerance: 1e-9,
+		},
+		{
+			name:      "identical points",
+			from:      LatLng{Latitude: 37.4220, Longitude: -122.0841},
+			to:        LatLng{Latitude: 37.4220, Longitude: -122.0841},
+			expected:  0,
+			tolerance: 1e-6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := haversineMeters(tt.from, tt.to)
+			allowed := tt.tolerance * tt.expected
+			if tt.expected == 0 {
+				allowed = tt.tolerance
+			}
+			if math.Abs(got-tt.expected) > allowed {
+				t.Errorf("expected %.1f m within %v, got %.1f m", tt.expected, allowed, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleHaversineDistance(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  string
+		expectErr string
+	}{
+		{
+			name: "metric",
+			args: map[string]interface{}{
+				"originLatitude": 51.5074, "originLongitude": -0.1278,
+				"destinationLatitude": 48.8566, "destinationLongitude": 2.3522,
+			},
+			expected: "Great-circle distance: 343.56 km (343557 meters)",
+		},
+		{
+			name: "imperial",
+			args: map[string]interface{}{
+				"originLatitude": 51.5074, "originLongitude": -0.1278,
+				"destinationLatitude": 48.8566, "destinationLongitude": 2.3522,
+				"units": unitsImperial,
+			},
+			expected: "Great-circle distance: 213.48 miles (343557 meters)",
+		},
+		{
+			name: "nautical",
+			args: map[string]interface{}{
+				"originLatitude": 51.5074, "originLongitude": -0.1278,
+				"destinationLatitude": 48.8566, "destinationLongitude": 2.3522,
+				"units": unitsNautical,
+			},
+			expected: "Great-circle distance: 185.51 nmi (343557 meters)",
+		},
+		{
+			name: "identical points",
+			args: map[string]interface{}{
+				"originLatitude": 10.0, "originLongitude": 20.0,
+				"destinationLatitude": 10.0, "destinationLongitude": 20.0,
+			},
+			expected: "Great-circle distance: 0.00 km (0 meters)",
+		},
+		{
+			name:      "missing coordinates",
+			args:      map[string]interface{}{"originLatitude": 10.0, "originLongitude": 20.0},
+			expectErr: "missing required arguments",
+		},
+		{
+			name: "latitude out of range",
+			args: map[string]interface{}{
+				"originLatitude": 91.0, "originLongitude": 20.0,
+				"destinationLatitude": 10.0, "destinationLongitude": 20.0,
+			},
+			expectErr: "origin: invalid latitude",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No API key or client: the tool never leaves the process
+			handler := &GeodistanceHandler{}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolHaversineDistance, Arguments: tt.args},
+			}
+
+			result, err := handler.handleHaversineDistance(context.Background(), request)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const defaultLanguageCode = "en-US"
+
+// languageCodePattern loosely matches a BCP-47 tag: a 2-3 letter primary
+// language followed by optional subtags such as a script or region.
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+func validateLanguageCode(code string) error {
+	if !languageCodePattern.MatchString(code) {
+		return fmt.Errorf("invalid languageCode %q: must be a BCP-47 language tag such as en-US", code)
+	}
+	return nil
+}
+
+func languageCodeOrDefault(code string) string {
+	if code == "" {
+		return defaultLanguageCode
+	}
+	return code
+}
+
+// regionCodePattern matches a two-letter ccTLD or CLDR region code such as
+// "us" or "de".
+var regionCodePattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// parseRegionCode validates an optional region code, which biases how the
+// Routes API geocodes ambiguous addresses, and lowercases it.
+func parseRegionCode(code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+	if !regionCodePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid regionCode %q: must be a two-letter region code such as us", code)
+	}
+	return strings.ToLower(code), nil
+}
@@ -0,0 +1,105 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateLanguageCode(t *testing.T) {
+	tests := []struct {
+		code      string
+		expectErr bool
+	}{
+		{code: "en-US", expectErr: false},
+		{code: "fr-FR", expectErr: false},
+		{code: "de", expectErr: false},
+		{code: "zh-Hant-TW", expectErr: false},
+		{code: "", expectErr: true},
+		{code: "english", expectErr: true},
+		{code: "en_US", expectErr: true},
+		{code: "en-", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := validateLanguageCode(tt.code)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRegionCode(t *testing.T) {
+	tests := []struct {
+		code      string
+		expected  string
+		expectErr bool
+	}{
+		{code: "", expected: ""},
+		{code: "us", expected: "us"},
+		{code: "DE", expected: "de"},
+		{code: "usa", expectErr: true},
+		{code: "u", expectErr: true},
+		{code: "u1", expectErr: true},
+		{code: "en-US", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			code, err := parseRegionCode(tt.code)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestRegionCodeSerialization(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	opts := routeOptions{RegionCode: "us"}
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{name: "routes", body: handler.buildRequestBody(nil, nil, opts)},
+		{name: "route matrix", body: handler.buildMatrixRequestBody([]string{"Springfield"}, []string{"Chicago"}, opts)},
+		{name: "compute routes", body: newComputeRoutesBody(handler.buildRequestBody([]Origin{{Address: "A"}}, []Destination{{Address: "B"}}, opts))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(data), `"regionCode":"us"`) {
+				t.Errorf("region code not serialized: %s", data)
+			}
+		})
+	}
+
+	data, err := json.Marshal(handler.buildRequestBody(nil, nil, routeOptions{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "regionCode") {
+		t.Errorf("regionCode should be omitted when not set: %s", data)
+	}
+}
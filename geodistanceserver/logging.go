@@ -0,0 +1,52 @@
+package geodistanceserver
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// logAPICall records one outgoing API call. It is a no-op without a
+// configured logger. Only the scheme, host, and path of the URL are logged
+// so credentials never reach the logs.
+func (gh *GeodistanceHandler) logAPICall(
+	ctx context.Context,
+	req *http.Request,
+	travelMode string,
+	statusCode int,
+	latency time.Duration,
+	err error,
+	resultAttrs ...slog.Attr,
+) {
+	if gh.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("url", redactURL(req.URL)),
+		slog.String("travelMode", travelMode),
+		slog.Duration("latency", latency),
+	}
+	if statusCode != 0 {
+		attrs = append(attrs, slog.Int("statusCode", statusCode))
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("requestId", id))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		gh.logger.LogAttrs(ctx, slog.LevelError, "routes API call failed", attrs...)
+		return
+	}
+
+	attrs = append(attrs, resultAttrs...)
+	gh.logger.LogAttrs(ctx, slog.LevelInfo, "routes API call completed", attrs...)
+}
+
+func redactURL(u *url.URL) string {
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+	return redacted.String()
+}
@@ -0,0 +1,112 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGeodistanceHandler_callDistanceMatrix_Logging(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockFunc       func(req *http.Request) (*http.Response, error)
+		expectErr      bool
+		expectedFields map[string]interface{}
+	}{
+		{
+			name: "successful request",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectedFields: map[string]interface{}{
+				"msg":            "routes API call completed",
+				"url":            defaultBaseURL,
+				"travelMode":     "DRIVE",
+				"statusCode":     float64(http.StatusOK),
+				"distanceMeters": float64(1000),
+			},
+		},
+		{
+			name: "API error response",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusBadRequest, `{"error": "Invalid request"}`), nil
+			},
+			expectErr: true,
+			expectedFields: map[string]interface{}{
+				"msg":        "routes API call failed",
+				"statusCode": float64(http.StatusBadRequest),
+			},
+		},
+		{
+			name: "network error",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("network error")
+			},
+			expectErr: true,
+			expectedFields: map[string]interface{}{
+				"msg":   "routes API call failed",
+				"error": "failed to execute request: network error",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler := &GeodistanceHandler{
+				apiKey: "secret-api-key",
+				client: &MockHTTPClient{DoFunc: tt.mockFunc},
+				logger: slog.New(slog.NewJSONHandler(&buf, nil)),
+			}
+
+			_, err := handler.callDistanceMatrix(context.Background(),
+				[]Origin{{Address: "New York"}}, []Destination{{Address: "Los Angeles"}}, routeOptions{})
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+
+			if strings.Contains(buf.String(), "secret-api-key") {
+				t.Fatal("API key must never be logged")
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+			}
+			for key, expected := range tt.expectedFields {
+				if entry[key] != expected {
+					t.Errorf("expected %s=%v, got %v", key, expected, entry[key])
+				}
+			}
+			if _, ok := entry["latency"]; !ok {
+				t.Error("expected latency field")
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_callDistanceMatrix_NoLogger(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+
+	if _, err := handler.callDistanceMatrix(context.Background(),
+		[]Origin{{Address: "New York"}}, []Destination{{Address: "Los Angeles"}}, routeOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://maps.googleapis.com/maps/api/geocode/json?address=x&key=secret", nil)
+	if got := redactURL(req.URL); got != "https://maps.googleapis.com/maps/api/geocode/json" {
+		t.Errorf("unexpected redacted URL: %s", got)
+	}
+}
@@ -0,0 +1,309 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMapboxMatrixURL  = "https://api.mapbox.com/directions-matrix/v1/mapbox"
+	defaultMapboxGeocodeURL = "https://api.mapbox.com/geocoding/v5/mapbox.places"
+)
+
+const (
+	providerGoogle = "google"
+	providerMapbox = "mapbox"
+)
+
+// MapboxProvider computes routes with the Mapbox Matrix API, geocoding
+// address endpoints with the Mapbox Geocoding API first. Only distance and
+// duration are reported: waypoints, transit, alternative routes, polylines
+// and toll estimates are not available. Avoiding tolls, highways and
+// ferries maps to Mapbox exclusions; other route modifiers, departure or
+// arrival times, extra computations, alternative routes and polyline or
+// travel advisory fields are rejected.
+type MapboxProvider struct {
+	accessToken string
+	client      HTTPClient
+	matrixURL   string
+	geocodeURL  string
+	// gh is the handler the provider was configured on; its rate limit,
+	// throttle retries and response size limit apply to Mapbox requests.
+	gh *GeodistanceHandler
+}
+
+// NewMapboxProvider creates a provider that authenticates with accessToken
+// and sends requests through client. Once configured on a handler, with
+// WithProvider, WithFallbackProvider or GEODISTANCE_PROVIDER, it shares
+// that handler's rate limit, throttle retries and response size limit.
+func NewMapboxProvider(accessToken string, client HTTPClient) *MapboxProvider {
+	return &MapboxProvider{
+		accessToken: accessToken,
+		client:      client,
+		matrixURL:   defaultMapboxMatrixURL,
+		geocodeURL:  defaultMapboxGeocodeURL,
+	}
+}
+
+type mapboxMatrixResponse struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message,omitempty"`
+	Distances [][]*float64 `json:"distances"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+type mapboxGeocodeResponse struct {
+	Features []struct {
+		Center []float64 `json:"center"`
+	} `json:"features"`
+}
+
+// ComputeRoutes routes the single origin to the single destination of body.
+// An unreachable destination yields a response without routes.
+func (p *MapboxProvider) ComputeRoutes(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error) {
+	if len(body.Intermediates) > 0 {
+		return nil, fmt.Errorf("mapbox provider does not support waypoints")
+	}
+	if len(body.Origins) != 1 || len(body.Destinations) != 1 {
+		return nil, fmt.Errorf("mapbox provider routes exactly one origin and one destination")
+	}
+	profile, err := mapboxProfile(body.TravelMode, body.RoutingPreference)
+	if err != nil {
+		return nil, err
+	}
+	if body.DepartureTime != "" || body.ArrivalTime != "" {
+		return nil, fmt.Errorf("mapbox provider does not support departureTime or arrivalTime")
+	}
+	if len(body.ExtraComputations) > 0 {
+		return nil, fmt.Errorf("mapbox provider does not support extraComputations")
+	}
+	if body.ComputeAlternativeRoutes {
+		return nil, fmt.Errorf("mapbox provider does not support computeAlternativeRoutes")
+	}
+	if field := mapboxUnsupportedField(fieldMask); field != "" {
+		return nil, fmt.Errorf("mapbox provider does not support %s", field)
+	}
+	exclude, err := mapboxExclusions(body.RouteModifiers, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	origin, err := p.coordinates(ctx, body.Origins[0].Address, body.Origins[0].Location)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := p.coordinates(ctx, body.Destinations[0].Address, body.Destinations[0].Location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"access_token": {p.accessToken},
+		"annotations":  {"distance,duration"},
+		"sources":      {"0"},
+		"destinations": {"1"},
+	}
+	if exclude != "" {
+		query.Set("exclude", exclude)
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s;%s?%s", p.matrixURL, profile, origin, destination, query.Encode())
+
+	data, err := p.get(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var matrix mapboxMatrixResponse
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mapbox response: %w", err)
+	}
+	if matrix.Code != "Ok" {
+		return nil, fmt.Errorf("mapbox matrix request failed: %s: %s", matrix.Code, matrix.Message)
+	}
+
+	responseBody := &ResponseBody{raw: data}
+	distance := matrixCell(matrix.Distances)
+	if distance == nil {
+		return responseBody, nil
+	}
+
+	route := Route{
+		DistanceMeters: int(math.Round(*distance)),
+		RouteLabels:    []string{routeLabelDefault},
+	}
+	if duration := matrixCell(matrix.Durations); duration != nil {
+		route.Duration = strconv.FormatFloat(*duration, 'f', -1, 64) + "s"
+	}
+	responseBody.Routes = []Route{route}
+	return responseBody, nil
+}
+
+// matrixCell returns the single cell of a one-by-one Mapbox annotation,
+// or nil when the API found no route.
+func matrixCell(rows [][]*float64) *float64 {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil
+	}
+	return rows[0][0]
+}
+
+// mapboxProfile maps a Routes API travel mode to a Mapbox routing profile.
+// Traffic-aware driving uses live traffic.
+func mapboxProfile(travelMode, routingPreference string) (string, error) {
+	switch travelModeOrDefault(travelMode) {
+	case travelModeDrive:
+		if routingPreference == routingPreferenceTrafficUnaware {
+			return "driving", nil
+		}
+		return "driving-traffic", nil
+	case travelModeBicycle:
+		return "cycling", nil
+	case travelModeWalk:
+		return "walking", nil
+	default:
+		return "", fmt.Errorf("mapbox provider does not support travel mode %s", travelMode)
+	}
+}
+
+// mapboxUnsupportedField returns the first field of fieldMask the Matrix
+// API cannot report, or "" when every field is available.
+func mapboxUnsupportedField(fieldMask string) string {
+	for _, field := range strings.Split(fieldMask, ",") {
+		if strings.HasPrefix(field, "routes.polyline") || strings.HasPrefix(field, "routes.travelAdvisory") {
+			return field
+		}
+	}
+	return ""
+}
+
+// mapboxExclusions maps avoid modifiers to the exclude classes of a Mapbox
+// profile. Modifiers Mapbox cannot honour are rejected rather than
+// silently ignored: only driving profiles can exclude tolls and motorways,
+// and indoor routes and vehicle emission types have no equivalent.
+func mapboxExclusions(modifiers *RouteModifiers, profile string) (string, error) {
+	if modifiers == nil {
+		return "", nil
+	}
+	if modifiers.AvoidIndoor {
+		return "", fmt.Errorf("mapbox provider does not support avoidIndoor")
+	}
+	if modifiers.VehicleInfo != nil {
+		return "", fmt.Errorf("mapbox provider does not support emissionType")
+	}
+
+	driving := profile == "driving" || profile == "driving-traffic"
+	var exclude []string
+	if modifiers.AvoidTolls {
+		if !driving {
+			return "", fmt.Errorf("mapbox provider cannot avoid tolls for profile %s", profile)
+		}
+		exclude = append(exclude, "toll")
+	}
+	if modifiers.AvoidHighways {
+		if !driving {
+			return "", fmt.Errorf("mapbox provider cannot avoid highways for profile %s", profile)
+		}
+		exclude = append(exclude, "motorway")
+	}
+	if modifiers.AvoidFerries {
+		exclude = append(exclude, "ferry")
+	}
+	return strings.Join(exclude, ","), nil
+}
+
+// coordinates returns the "longitude,latitude" pair Mapbox expects,
+// geocoding address when no location is given.
+func (p *MapboxProvider) coordinates(ctx context.Context, address string, location *Location) (string, error) {
+	if location != nil {
+		return formatLngLat(location.LatLng.Longitude, location.LatLng.Latitude), nil
+	}
+
+	query := url.Values{"access_token": {p.accessToken}, "limit": {"1"}}
+	endpoint := fmt.Sprintf("%s/%s.json?%s", p.geocodeURL, url.PathEscape(address), query.Encode())
+	data, err := p.get(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	var geocode mapboxGeocodeResponse
+	if err := json.Unmarshal(data, &geocode); err != nil {
+		return "", fmt.Errorf("failed to unmarshal mapbox geocoding response: %w", err)
+	}
+	if len(geocode.Features) == 0 || len(geocode.Features[0].Center) != 2 {
+		return "", fmt.Errorf("mapbox could not geocode address %q", address)
+	}
+	center := geocode.Features[0].Center
+	return formatLngLat(center[0], center[1]), nil
+}
+
+func formatLngLat(lng, lat float64) string {
+	return strconv.FormatFloat(lng, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+}
+
+// get fetches endpoint and returns the body of a 200 response. Errors never
+// include the URL, since it carries the access token.
+func (p *MapboxProvider) get(ctx context.Context, endpoint string) ([]byte, error) {
+	gh := p.handler()
+	if err := gh.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.New("failed to create mapbox request")
+	}
+	req.Header.Set("User-Agent", gh.userAgentOrDefault())
+
+	resp, err := gh.doWith(ctx, p.client, req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to execute mapbox request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Body: string(gh.readErrorBody(resp.Body))}
+	}
+	data, err := gh.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapbox response: %w", err)
+	}
+	return data, nil
+}
+
+// handler returns the handler the provider was configured on, or a
+// zero-value one with the default limits when it is used on its own.
+func (p *MapboxProvider) handler() *GeodistanceHandler {
+	if p.gh == nil {
+		return &GeodistanceHandler{}
+	}
+	return p.gh
+}
+
+// providerFromEnvironment builds the primary provider selected by
+// GEODISTANCE_PROVIDER. It returns nil for the default Google Routes API.
+func providerFromEnvironment(client HTTPClient) (Provider, error) {
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv("GEODISTANCE_PROVIDER"))); name {
+	case "", providerGoogle:
+		return nil, nil
+	case providerMapbox:
+		token := os.Getenv("MAPBOX_ACCESS_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("GEODISTANCE_PROVIDER is %s but MAPBOX_ACCESS_TOKEN is not set", providerMapbox)
+		}
+		return NewMapboxProvider(token, client), nil
+	default:
+		return nil, fmt.Errorf("invalid GEODISTANCE_PROVIDER %q: must be %s or %s", name, providerGoogle, providerMapbox)
+	}
+}
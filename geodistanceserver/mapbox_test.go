@@ -0,0 +1,464 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const mapboxMatrixResponseBody = `{"code":"Ok","distances":[[94475.4]],"durations":[[3288.2]],` +
+	`"sources":[{"location":[-95.93,41.25]}],"destinations":[{"location":[-96.68,40.81]}]}`
+
+// mapboxDoFunc serves the Mapbox geocoding and matrix endpoints, recording
+// the paths requested.
+func mapboxDoFunc(paths *[]string, matrixStatus int, matrixBody string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		*paths = append(*paths, req.URL.Path)
+		if req.URL.Query().Get("access_token") != "mapbox-token" {
+			return createMockResponse(http.StatusUnauthorized, `{"message":"Not Authorized - Invalid Token"}`), nil
+		}
+		if strings.HasPrefix(req.URL.Path, "/geocoding/") {
+			if strings.Contains(req.URL.Path, "Nowhere") {
+				return createMockResponse(http.StatusOK, `{"type":"FeatureCollection","features":[]}`), nil
+			}
+			center := "[-95.93,41.25]"
+			if strings.Contains(req.URL.Path, "Lincoln") {
+				center = "[-96.68,40.81]"
+			}
+			return createMockResponse(http.StatusOK, `{"type":"FeatureCollection","features":[{"center":`+center+`}]}`), nil
+		}
+		return createMockResponse(matrixStatus, matrixBody), nil
+	}
+}
+
+func newTestMapboxProvider(doFunc func(req *http.Request) (*http.Response, error)) *MapboxProvider {
+	provider := NewMapboxProvider("mapbox-token", &MockHTTPClient{DoFunc: doFunc})
+	provider.matrixURL = "https://mapbox.test/directions-matrix/v1/mapbox"
+	provider.geocodeURL = "https://mapbox.test/geocoding/v5/mapbox.places"
+	return provider
+}
+
+func TestMapboxProvider_ComputeRoutes(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           *RequestBody
+		matrixStatus   int
+		matrixBody     string
+		expectErr      string
+		expectNoRoutes bool
+		expectedRoute  Route
+		expectedPath   string
+	}{
+		{
+			name: "geocodes addresses",
+			body: &RequestBody{
+				Origins:           []Origin{{Address: "Omaha, NE"}},
+				Destinations:      []Destination{{Address: "Lincoln, NE"}},
+				TravelMode:        travelModeDrive,
+				RoutingPreference: routingPreferenceTrafficAware,
+			},
+			matrixStatus:  http.StatusOK,
+			matrixBody:    mapboxMatrixResponseBody,
+			expectedRoute: Route{DistanceMeters: 94475, Duration: "3288.2s", RouteLabels: []string{routeLabelDefault}},
+			expectedPath:  "/directions-matrix/v1/mapbox/driving-traffic/-95.93,41.25;-96.68,40.81",
+		},
+		{
+			name: "coordinates skip geocoding",
+			body: &RequestBody{
+				Origins:           []Origin{{Location: &Location{LatLng: LatLng{Latitude: 41.25, Longitude: -95.93}}}},
+				Destinations:      []Destination{{Location: &Location{LatLng: LatLng{Latitude: 40.81, Longitude: -96.68}}}},
+				TravelMode:        travelModeDrive,
+				RoutingPreference: routingPreferenceTrafficUnaware,
+			},
+			matrixStatus:  http.StatusOK,
+			matrixBody:    mapboxMatrixResponseBody,
+			expectedRoute: Route{DistanceMeters: 94475, Duration: "3288.2s", RouteLabels: []string{routeLabelDefault}},
+			expectedPath:  "/directions-matrix/v1/mapbox/driving/-95.93,41.25;-96.68,40.81",
+		},
+		{
+			name: "walking profile",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+				TravelMode:   travelModeWalk,
+			},
+			matrixStatus:  http.StatusOK,
+			matrixBody:    mapboxMatrixResponseBody,
+			expectedRoute: Route{DistanceMeters: 94475, Duration: "3288.2s", RouteLabels: []string{routeLabelDefault}},
+			expectedPath:  "/directions-matrix/v1/mapbox/walking/-95.93,41.25;-96.68,40.81",
+		},
+		{
+			name: "no route",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+			},
+			matrixStatus:   http.StatusOK,
+			matrixBody:     `{"code":"Ok","distances":[[null]],"durations":[[null]]}`,
+			expectNoRoutes: true,
+		},
+		{
+			name: "error code",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+			},
+			matrixStatus: http.StatusOK,
+			matrixBody:   `{"code":"InvalidInput","message":"Coordinate is invalid: 200,41"}`,
+			expectErr:    "InvalidInput",
+		},
+		{
+			name: "server error",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+			},
+			matrixStatus: http.StatusServiceUnavailable,
+			matrixBody:   `{"message":"Service unavailable"}`,
+			expectErr:    "status 503",
+		},
+		{
+			name: "address not found",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Nowhere"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+			},
+			expectErr: `could not geocode address "Nowhere"`,
+		},
+		{
+			name: "transit unsupported",
+			body: &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+				TravelMode:   travelModeTransit,
+			},
+			expectErr: "does not support travel mode TRANSIT",
+		},
+		{
+			name: "waypoints unsupported",
+			body: &RequestBody{
+				Origins:       []Origin{{Address: "Omaha, NE"}},
+				Destinations:  []Destination{{Address: "Lincoln, NE"}},
+				Intermediates: []Waypoint{{Address: "Ashland, NE"}},
+			},
+			expectErr: "does not support waypoints",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			provider := newTestMapboxProvider(mapboxDoFunc(&paths, tt.matrixStatus, tt.matrixBody))
+
+			responseBody, err := provider.ComputeRoutes(context.Background(), tt.body, "")
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.expectNoRoutes {
+				if len(responseBody.Routes) != 0 {
+					t.Errorf("expected no routes, got %+v", responseBody.Routes)
+				}
+				return
+			}
+			if len(responseBody.Routes) != 1 {
+				t.Fatalf("expected 1 route, got %d", len(responseBody.Routes))
+			}
+			got := responseBody.Routes[0]
+			if got.DistanceMeters != tt.expectedRoute.DistanceMeters || got.Duration != tt.expectedRoute.Duration ||
+				fmt.Sprint(got.RouteLabels) != fmt.Sprint(tt.expectedRoute.RouteLabels) {
+				t.Errorf("expected route %+v, got %+v", tt.expectedRoute, got)
+			}
+			if last := paths[len(paths)-1]; last != tt.expectedPath {
+				t.Errorf("expected matrix path %s, got %s", tt.expectedPath, last)
+			}
+		})
+	}
+}
+
+func TestMapboxProvider_ErrorsOmitToken(t *testing.T) {
+	// http.Client wraps transport failures in a *url.Error quoting the URL
+	provider := newTestMapboxProvider(func(req *http.Request) (*http.Response, error) {
+		return nil, &url.Error{Op: "Get", URL: req.URL.String(), Err: errors.New("connection refused")}
+	})
+
+	_, err := provider.ComputeRoutes(context.Background(), &RequestBody{
+		Origins:      []Origin{{Address: "Omaha, NE"}},
+		Destinations: []Destination{{Address: "Lincoln, NE"}},
+	}, "")
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if strings.Contains(err.Error(), "mapbox-token") {
+		t.Errorf("error leaks the access token: %v", err)
+	}
+}
+
+func TestProviderFromEnvironment(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		token        string
+		expectMapbox bool
+		expectErr    bool
+	}{
+		{name: "unset uses google"},
+		{name: "google", provider: "google"},
+		{name: "mapbox", provider: "Mapbox", token: "mapbox-token", expectMapbox: true},
+		{name: "mapbox without token", provider: "mapbox", expectErr: true},
+		{name: "unknown provider", provider: "here", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEODISTANCE_PROVIDER", tt.provider)
+			t.Setenv("MAPBOX_ACCESS_TOKEN", tt.token)
+
+			handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{})
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, isMapbox := handler.provider.(*MapboxProvider)
+			if isMapbox != tt.expectMapbox {
+				t.Errorf("expected mapbox provider=%v, got %T", tt.expectMapbox, handler.provider)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_MapboxProvider(t *testing.T) {
+	var paths []string
+	provider := newTestMapboxProvider(mapboxDoFunc(&paths, http.StatusOK, mapboxMatrixResponseBody))
+	handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		t.Errorf("unexpected Google request to %s", req.URL)
+		return nil, fmt.Errorf("unexpected request")
+	}}, WithProvider(provider))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: toolCalculateDistance,
+			Arguments: map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "Lincoln, NE",
+			},
+		},
+	}
+
+	result, err := handler.handleDistanceCalculation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "94.47 km (94475 meters)") || !strings.Contains(text, "Duration: 54m48.2s") {
+		t.Errorf("unexpected result %q", text)
+	}
+}
+
+func TestMapboxProvider_RouteModifiers(t *testing.T) {
+	tests := []struct {
+		name            string
+		travelMode      string
+		modifiers       *RouteModifiers
+		departureTime   string
+		extra           []string
+		alternatives    bool
+		fieldMask       string
+		expectedExclude string
+		expectErr       string
+	}{
+		{name: "no modifiers", travelMode: travelModeDrive},
+		{
+			name:            "driving exclusions",
+			travelMode:      travelModeDrive,
+			modifiers:       &RouteModifiers{AvoidTolls: true, AvoidHighways: true, AvoidFerries: true},
+			expectedExclude: "toll,motorway,ferry",
+		},
+		{
+			name:            "walking ferries",
+			travelMode:      travelModeWalk,
+			modifiers:       &RouteModifiers{AvoidFerries: true},
+			expectedExclude: "ferry",
+		},
+		{
+			name:       "walking tolls",
+			travelMode: travelModeWalk,
+			modifiers:  &RouteModifiers{AvoidTolls: true},
+			expectErr:  "cannot avoid tolls for profile walking",
+		},
+		{
+			name:       "indoor",
+			travelMode: travelModeWalk,
+			modifiers:  &RouteModifiers{AvoidIndoor: true},
+			expectErr:  "does not support avoidIndoor",
+		},
+		{
+			name:       "emission type",
+			travelMode: travelModeDrive,
+			modifiers:  &RouteModifiers{VehicleInfo: &VehicleInfo{EmissionType: emissionTypeElectric}},
+			expectErr:  "does not support emissionType",
+		},
+		{
+			name:          "departure time",
+			travelMode:    travelModeDrive,
+			departureTime: "2030-01-01T08:00:00Z",
+			expectErr:     "does not support departureTime",
+		},
+		{
+			name:       "toll computation",
+			travelMode: travelModeDrive,
+			extra:      []string{extraComputationTolls},
+			expectErr:  "does not support extraComputations",
+		},
+		{
+			name:         "alternative routes",
+			travelMode:   travelModeDrive,
+			alternatives: true,
+			expectErr:    "does not support computeAlternativeRoutes",
+		},
+		{
+			name:       "polyline field",
+			travelMode: travelModeDrive,
+			fieldMask:  fieldMask(fieldMaskFeatures{Polyline: true}),
+			expectErr:  "does not support " + polylineFieldMask,
+		},
+		{
+			name:       "tolls field",
+			travelMode: travelModeDrive,
+			fieldMask:  fieldMask(fieldMaskFeatures{Tolls: true}),
+			expectErr:  "does not support " + tollsFieldMask,
+		},
+		{
+			name:       "base fields",
+			travelMode: travelModeDrive,
+			fieldMask:  fieldMask(fieldMaskFeatures{}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			var exclude string
+			serve := mapboxDoFunc(&paths, http.StatusOK, mapboxMatrixResponseBody)
+			provider := newTestMapboxProvider(func(req *http.Request) (*http.Response, error) {
+				if strings.HasPrefix(req.URL.Path, "/directions-matrix/") {
+					exclude = req.URL.Query().Get("exclude")
+				}
+				return serve(req)
+			})
+
+			_, err := provider.ComputeRoutes(context.Background(), &RequestBody{
+				Origins:                  []Origin{{Address: "Omaha, NE"}},
+				Destinations:             []Destination{{Address: "Lincoln, NE"}},
+				TravelMode:               tt.travelMode,
+				RouteModifiers:           tt.modifiers,
+				DepartureTime:            tt.departureTime,
+				ExtraComputations:        tt.extra,
+				ComputeAlternativeRoutes: tt.alternatives,
+			}, tt.fieldMask)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				if len(paths) != 0 {
+					t.Errorf("expected no request for an unsupported option, got %v", paths)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exclude != tt.expectedExclude {
+				t.Errorf("expected exclude %q, got %q", tt.expectedExclude, exclude)
+			}
+		})
+	}
+}
+
+func TestMapboxProvider_UsesHandlerLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		matrixStatus  []int
+		expectErr     error
+		expectMatrix  int
+		expectSuccess bool
+	}{
+		{
+			name:         "response over the size limit",
+			opts:         []Option{WithMaxResponseSize(100)},
+			matrixStatus: []int{http.StatusOK},
+			expectErr:    ErrResponseTooLarge,
+			expectMatrix: 1,
+		},
+		{
+			name:          "throttled response is retried",
+			opts:          []Option{WithThrottleRetry(1, time.Millisecond)},
+			matrixStatus:  []int{http.StatusTooManyRequests, http.StatusOK},
+			expectMatrix:  2,
+			expectSuccess: true,
+		},
+		{
+			name:         "throttled response without retries",
+			opts:         []Option{WithThrottleRetry(0, 0)},
+			matrixStatus: []int{http.StatusTooManyRequests},
+			expectMatrix: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			matrixCalls := 0
+			serve := mapboxDoFunc(&paths, http.StatusOK, mapboxMatrixResponseBody)
+			provider := newTestMapboxProvider(func(req *http.Request) (*http.Response, error) {
+				if strings.HasPrefix(req.URL.Path, "/directions-matrix/") {
+					status := tt.matrixStatus[min(matrixCalls, len(tt.matrixStatus)-1)]
+					matrixCalls++
+					if status != http.StatusOK {
+						return createMockResponse(status, `{"message":"Too Many Requests"}`), nil
+					}
+				}
+				return serve(req)
+			})
+			if _, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{}, append(tt.opts, WithProvider(provider))...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err := provider.ComputeRoutes(context.Background(), &RequestBody{
+				Origins:      []Origin{{Address: "Omaha, NE"}},
+				Destinations: []Destination{{Address: "Lincoln, NE"}},
+			}, "")
+			if tt.expectSuccess != (err == nil) {
+				t.Fatalf("expected success=%v, got error %v", tt.expectSuccess, err)
+			}
+			if tt.expectErr != nil && !errors.Is(err, tt.expectErr) {
+				t.Errorf("expected %v, got %v", tt.expectErr, err)
+			}
+			if matrixCalls != tt.expectMatrix {
+				t.Errorf("expected %d matrix requests, got %d", tt.expectMatrix, matrixCalls)
+			}
+		})
+	}
+}
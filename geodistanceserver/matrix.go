@@ -0,0 +1,501 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const matrixFieldMask = "originIndex,destinationIndex,duration,distanceMeters,status,condition"
+
+const conditionRouteExists = "ROUTE_EXISTS"
+
+// Per-request limits of computeRouteMatrix: the total element count, the
+// lower element count for TRAFFIC_AWARE_OPTIMAL and TRANSIT, and the number
+// of origins plus destinations given as addresses.
+const (
+	maxMatrixElements         = 625
+	maxMatrixElementsLimited  = 100
+	maxMatrixAddressWaypoints = 50
+)
+
+// maxMatrixConcurrency bounds the number of chunk requests in flight when
+// an oversized matrix is split.
+const maxMatrixConcurrency = 4
+
+// matrixChunk is a sub-request covering origins [originStart, originEnd)
+// and destinations [destinationStart, destinationEnd).
+type matrixChunk struct {
+	originStart, originEnd           int
+	destinationStart, destinationEnd int
+}
+
+type Waypoint struct {
+	Address  string    `json:"address,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location places a waypoint at fixed coordinates instead of an address.
+type Location struct {
+	LatLng LatLng `json:"latLng"`
+}
+
+// LatLng is always serialized in full: 0 is a valid latitude or longitude.
+type LatLng struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type MatrixOrigin struct {
+	Waypoint       Waypoint        `json:"waypoint"`
+	RouteModifiers *RouteModifiers `json:"routeModifiers,omitempty"`
+}
+
+type MatrixDestination struct {
+	Waypoint Waypoint `json:"waypoint"`
+}
+
+type MatrixRequestBody struct {
+	Origins            []MatrixOrigin      `json:"origins"`
+	Destinations       []MatrixDestination `json:"destinations"`
+	TravelMode         string              `json:"travelMode,omitempty"`
+	RoutingPreference  string              `json:"routingPreference,omitempty"`
+	LanguageCode       string              `json:"languageCode,omitempty"`
+	RegionCode         string              `json:"regionCode,omitempty"`
+	DepartureTime      string              `json:"departureTime,omitempty"`
+	ArrivalTime        string              `json:"arrivalTime,omitempty"`
+	TransitPreferences *TransitPreferences `json:"transitPreferences,omitempty"`
+}
+
+type MatrixElement struct {
+	OriginIndex      int             `json:"originIndex"`
+	DestinationIndex int             `json:"destinationIndex"`
+	Status           json.RawMessage `json:"status,omitempty"`
+	DistanceMeters   int             `json:"distanceMeters"`
+	Duration         string          `json:"duration"`
+	Condition        string          `json:"condition"`
+}
+
+func (gh *GeodistanceHandler) handleDistanceMatrix(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	ctx = contextWithRequestIDArgument(ctx, request)
+
+	args, err := requireStringSliceArguments(request, toolCalculateDistanceMatrix, "originAddresses", "destinationAddresses")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	originAddresses, destinationAddresses := args[0], args[1]
+	for i := range originAddresses {
+		originAddresses[i] = normalizeAddress(originAddresses[i])
+	}
+	for i := range destinationAddresses {
+		destinationAddresses[i] = normalizeAddress(destinationAddresses[i])
+	}
+
+	if err := gh.validateMatrixAddresses(originAddresses, destinationAddresses); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	opts, err := gh.parseRouteOptions(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	page, err := parseMatrixPage(request, originAddresses, destinationAddresses)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	// Only the page's rows are requested; oversized pages are still split
+	// into chunks when autoSplit is set
+	originAddresses = originAddresses[page.start:page.end]
+
+	var elements []MatrixElement
+	if err := validateMatrixSize(len(originAddresses), len(destinationAddresses), opts); err != nil {
+		if !request.GetBool("autoSplit", false) {
+			return nil, invalidArgument(fmt.Errorf("%w; set autoSplit to split it into multiple requests", err))
+		}
+		elements, err = gh.callRouteMatrixChunked(ctx, originAddresses, destinationAddresses, opts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		elements, err = gh.callRouteMatrix(ctx, originAddresses, destinationAddresses, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	grid, err := indexMatrix(elements, len(originAddresses), len(destinationAddresses))
+	if err != nil {
+		return nil, err
+	}
+
+	result := gh.formatMatrixResponse(grid, originAddresses, destinationAddresses, opts)
+	if opts.Debug {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: estimateElements(len(originAddresses), len(destinationAddresses), newCostFeatures(opts)).String(),
+		})
+	}
+	if page.next != "" {
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: "Next pageToken: " + page.next,
+		})
+	}
+	return result, nil
+}
+
+func (gh *GeodistanceHandler) validateMatrixAddresses(origins, destinations []string) error {
+	if len(origins) == 0 {
+		return fmt.Errorf("at least one origin address is required")
+	}
+	if len(destinations) == 0 {
+		return fmt.Errorf("at least one destination address is required")
+	}
+	for i, origin := range origins {
+		if origin == "" {
+			return fmt.Errorf("origin address %d cannot be empty", i+1)
+		}
+		if err := gh.validateAddress(fmt.Sprintf("origin address %d", i+1), origin); err != nil {
+			return err
+		}
+	}
+	for i, destination := range destinations {
+		if destination == "" {
+			return fmt.Errorf("destination address %d cannot be empty", i+1)
+		}
+		if err := gh.validateAddress(fmt.Sprintf("destination address %d", i+1), destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matrixElementLimit returns the most elements one computeRouteMatrix
+// request may carry with the given options, and the setting that lowers it
+// below maxMatrixElements, if any.
+func matrixElementLimit(opts routeOptions) (int, string) {
+	mode := travelModeOrDefault(opts.TravelMode)
+	if mode == travelModeTransit {
+		return maxMatrixElementsLimited, "travel mode " + travelModeTransit
+	}
+	if supportsRoutingPreference(mode) && opts.RoutingPreference == routingPreferenceTrafficAwareOptimal {
+		return maxMatrixElementsLimited, "routing preference " + routingPreferenceTrafficAwareOptimal
+	}
+	return maxMatrixElements, ""
+}
+
+func validateMatrixSize(numOrigins, numDestinations int, opts routeOptions) error {
+	if numOrigins+numDestinations > maxMatrixAddressWaypoints {
+		return fmt.Errorf("matrix of %d origins and %d destinations exceeds the per-request limit of %d addresses",
+			numOrigins, numDestinations, maxMatrixAddressWaypoints)
+	}
+	if limit, reason := matrixElementLimit(opts); numOrigins*numDestinations > limit {
+		if reason != "" {
+			return fmt.Errorf("matrix of %d elements exceeds the per-request limit of %d elements for %s",
+				numOrigins*numDestinations, limit, reason)
+		}
+		return fmt.Errorf("matrix of %d elements exceeds the per-request limit of %d elements",
+			numOrigins*numDestinations, limit)
+	}
+	return nil
+}
+
+// planMatrixChunks tiles an origins × destinations matrix into sub-requests
+// that each satisfy validateMatrixSize with the given options.
+func planMatrixChunks(numOrigins, numDestinations int, opts routeOptions) []matrixChunk {
+	maxElements, _ := matrixElementLimit(opts)
+	originSize := min(numOrigins, maxMatrixAddressWaypoints/2)
+	destinationSize := min(numDestinations, maxMatrixAddressWaypoints-originSize, maxElements/originSize)
+
+	var chunks []matrixChunk
+	for o := 0; o < numOrigins; o += originSize {
+		for d := 0; d < numDestinations; d += destinationSize {
+			chunks = append(chunks, matrixChunk{
+				originStart:      o,
+				originEnd:        min(o+originSize, numOrigins),
+				destinationStart: d,
+				destinationEnd:   min(d+destinationSize, numDestinations),
+			})
+		}
+	}
+	return chunks
+}
+
+func (gh *GeodistanceHandler) buildMatrixRequestBody(origins, destinations []string, opts routeOptions) *MatrixRequestBody {
+	routingPreference := opts.RoutingPreference
+	if routingPreference == "" {
+		routingPreference = routingPreferenceTrafficAware
+	}
+
+	var modifiers *RouteModifiers
+	if opts.RouteModifiers != (RouteModifiers{}) {
+		m := opts.RouteModifiers
+		modifiers = &m
+	}
+
+	body := &MatrixRequestBody{
+		Origins:            make([]MatrixOrigin, len(origins)),
+		Destinations:       make([]MatrixDestination, len(destinations)),
+		TravelMode:         travelModeOrDefault(opts.TravelMode),
+		LanguageCode:       languageCodeOrDefault(opts.LanguageCode),
+		RegionCode:         opts.RegionCode,
+		TransitPreferences: opts.TransitPreferences,
+	}
+	if supportsRoutingPreference(body.TravelMode) {
+		body.RoutingPreference = routingPreference
+	}
+	for i, address := range origins {
+		body.Origins[i] = MatrixOrigin{Waypoint: Waypoint{Address: address}, RouteModifiers: modifiers}
+	}
+	for i, address := range destinations {
+		body.Destinations[i] = MatrixDestination{Waypoint: Waypoint{Address: address}}
+	}
+
+	if !opts.DepartureTime.IsZero() {
+		body.DepartureTime = opts.DepartureTime.UTC().Format(time.RFC3339)
+	}
+	if !opts.ArrivalTime.IsZero() {
+		body.ArrivalTime = opts.ArrivalTime.UTC().Format(time.RFC3339)
+	}
+
+	return body
+}
+
+func (gh *GeodistanceHandler) processMatrixResponse(resp *http.Response) ([]MatrixElement, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes := gh.readErrorBody(resp.Body)
+		return nil, errorFromResponse(resp.StatusCode, bodyBytes)
+	}
+
+	bodyBytes, err := gh.readResponseBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiErr := parseAPIError(bodyBytes); apiErr != nil {
+		apiErr.HTTPStatus = resp.StatusCode
+		return nil, apiErr
+	}
+
+	var elements []MatrixElement
+	if err := gh.decodeResponse(bodyBytes, &elements); err != nil {
+		return nil, err
+	}
+
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("no matrix elements found in response")
+	}
+
+	return elements, nil
+}
+
+// indexMatrix arranges response elements into an origins × destinations
+// grid. The API streams elements in arbitrary order, so each one is placed
+// by its originIndex/destinationIndex; cells the API did not return stay nil.
+func indexMatrix(elements []MatrixElement, numOrigins, numDestinations int) ([][]*MatrixElement, error) {
+	grid := make([][]*MatrixElement, numOrigins)
+	for i := range grid {
+		grid[i] = make([]*MatrixElement, numDestinations)
+	}
+
+	for i := range elements {
+		element := &elements[i]
+		if element.OriginIndex < 0 || element.OriginIndex >= numOrigins ||
+			element.DestinationIndex < 0 || element.DestinationIndex >= numDestinations {
+			return nil, fmt.Errorf("matrix element index out of range: origin %d, destination %d",
+				element.OriginIndex, element.DestinationIndex)
+		}
+		grid[element.OriginIndex][element.DestinationIndex] = element
+	}
+
+	return grid, nil
+}
+
+func (gh *GeodistanceHandler) formatMatrixResponse(
+	grid [][]*MatrixElement,
+	origins, destinations []string,
+	opts routeOptions,
+) *mcp.CallToolResult {
+	var lines []string
+	for i, row := range grid {
+		for j, element := range row {
+			lines = append(lines, fmt.Sprintf("%s -> %s: %s", origins[i], destinations[j], gh.formatMatrixCell(element, opts)))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: strings.Join(lines, "\n"),
+			},
+		},
+	}
+}
+
+// elementStatusMessage inspects an element's raw google.rpc.Status and
+// returns a friendly description of the failure, or ok=true when the
+// element succeeded. A missing or empty status means success.
+func elementStatusMessage(status json.RawMessage) (message string, ok bool) {
+	if len(status) == 0 {
+		return "", true
+	}
+
+	var parsed struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(status, &parsed); err != nil {
+		return "unreadable element status", false
+	}
+	if parsed.Code == 0 {
+		return "", true
+	}
+	if parsed.Message != "" {
+		return parsed.Message, false
+	}
+	return fmt.Sprintf("status code %d", parsed.Code), false
+}
+
+func (gh *GeodistanceHandler) formatMatrixCell(element *MatrixElement, opts routeOptions) string {
+	if element == nil {
+		return "no result returned"
+	}
+	if message, ok := elementStatusMessage(element.Status); !ok {
+		return fmt.Sprintf("no route available (%s)", message)
+	}
+	if element.Condition != conditionRouteExists {
+		return "no route available"
+	}
+	return fmt.Sprintf("%s, Duration: %s", formatDistance(roundMeters(element.DistanceMeters, opts.RoundMeters), opts.Units, opts.NumberLocale), formatDuration(element.Duration, opts.DurationFormat))
+}
+
+func (gh *GeodistanceHandler) callRouteMatrix(
+	ctx context.Context,
+	origins []string,
+	destinations []string,
+	opts routeOptions,
+) ([]MatrixElement, error) {
+	body := gh.buildMatrixRequestBody(origins, destinations, opts)
+
+	endpoint := gh.baseURL
+	if endpoint == "" {
+		endpoint = defaultBaseURL
+	}
+	if err := gh.waitRateLimit(ctx); err != nil {
+		return nil, annotateRequestID(ctx, err)
+	}
+
+	req, err := gh.newAPIRequest(ctx, endpoint, body, matrixFieldMask)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := gh.do(ctx, req)
+	gh.metrics.observeRequestDuration(time.Since(start))
+	if err != nil {
+		err = fmt.Errorf("failed to execute request: %w", err)
+		gh.metrics.incError(errorCategoryNetwork)
+		gh.logAPICall(ctx, req, body.TravelMode, 0, time.Since(start), err)
+		return nil, annotateRequestID(ctx, err)
+	}
+
+	elements, err := gh.processMatrixResponse(resp)
+	if err != nil {
+		gh.metrics.incError(errorCategoryAPI)
+		gh.logAPICall(ctx, req, body.TravelMode, resp.StatusCode, time.Since(start), err)
+		return nil, annotateRequestID(ctx, err)
+	}
+
+	gh.logAPICall(ctx, req, body.TravelMode, resp.StatusCode, time.Since(start), nil,
+		slog.Int("elements", len(elements)))
+
+	return elements, nil
+}
+
+// callRouteMatrixChunked fans the chunks of an oversized matrix out to at
+// most maxMatrixConcurrency concurrent requests and reassembles the
+// elements by their global indices. The first failure cancels the
+// remaining requests and is returned.
+func (gh *GeodistanceHandler) callRouteMatrixChunked(
+	ctx context.Context,
+	origins []string,
+	destinations []string,
+	opts routeOptions,
+) ([]MatrixElement, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunks := planMatrixChunks(len(origins), len(destinations), opts)
+	results := make([][]MatrixElement, len(chunks))
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	work := make(chan int)
+	for w := 0; w < min(maxMatrixConcurrency, len(chunks)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				chunk := chunks[i]
+				chunkElements, err := gh.callRouteMatrix(ctx,
+					origins[chunk.originStart:chunk.originEnd],
+					destinations[chunk.destinationStart:chunk.destinationEnd],
+					opts)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+
+				for j := range chunkElements {
+					chunkElements[j].OriginIndex += chunk.originStart
+					chunkElements[j].DestinationIndex += chunk.destinationStart
+				}
+				results[i] = chunkElements
+			}
+		}()
+	}
+
+dispatch:
+	for i := range chunks {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var elements []MatrixElement
+	for _, chunkElements := range results {
+		elements = append(elements, chunkElements...)
+	}
+	return elements, nil
+}
@@ -0,0 +1,699 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Elements arrive out of order, as the Routes API streams them
+const validMatrixResponse = `[
+  {"originIndex": 0, "destinationIndex": 0, "status": {}, "distanceMeters": 825, "duration": "180s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 1, "destinationIndex": 0, "status": {}, "distanceMeters": 2920, "duration": "390s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 1, "destinationIndex": 1, "status": {}, "distanceMeters": 6134, "duration": "689s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 0, "destinationIndex": 1, "status": {}, "distanceMeters": 6705, "duration": "1116s", "condition": "ROUTE_EXISTS"}
+]`
+
+const partialFailureMatrixResponse = `[
+  {"originIndex": 0, "destinationIndex": 0, "status": {}, "distanceMeters": 825, "duration": "180s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 0, "destinationIndex": 1, "status": {}, "condition": "ROUTE_NOT_FOUND"},
+  {"originIndex": 1, "destinationIndex": 0, "status": {}, "distanceMeters": 2920, "duration": "390s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 1, "destinationIndex": 1, "status": {"code": 3, "message": "Waypoint could not be geocoded."}}
+]`
+
+func TestGeodistanceHandler_buildMatrixRequestBody(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	body := handler.buildMatrixRequestBody(
+		[]string{"New York", "Boston"},
+		[]string{"Philadelphia"},
+		routeOptions{RouteModifiers: RouteModifiers{AvoidTolls: true}},
+	)
+
+	if len(body.Origins) != 2 || body.Origins[1].Waypoint.Address != "Boston" {
+		t.Error("origins not set correctly")
+	}
+	if len(body.Destinations) != 1 || body.Destinations[0].Waypoint.Address != "Philadelphia" {
+		t.Error("destinations not set correctly")
+	}
+	if body.RoutingPreference != routingPreferenceTrafficAware {
+		t.Errorf("expected default routing preference, got %s", body.RoutingPreference)
+	}
+	for _, origin := range body.Origins {
+		if origin.RouteModifiers == nil || !origin.RouteModifiers.AvoidTolls {
+			t.Error("route modifiers not applied to every origin")
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `"origins":[{"waypoint":{"address":"New York"}`) {
+		t.Errorf("unexpected origin serialization: %s", data)
+	}
+}
+
+func TestIndexMatrix(t *testing.T) {
+	var elements []MatrixElement
+	if err := json.Unmarshal([]byte(validMatrixResponse), &elements); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	grid, err := indexMatrix(elements, 2, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := [][]int{{825, 6705}, {2920, 6134}}
+	for i := range expected {
+		for j := range expected[i] {
+			if grid[i][j] == nil {
+				t.Fatalf("cell [%d][%d] not populated", i, j)
+			}
+			if grid[i][j].DistanceMeters != expected[i][j] {
+				t.Errorf("cell [%d][%d]: expected %d meters, got %d", i, j, expected[i][j], grid[i][j].DistanceMeters)
+			}
+		}
+	}
+
+	if _, err := indexMatrix(elements, 1, 2); err == nil {
+		t.Error("expected error for out-of-range origin index")
+	}
+}
+
+func TestElementStatusMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          string
+		expectedMessage string
+		expectedOK      bool
+	}{
+		{name: "missing status", status: "", expectedOK: true},
+		{name: "empty status", status: `{}`, expectedOK: true},
+		{name: "explicit OK code", status: `{"code": 0}`, expectedOK: true},
+		{
+			name:            "error with message",
+			status:          `{"code": 5, "message": "Route not found."}`,
+			expectedMessage: "Route not found.",
+		},
+		{
+			name:            "error without message",
+			status:          `{"code": 13}`,
+			expectedMessage: "status code 13",
+		},
+		{
+			name:            "malformed status",
+			status:          `"broken"`,
+			expectedMessage: "unreadable element status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, ok := elementStatusMessage(json.RawMessage(tt.status))
+			if ok != tt.expectedOK {
+				t.Errorf("expected ok=%v, got %v", tt.expectedOK, ok)
+			}
+			if message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, message)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_formatMatrixCell(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	opts := routeOptions{Units: unitsMetric}
+
+	tests := []struct {
+		name     string
+		element  *MatrixElement
+		expected string
+	}{
+		{
+			name:     "missing element",
+			element:  nil,
+			expected: "no result returned",
+		},
+		{
+			name:     "route not found",
+			element:  &MatrixElement{Status: json.RawMessage(`{}`), Condition: "ROUTE_NOT_FOUND"},
+			expected: "no route available",
+		},
+		{
+			name:     "failed status",
+			element:  &MatrixElement{Status: json.RawMessage(`{"code": 3, "message": "Invalid waypoint."}`)},
+			expected: "no route available (Invalid waypoint.)",
+		},
+		{
+			name:     "route exists",
+			element:  &MatrixElement{Status: json.RawMessage(`{}`), DistanceMeters: 1000, Duration: "60s", Condition: conditionRouteExists},
+			expected: "1.00 km (1000 meters), Duration: 1m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handler.formatMatrixCell(tt.element, opts); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_processMatrixResponse(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   int
+		expectErr  bool
+	}{
+		{
+			name:       "successful response",
+			statusCode: http.StatusOK,
+			body:       validMatrixResponse,
+			expected:   4,
+		},
+		{
+			name:       "API error",
+			statusCode: http.StatusBadRequest,
+			body:       `{"error": "Invalid request"}`,
+			expectErr:  true,
+		},
+		{
+			name:       "error object in OK body",
+			statusCode: http.StatusOK,
+			body:       `{"error": {"code": 400, "message": "Invalid waypoint.", "status": "INVALID_ARGUMENT"}}`,
+			expectErr:  true,
+		},
+		{
+			name:       "empty matrix",
+			statusCode: http.StatusOK,
+			body:       `[]`,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := handler.processMatrixResponse(createMockResponse(tt.statusCode, tt.body))
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(elements) != tt.expected {
+				t.Errorf("expected %d elements, got %d", tt.expected, len(elements))
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceMatrix(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestArgs  map[string]interface{}
+		mockFunc     func(req *http.Request) (*http.Response, error)
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name: "2x2 matrix",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{"A", "B"},
+				"destinationAddresses": []interface{}{"C", "D"},
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("X-Goog-FieldMask") != matrixFieldMask {
+					return nil, fmt.Errorf("unexpected field mask: %s", req.Header.Get("X-Goog-FieldMask"))
+				}
+				return createMockResponse(http.StatusOK, validMatrixResponse), nil
+			},
+			expectedText: strings.Join([]string{
+				"A -> C: 0.82 km (825 meters), Duration: 3m0s",
+				"A -> D: 6.71 km (6705 meters), Duration: 18m36s",
+				"B -> C: 2.92 km (2920 meters), Duration: 6m30s",
+				"B -> D: 6.13 km (6134 meters), Duration: 11m29s",
+			}, "\n"),
+		},
+		{
+			name: "partial failure",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{"A", "B"},
+				"destinationAddresses": []interface{}{"C", "D"},
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, partialFailureMatrixResponse), nil
+			},
+			expectedText: strings.Join([]string{
+				"A -> C: 0.82 km (825 meters), Duration: 3m0s",
+				"A -> D: no route available",
+				"B -> C: 2.92 km (2920 meters), Duration: 6m30s",
+				"B -> D: no route available (Waypoint could not be geocoded.)",
+			}, "\n"),
+		},
+		{
+			name:        "missing arguments",
+			requestArgs: map[string]interface{}{},
+			expectErr:   true,
+		},
+		{
+			name: "empty origins",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{},
+				"destinationAddresses": []interface{}{"C"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty destination entry",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{"A"},
+				"destinationAddresses": []interface{}{"C", ""},
+			},
+			expectErr: true,
+		},
+		{
+			name: "origin entry too long",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{"A", strings.Repeat("a", defaultMaxAddressLength+1)},
+				"destinationAddresses": []interface{}{"C"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "API failure",
+			requestArgs: map[string]interface{}{
+				"originAddresses":      []interface{}{"A"},
+				"destinationAddresses": []interface{}{"C"},
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("network error")
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: tt.mockFunc},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "calculate_distance_matrix",
+					Arguments: tt.requestArgs,
+				},
+			}
+
+			result, err := handler.handleDistanceMatrix(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				if result != nil {
+					t.Error("expected nil result when error occurs")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatal("expected text content")
+			}
+			if textContent.Text != tt.expectedText {
+				t.Errorf("expected text:\n%s\ngot:\n%s", tt.expectedText, textContent.Text)
+			}
+		})
+	}
+}
+
+// syntheticMatrixDoFunc answers matrix requests for addresses named "o<N>"
+// and "d<N>" with distanceMeters = N(origin)*1000 + N(destination), so the
+// stitched grid can be checked cell by cell.
+func syntheticMatrixDoFunc(requests *int32) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(requests, 1)
+
+		var body MatrixRequestBody
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		opts := routeOptions{TravelMode: body.TravelMode, RoutingPreference: body.RoutingPreference}
+		if err := validateMatrixSize(len(body.Origins), len(body.Destinations), opts); err != nil {
+			return createMockResponse(http.StatusBadRequest, err.Error()), nil
+		}
+
+		var elements []MatrixElement
+		for i, origin := range body.Origins {
+			for j, destination := range body.Destinations {
+				var o, d int
+				fmt.Sscanf(origin.Waypoint.Address, "o%d", &o)
+				fmt.Sscanf(destination.Waypoint.Address, "d%d", &d)
+				elements = append(elements, MatrixElement{
+					OriginIndex:      i,
+					DestinationIndex: j,
+					DistanceMeters:   o*1000 + d,
+					Duration:         "60s",
+					Condition:        conditionRouteExists,
+				})
+			}
+		}
+		data, _ := json.Marshal(elements)
+		return createMockResponse(http.StatusOK, string(data)), nil
+	}
+}
+
+func syntheticAddresses(prefix string, n int) []string {
+	addresses := make([]string, n)
+	for i := range addresses {
+		addresses[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return addresses
+}
+
+func toAny(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+func TestValidateMatrixSize(t *testing.T) {
+	aware := routeOptions{RoutingPreference: routingPreferenceTrafficAware}
+	unaware := routeOptions{RoutingPreference: routingPreferenceTrafficUnaware}
+	optimal := routeOptions{RoutingPreference: routingPreferenceTrafficAwareOptimal}
+	transit := routeOptions{TravelMode: travelModeTransit, RoutingPreference: routingPreferenceTrafficAware}
+
+	tests := []struct {
+		name            string
+		numOrigins      int
+		numDestinations int
+		opts            routeOptions
+		expectErr       bool
+	}{
+		{name: "small matrix", numOrigins: 2, numDestinations: 2, expectErr: false},
+		{name: "at address limit", numOrigins: 25, numDestinations: 25, expectErr: false},
+		{name: "over address limit", numOrigins: 1, numDestinations: 50, expectErr: true},
+		{name: "over both limits", numOrigins: 30, numDestinations: 30, expectErr: true},
+		{name: "traffic aware at element limit", numOrigins: 25, numDestinations: 25, opts: aware, expectErr: false},
+		{name: "traffic unaware at element limit", numOrigins: 25, numDestinations: 25, opts: unaware, expectErr: false},
+		{name: "traffic aware optimal at element limit", numOrigins: 10, numDestinations: 10, opts: optimal, expectErr: false},
+		{name: "traffic aware optimal over element limit", numOrigins: 1, numDestinations: 101, opts: optimal, expectErr: true},
+		{name: "traffic aware optimal 11x10", numOrigins: 11, numDestinations: 10, opts: optimal, expectErr: true},
+		{name: "transit at element limit", numOrigins: 20, numDestinations: 5, opts: transit, expectErr: false},
+		{name: "transit over element limit", numOrigins: 17, numDestinations: 6, opts: transit, expectErr: true},
+		// Routing preferences are not sent for walking, so the limit is the default
+		{name: "walking ignores traffic aware optimal", numOrigins: 25, numDestinations: 25, opts: routeOptions{TravelMode: travelModeWalk, RoutingPreference: routingPreferenceTrafficAwareOptimal}, expectErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMatrixSize(tt.numOrigins, tt.numDestinations, tt.opts)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPlanMatrixChunks(t *testing.T) {
+	sizes := [][2]int{{1, 1}, {1, 100}, {100, 1}, {30, 30}, {25, 25}, {60, 7}}
+	preferences := []string{routingPreferenceTrafficAware, routingPreferenceTrafficAwareOptimal}
+
+	for _, size := range sizes {
+		for _, preference := range preferences {
+			numOrigins, numDestinations := size[0], size[1]
+			opts := routeOptions{RoutingPreference: preference}
+			t.Run(fmt.Sprintf("%dx%d %s", numOrigins, numDestinations, preference), func(t *testing.T) {
+				covered := make(map[[2]int]int)
+				for _, chunk := range planMatrixChunks(numOrigins, numDestinations, opts) {
+					if err := validateMatrixSize(chunk.originEnd-chunk.originStart, chunk.destinationEnd-chunk.destinationStart, opts); err != nil {
+						t.Errorf("chunk %+v exceeds limits: %v", chunk, err)
+					}
+					for o := chunk.originStart; o < chunk.originEnd; o++ {
+						for d := chunk.destinationStart; d < chunk.destinationEnd; d++ {
+							covered[[2]int{o, d}]++
+						}
+					}
+				}
+				if len(covered) != numOrigins*numDestinations {
+					t.Errorf("expected %d cells covered, got %d", numOrigins*numDestinations, len(covered))
+				}
+				for cell, count := range covered {
+					if count != 1 {
+						t.Errorf("cell %v covered %d times", cell, count)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceMatrix_AutoSplit(t *testing.T) {
+	origins := syntheticAddresses("o", 30)
+	destinations := syntheticAddresses("d", 30)
+
+	tests := []struct {
+		name             string
+		autoSplit        bool
+		expectErr        bool
+		expectedRequests int32
+	}{
+		{name: "limit exceeded without autoSplit", autoSplit: false, expectErr: true, expectedRequests: 0},
+		{name: "limit exceeded with autoSplit", autoSplit: true, expectErr: false, expectedRequests: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance_matrix",
+					Arguments: map[string]interface{}{
+						"originAddresses":      toAny(origins),
+						"destinationAddresses": toAny(destinations),
+						"autoSplit":            tt.autoSplit,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceMatrix(context.Background(), request)
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d sub-requests, got %d", tt.expectedRequests, requests)
+			}
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			lines := strings.Split(text, "\n")
+			if len(lines) != 900 {
+				t.Fatalf("expected 900 cells, got %d", len(lines))
+			}
+			for _, check := range []struct{ o, d int }{{0, 0}, {29, 29}, {27, 3}, {3, 27}} {
+				meters := check.o*1000 + check.d
+				expected := fmt.Sprintf("o%d -> d%d: %s, Duration: 1m0s", check.o, check.d, formatDistance(meters, unitsMetric, ""))
+				if lines[check.o*30+check.d] != expected {
+					t.Errorf("expected %q, got %q", expected, lines[check.o*30+check.d])
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceMatrix_ElementLimit(t *testing.T) {
+	tests := []struct {
+		name              string
+		origins           int
+		destinations      int
+		routingPreference string
+		autoSplit         bool
+		expectErr         string
+		expectedRequests  int32
+	}{
+		{name: "traffic aware at limit", origins: 25, destinations: 25, routingPreference: routingPreferenceTrafficAware, expectedRequests: 1},
+		{name: "traffic unaware at limit", origins: 25, destinations: 25, routingPreference: routingPreferenceTrafficUnaware, expectedRequests: 1},
+		{name: "traffic aware optimal at limit", origins: 10, destinations: 10, routingPreference: routingPreferenceTrafficAwareOptimal, expectedRequests: 1},
+		{
+			name: "traffic aware optimal over limit", origins: 11, destinations: 10, routingPreference: routingPreferenceTrafficAwareOptimal,
+			expectErr: "limit of 100 elements for routing preference TRAFFIC_AWARE_OPTIMAL",
+		},
+		{name: "traffic aware optimal split", origins: 11, destinations: 10, routingPreference: routingPreferenceTrafficAwareOptimal, autoSplit: true, expectedRequests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: toolCalculateDistanceMatrix,
+					Arguments: map[string]interface{}{
+						"originAddresses":      toAny(syntheticAddresses("o", tt.origins)),
+						"destinationAddresses": toAny(syntheticAddresses("d", tt.destinations)),
+						"routingPreference":    tt.routingPreference,
+						"autoSplit":            tt.autoSplit,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceMatrix(context.Background(), request)
+			if requests != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests)
+			}
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lines := strings.Split(result.Content[0].(mcp.TextContent).Text, "\n"); len(lines) != tt.origins*tt.destinations {
+				t.Errorf("expected %d cells, got %d", tt.origins*tt.destinations, len(lines))
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_callRouteMatrixChunked_Concurrency(t *testing.T) {
+	origins := syntheticAddresses("o", 100)
+	destinations := syntheticAddresses("d", 30)
+
+	var requests, inFlight, peak int32
+	synthetic := syntheticMatrixDoFunc(&requests)
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if current <= p || atomic.CompareAndSwapInt32(&peak, p, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return synthetic(req)
+		}},
+	}
+
+	elements, err := handler.callRouteMatrixChunked(context.Background(), origins, destinations, routeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedChunks := int32(len(planMatrixChunks(len(origins), len(destinations), routeOptions{})))
+	if requests != expectedChunks {
+		t.Errorf("expected %d sub-requests, got %d", expectedChunks, requests)
+	}
+	if peak > maxMatrixConcurrency {
+		t.Errorf("expected at most %d concurrent requests, got %d", maxMatrixConcurrency, peak)
+	}
+	if peak < 2 {
+		t.Errorf("expected chunks to be requested concurrently, peak was %d", peak)
+	}
+
+	grid, err := indexMatrix(elements, len(origins), len(destinations))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for o := range origins {
+		for d := range destinations {
+			if grid[o][d] == nil {
+				t.Fatalf("cell %d,%d not populated", o, d)
+			}
+			if grid[o][d].DistanceMeters != o*1000+d {
+				t.Errorf("cell %d,%d: expected %d meters, got %d", o, d, o*1000+d, grid[o][d].DistanceMeters)
+			}
+		}
+	}
+}
+
+func TestGeodistanceHandler_callRouteMatrixChunked_FirstError(t *testing.T) {
+	origins := syntheticAddresses("o", 100)
+	destinations := syntheticAddresses("d", 30)
+
+	var requests int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				return createMockResponse(http.StatusInternalServerError, "backend unavailable"), nil
+			}
+			// Other workers block until the failure cancels them
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}},
+	}
+
+	_, err := handler.callRouteMatrixChunked(context.Background(), origins, destinations, routeOptions{})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("expected the first error to be returned, got %v", err)
+	}
+	if total := int(atomic.LoadInt32(&requests)); total >= len(planMatrixChunks(len(origins), len(destinations), routeOptions{})) {
+		t.Errorf("expected remaining chunks to be skipped after the failure, got %d requests", total)
+	}
+}
+
+func TestGeodistanceHandler_callRouteMatrixChunked_ContextCanceled(t *testing.T) {
+	var requests int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := handler.callRouteMatrixChunked(ctx, syntheticAddresses("o", 60), syntheticAddresses("d", 30), routeOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package geodistanceserver
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// matrixPage is the range of origin rows one paged matrix call returns,
+// with the token of the following page, empty on the last one.
+type matrixPage struct {
+	start, end int
+	next       string
+}
+
+// parseMatrixPage reads the optional pageSize and pageToken arguments of
+// calculate_distance_matrix. Without pageSize every row is returned. Page
+// tokens carry the first row of the page and a fingerprint of the
+// addresses, so a token is only accepted for the matrix that issued it.
+func parseMatrixPage(request mcp.CallToolRequest, origins, destinations []string) (matrixPage, error) {
+	all := matrixPage{start: 0, end: len(origins)}
+
+	size, err := optionalFloatArgument(request, toolCalculateDistanceMatrix, "pageSize")
+	if err != nil {
+		return matrixPage{}, err
+	}
+	token := request.GetString("pageToken", "")
+	if size == nil {
+		if token != "" {
+			return matrixPage{}, fmt.Errorf("pageToken requires pageSize")
+		}
+		return all, nil
+	}
+	if *size != math.Trunc(*size) || *size < 1 {
+		return matrixPage{}, fmt.Errorf("invalid pageSize %v: must be a whole number of at least 1", *size)
+	}
+
+	fingerprint := matrixFingerprint(origins, destinations)
+	start := 0
+	if token != "" {
+		if start, err = decodePageToken(token, fingerprint); err != nil {
+			return matrixPage{}, err
+		}
+		if start >= len(origins) {
+			return matrixPage{}, fmt.Errorf("invalid pageToken: past the last origin")
+		}
+	}
+
+	page := matrixPage{start: start, end: len(origins)}
+	if rows := float64(len(origins) - start); *size < rows {
+		page.end = start + int(*size)
+		page.next = encodePageToken(page.end, fingerprint)
+	}
+	return page, nil
+}
+
+// matrixFingerprint identifies a matrix by its addresses.
+func matrixFingerprint(origins, destinations []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(origins, "\x00") + "\x01" + strings.Join(destinations, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodePageToken(start int, fingerprint string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(start) + ":" + fingerprint))
+}
+
+func decodePageToken(token, fingerprint string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pageToken %q", token)
+	}
+	row, tokenFingerprint, ok := strings.Cut(string(data), ":")
+	start, err := strconv.Atoi(row)
+	if !ok || err != nil || start < 1 {
+		return 0, fmt.Errorf("invalid pageToken %q", token)
+	}
+	if tokenFingerprint != fingerprint {
+		return 0, fmt.Errorf("pageToken %q was issued for different addresses", token)
+	}
+	return start, nil
+}
@@ -0,0 +1,174 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleDistanceMatrix_Paging(t *testing.T) {
+	tests := []struct {
+		name          string
+		origins       int
+		destinations  int
+		pageSize      float64
+		autoSplit     bool
+		expectedPages int
+	}{
+		{name: "even pages", origins: 6, destinations: 4, pageSize: 2, expectedPages: 3},
+		{name: "short last page", origins: 7, destinations: 3, pageSize: 3, expectedPages: 3},
+		{name: "single page", origins: 3, destinations: 3, pageSize: 10, expectedPages: 1},
+		// A 30-row page of 30 destinations is still split into chunks
+		{name: "pages larger than a request", origins: 40, destinations: 30, pageSize: 30, autoSplit: true, expectedPages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: syntheticMatrixDoFunc(&requests)},
+			}
+			origins := syntheticAddresses("o", tt.origins)
+			destinations := syntheticAddresses("d", tt.destinations)
+
+			seen := make(map[string]int)
+			token := ""
+			pages := 0
+			for {
+				args := map[string]interface{}{
+					"originAddresses":      toAny(origins),
+					"destinationAddresses": toAny(destinations),
+					"pageSize":             tt.pageSize,
+					"autoSplit":            tt.autoSplit,
+				}
+				if token != "" {
+					args["pageToken"] = token
+				}
+				request := mcp.CallToolRequest{
+					Params: mcp.CallToolParams{Name: toolCalculateDistanceMatrix, Arguments: args},
+				}
+				result, err := handler.handleDistanceMatrix(context.Background(), request)
+				if err != nil {
+					t.Fatalf("page %d: unexpected error: %v", pages+1, err)
+				}
+				pages++
+
+				for _, line := range strings.Split(result.Content[0].(mcp.TextContent).Text, "\n") {
+					cell, _, _ := strings.Cut(line, ":")
+					seen[cell]++
+				}
+
+				token = ""
+				if len(result.Content) > 1 {
+					next := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+					token = strings.TrimPrefix(next, "Next pageToken: ")
+				}
+				if token == "" {
+					break
+				}
+				if pages > tt.expectedPages {
+					t.Fatalf("expected %d pages, still paging", tt.expectedPages)
+				}
+			}
+
+			if pages != tt.expectedPages {
+				t.Errorf("expected %d pages, got %d", tt.expectedPages, pages)
+			}
+			if len(seen) != tt.origins*tt.destinations {
+				t.Errorf("expected %d distinct cells, got %d", tt.origins*tt.destinations, len(seen))
+			}
+			for i := range origins {
+				for j := range destinations {
+					cell := fmt.Sprintf("o%d -> d%d", i, j)
+					if seen[cell] != 1 {
+						t.Errorf("expected %s exactly once, got %d", cell, seen[cell])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseMatrixPage(t *testing.T) {
+	origins := syntheticAddresses("o", 5)
+	destinations := syntheticAddresses("d", 2)
+	fingerprint := matrixFingerprint(origins, destinations)
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  matrixPage
+		expectErr string
+	}{
+		{
+			name:     "no paging",
+			args:     map[string]interface{}{},
+			expected: matrixPage{start: 0, end: 5},
+		},
+		{
+			name:     "first page",
+			args:     map[string]interface{}{"pageSize": 2.0},
+			expected: matrixPage{start: 0, end: 2, next: encodePageToken(2, fingerprint)},
+		},
+		{
+			name:     "last page",
+			args:     map[string]interface{}{"pageSize": 2.0, "pageToken": encodePageToken(4, fingerprint)},
+			expected: matrixPage{start: 4, end: 5},
+		},
+		{
+			name:      "token without page size",
+			args:      map[string]interface{}{"pageToken": encodePageToken(2, fingerprint)},
+			expectErr: "pageToken requires pageSize",
+		},
+		{
+			name:      "zero page size",
+			args:      map[string]interface{}{"pageSize": 0.0},
+			expectErr: "invalid pageSize",
+		},
+		{
+			name:      "fractional page size",
+			args:      map[string]interface{}{"pageSize": 1.5},
+			expectErr: "invalid pageSize",
+		},
+		{
+			name:      "malformed token",
+			args:      map[string]interface{}{"pageSize": 2.0, "pageToken": "not a token"},
+			expectErr: "invalid pageToken",
+		},
+		{
+			name:      "token for other addresses",
+			args:      map[string]interface{}{"pageSize": 2.0, "pageToken": encodePageToken(2, matrixFingerprint(origins[:4], destinations))},
+			expectErr: "issued for different addresses",
+		},
+		{
+			name:      "token past the end",
+			args:      map[string]interface{}{"pageSize": 2.0, "pageToken": encodePageToken(5, fingerprint)},
+			expectErr: "past the last origin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistanceMatrix, Arguments: tt.args},
+			}
+			page, err := parseMatrixPage(request, origins, destinations)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if page != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, page)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package geodistanceserver
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	errorCategoryValidation = "validation"
+	errorCategoryNetwork    = "network"
+	errorCategoryAPI        = "api"
+)
+
+// Metrics holds the Prometheus collectors for a handler. A nil *Metrics is
+// valid and records nothing, so metrics stay optional.
+type Metrics struct {
+	calculations    prometheus.Counter
+	errors          *prometheus.CounterVec
+	requestDuration prometheus.Histogram
+}
+
+// NewMetrics creates the handler collectors and registers them on reg.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		calculations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "geodistance_calculations_total",
+			Help: "Total number of distance calculations requested.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "geodistance_errors_total",
+			Help: "Total number of errors by category (validation, network, api).",
+		}, []string{"category"}),
+		requestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "geodistance_api_request_duration_seconds",
+			Help:    "Latency of Routes API requests in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+
+	for _, c := range []prometheus.Collector{m.calculations, m.errors, m.requestDuration} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) incCalculations() {
+	if m == nil {
+		return
+	}
+	m.calculations.Inc()
+}
+
+func (m *Metrics) incError(category string) {
+	if m == nil {
+		return
+	}
+	m.errors.WithLabelValues(category).Inc()
+}
+
+func (m *Metrics) observeRequestDuration(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requestDuration.Observe(d.Seconds())
+}
@@ -0,0 +1,137 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewMetrics_Register(t *testing.T) {
+	reg := prometheus.NewRegistry()
+
+	if _, err := NewMetrics(reg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Registering a second set on the same registry must fail
+	if _, err := NewMetrics(reg); err == nil {
+		t.Error("expected duplicate registration error")
+	}
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	var m *Metrics
+	m.incCalculations()
+	m.incError(errorCategoryAPI)
+	m.observeRequestDuration(0)
+}
+
+func TestGeodistanceHandler_Metrics(t *testing.T) {
+	tests := []struct {
+		name                 string
+		requestArgs          map[string]interface{}
+		mockFunc             func(req *http.Request) (*http.Response, error)
+		expectedErrorCounts  map[string]float64
+		expectedObservations int
+	}{
+		{
+			name: "successful calculation",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expectedErrorCounts:  map[string]float64{},
+			expectedObservations: 1,
+		},
+		{
+			name: "validation error",
+			requestArgs: map[string]interface{}{
+				"originAddress": "New York",
+			},
+			expectedErrorCounts:  map[string]float64{errorCategoryValidation: 1},
+			expectedObservations: 0,
+		},
+		{
+			name: "network error",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return nil, fmt.Errorf("network error")
+			},
+			expectedErrorCounts:  map[string]float64{errorCategoryNetwork: 1},
+			expectedObservations: 1,
+		},
+		{
+			name: "API error",
+			requestArgs: map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Los Angeles",
+			},
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusBadRequest, `{"error": "Invalid request"}`), nil
+			},
+			expectedErrorCounts:  map[string]float64{errorCategoryAPI: 1},
+			expectedObservations: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			metrics, err := NewMetrics(reg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			handler := &GeodistanceHandler{
+				apiKey:  "test-key",
+				client:  &MockHTTPClient{DoFunc: tt.mockFunc},
+				metrics: metrics,
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "calculate_distance",
+					Arguments: tt.requestArgs,
+				},
+			}
+			handler.handleDistanceCalculation(context.Background(), request)
+
+			if got := testutil.ToFloat64(metrics.calculations); got != 1 {
+				t.Errorf("expected 1 calculation, got %v", got)
+			}
+			for _, category := range []string{errorCategoryValidation, errorCategoryNetwork, errorCategoryAPI} {
+				got := testutil.ToFloat64(metrics.errors.WithLabelValues(category))
+				if got != tt.expectedErrorCounts[category] {
+					t.Errorf("expected %v %s errors, got %v", tt.expectedErrorCounts[category], category, got)
+				}
+			}
+			if got := testutil.CollectAndCount(metrics.requestDuration); got != 1 {
+				t.Errorf("expected histogram to be collected, got %d series", got)
+			}
+
+			families, err := reg.Gather()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, family := range families {
+				if family.GetName() == "geodistance_api_request_duration_seconds" {
+					count := family.GetMetric()[0].GetHistogram().GetSampleCount()
+					if int(count) != tt.expectedObservations {
+						t.Errorf("expected %d latency observations, got %d", tt.expectedObservations, count)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package geodistanceserver
+
+import "net/http"
+
+// HTTPClientFunc adapts an ordinary function to HTTPClient.
+type HTTPClientFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f HTTPClientFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the client that sends API requests, for example to add
+// headers, trace calls, or record responses. It returns a client that
+// usually delegates to next.
+type Middleware func(next HTTPClient) HTTPClient
+
+// httpClient returns gh's client wrapped in its middleware, the first of
+// which sees each request first and its response last.
+func (gh *GeodistanceHandler) httpClient() HTTPClient {
+	client := gh.client
+	for i := len(gh.middleware) - 1; i >= 0; i-- {
+		client = gh.middleware[i](client)
+	}
+	return client
+}
@@ -0,0 +1,98 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestWithMiddleware_InjectsHeader(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			if got := req.Header.Get("X-Trace-Id"); got != "trace-1" {
+				t.Errorf("expected X-Trace-Id trace-1, got %q", got)
+			}
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+	WithMiddleware(func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Trace-Id", "trace-1")
+			return next.Do(req)
+		})
+	})(handler)
+
+	if _, err := handler.callDistanceMatrix(context.Background(),
+		[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWithMiddleware_RecordsStatus(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusOK}
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			status := statuses[0]
+			statuses = statuses[1:]
+			resp := createMockResponse(status, createValidAPIResponse())
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}},
+		retry: newThrottleRetry(1, 0),
+	}
+
+	var recorded []int
+	WithMiddleware(func(next HTTPClient) HTTPClient {
+		return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			if err == nil {
+				recorded = append(recorded, resp.StatusCode)
+			}
+			return resp, err
+		})
+	})(handler)
+
+	if _, err := handler.callDistanceMatrix(context.Background(),
+		[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{http.StatusTooManyRequests, http.StatusOK}; !reflect.DeepEqual(recorded, want) {
+		t.Errorf("expected statuses %v for each attempt, got %v", want, recorded)
+	}
+}
+
+func TestWithMiddleware_Order(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next HTTPClient) HTTPClient {
+			return HTTPClientFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" request")
+				resp, err := next.Do(req)
+				order = append(order, name+" response")
+				return resp, err
+			})
+		}
+	}
+
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			order = append(order, "client")
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+	WithMiddleware(trace("first"))(handler)
+	WithMiddleware(trace("second"))(handler)
+
+	if _, err := handler.callDistanceMatrix(context.Background(),
+		[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"first request", "second request", "client", "second response", "first response"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("expected %v, got %v", want, order)
+	}
+}
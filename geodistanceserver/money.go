@@ -0,0 +1,56 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+const nanosPerUnit = 1_000_000_000
+
+// currencyDecimals lists ISO 4217 currencies whose minor unit is not two
+// decimal places. All others are formatted with two decimals.
+var currencyDecimals = map[string]int{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "PYG": 0, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// formatMoney renders a google.type.Money amount, given as whole units plus
+// nanos (10^-9 units) of the same sign, rounded half away from zero to the
+// currency's decimal places, e.g. units=4, nanos=500000000 -> "USD 4.50".
+func formatMoney(currencyCode string, units int64, nanos int32) string {
+	decimals, ok := currencyDecimals[strings.ToUpper(currencyCode)]
+	if !ok {
+		decimals = 2
+	}
+
+	sign := ""
+	if units < 0 || nanos < 0 {
+		sign = "-"
+	}
+	whole := uint64(units)
+	if units < 0 {
+		whole = uint64(-units)
+	}
+	frac := int64(nanos)
+	if frac < 0 {
+		frac = -frac
+	}
+
+	scale := int64(1)
+	for i := 0; i < 9-decimals; i++ {
+		scale *= 10
+	}
+	minor := (frac + scale/2) / scale
+	if limit := nanosPerUnit / scale; minor >= limit {
+		whole++
+		minor -= limit
+	}
+
+	if whole == 0 && minor == 0 {
+		sign = ""
+	}
+	if decimals == 0 {
+		return fmt.Sprintf("%s %s%d", currencyCode, sign, whole)
+	}
+	return fmt.Sprintf("%s %s%d.%0*d", currencyCode, sign, whole, decimals, minor)
+}
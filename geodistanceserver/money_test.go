@@ -0,0 +1,41 @@
+package geodistanceserver
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		units    int64
+		nanos    int32
+		expected string
+	}{
+		{name: "units and nanos", currency: "USD", units: 4, nanos: 500000000, expected: "USD 4.50"},
+		{name: "zero nanos", currency: "USD", units: 4, nanos: 0, expected: "USD 4.00"},
+		{name: "zero amount", currency: "EUR", units: 0, nanos: 0, expected: "EUR 0.00"},
+		{name: "nanos only", currency: "EUR", units: 0, nanos: 10000000, expected: "EUR 0.01"},
+		{name: "rounds half up", currency: "USD", units: 1, nanos: 5000000, expected: "USD 1.01"},
+		{name: "rounds down", currency: "USD", units: 1, nanos: 4999999, expected: "USD 1.00"},
+		{name: "max nanos carries into units", currency: "USD", units: 4, nanos: 999999999, expected: "USD 5.00"},
+		{name: "negative amount", currency: "USD", units: -4, nanos: -500000000, expected: "USD -4.50"},
+		{name: "negative nanos only", currency: "USD", units: 0, nanos: -250000000, expected: "USD -0.25"},
+		{name: "negative rounding to zero", currency: "USD", units: 0, nanos: -1, expected: "USD 0.00"},
+		{name: "zero-decimal currency", currency: "JPY", units: 1200, nanos: 0, expected: "JPY 1200"},
+		{name: "zero-decimal currency rounds", currency: "JPY", units: 1200, nanos: 500000000, expected: "JPY 1201"},
+		{name: "three-decimal currency", currency: "KWD", units: 1, nanos: 234500000, expected: "KWD 1.235"},
+		{name: "lowercase currency code", currency: "jpy", units: 5, nanos: 0, expected: "jpy 5"},
+		{name: "largest units", currency: "USD", units: math.MaxInt64, nanos: 0, expected: "USD 9223372036854775807.00"},
+		{name: "smallest units", currency: "USD", units: math.MinInt64, nanos: 0, expected: "USD -9223372036854775808.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMoney(tt.currency, tt.units, tt.nanos); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
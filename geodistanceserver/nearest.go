@@ -0,0 +1,171 @@
+package geodistanceserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	sortByDistance = "DISTANCE"
+	sortByDuration = "DURATION"
+)
+
+var sortKeys = []string{sortByDistance, sortByDuration}
+
+// parseSortBy reads the sortBy argument of nearest_destination. Giving it
+// implies the ranked list. Ranking by duration needs the durations that
+// distanceOnly leaves out.
+func parseSortBy(request mcp.CallToolRequest, opts routeOptions) (string, error) {
+	sortBy := enumArgument(request, "sortBy", sortByDistance)
+	switch sortBy {
+	case sortByDistance:
+	case sortByDuration:
+		if opts.DistanceOnly {
+			return "", fmt.Errorf("sortBy %s cannot be combined with distanceOnly", sortByDuration)
+		}
+	default:
+		return "", fmt.Errorf("invalid sortBy %q: must be %s or %s", sortBy, sortByDistance, sortByDuration)
+	}
+	return sortBy, nil
+}
+
+// rankedDestination is a reachable destination of a one-to-many matrix.
+type rankedDestination struct {
+	index   int
+	element *MatrixElement
+}
+
+func (gh *GeodistanceHandler) handleNearestDestination(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	ctx = contextWithRequestIDArgument(ctx, request)
+
+	origins, err := requireStringArguments(request, toolNearestDestination, "originAddress")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	lists, err := requireStringSliceArguments(request, toolNearestDestination, "destinationAddresses")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	origin := normalizeAddress(origins[0])
+	destinations := lists[0]
+	for i := range destinations {
+		destinations[i] = normalizeAddress(destinations[i])
+	}
+
+	if err := gh.validateMatrixAddresses([]string{origin}, destinations); err != nil {
+		return nil, invalidArgument(err)
+	}
+	opts, err := gh.parseRouteOptions(request)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := validateMatrixSize(1, len(destinations), opts); err != nil {
+		return nil, invalidArgument(err)
+	}
+	sortBy, err := parseSortBy(request, opts)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	_, sorted := request.GetArguments()["sortBy"]
+
+	elements, err := gh.callRouteMatrix(ctx, []string{origin}, destinations, opts)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := indexMatrix(elements, 1, len(destinations))
+	if err != nil {
+		return nil, err
+	}
+
+	ranked, unreachable := rankDestinations(grid[0], sortBy)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("%w from %s to any destination", ErrNoRoute, origin)
+	}
+
+	headline := "Nearest destination"
+	if sortBy == sortByDuration {
+		headline = "Fastest destination"
+	}
+	first := ranked[0]
+	lines := []string{fmt.Sprintf("%s: %s, %s",
+		headline, destinations[first.index], gh.formatMatrixCell(first.element, opts))}
+	if sorted || request.GetBool("ranked", false) {
+		for i, r := range ranked {
+			lines = append(lines, fmt.Sprintf("%d. %s: %s", i+1, destinations[r.index], gh.formatMatrixCell(r.element, opts)))
+		}
+		for _, i := range unreachable {
+			lines = append(lines, fmt.Sprintf("-  %s: %s", destinations[i], gh.formatMatrixCell(grid[0][i], opts)))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: strings.Join(lines, "\n"),
+			},
+		},
+	}, nil
+}
+
+// rankDestinations orders the reachable cells of a matrix row by distance,
+// breaking ties by duration, or with sortBy DURATION by duration, breaking
+// ties by distance. Remaining ties keep input order, so equal results
+// always rank the same way. Cells without a usable duration rank after
+// those with one when sorting by duration. The indices of unreachable
+// cells are returned separately, in input order.
+func rankDestinations(row []*MatrixElement, sortBy string) (ranked []rankedDestination, unreachable []int) {
+	for i, element := range row {
+		if !elementReachable(element) {
+			unreachable = append(unreachable, i)
+			continue
+		}
+		ranked = append(ranked, rankedDestination{index: i, element: element})
+	}
+
+	byDistance := func(ea, eb *MatrixElement) int {
+		return ea.DistanceMeters - eb.DistanceMeters
+	}
+	byDuration := func(ea, eb *MatrixElement) int {
+		da, errA := parseDuration(ea.Duration)
+		db, errB := parseDuration(eb.Duration)
+		switch {
+		case errA == nil && errB == nil:
+			return cmp.Compare(da, db)
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		}
+		return 0
+	}
+	primary, secondary := byDistance, byDuration
+	if sortBy == sortByDuration {
+		primary, secondary = byDuration, byDistance
+	}
+
+	slices.SortStableFunc(ranked, func(a, b rankedDestination) int {
+		if c := primary(a.element, b.element); c != 0 {
+			return c
+		}
+		return secondary(a.element, b.element)
+	})
+	return ranked, unreachable
+}
+
+// elementReachable reports whether a matrix cell holds a usable route.
+func elementReachable(element *MatrixElement) bool {
+	if element == nil || element.Condition != conditionRouteExists {
+		return false
+	}
+	_, ok := elementStatusMessage(element.Status)
+	return ok
+}
@@ -0,0 +1,233 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Destination 1 is unreachable; destinations 0 and 2 tie on distance and
+// 2 wins on duration.
+const nearestMatrixResponse = `[
+  {"originIndex": 0, "destinationIndex": 1, "status": {}, "condition": "ROUTE_NOT_FOUND"},
+  {"originIndex": 0, "destinationIndex": 3, "status": {}, "distanceMeters": 9000, "duration": "600s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 0, "destinationIndex": 0, "status": {}, "distanceMeters": 2500, "duration": "400s", "condition": "ROUTE_EXISTS"},
+  {"originIndex": 0, "destinationIndex": 2, "status": {}, "distanceMeters": 2500, "duration": "300s", "condition": "ROUTE_EXISTS"}
+]`
+
+func TestRankDestinations(t *testing.T) {
+	reachable := func(meters int, duration string) *MatrixElement {
+		return &MatrixElement{DistanceMeters: meters, Duration: duration, Condition: conditionRouteExists}
+	}
+
+	tests := []struct {
+		name        string
+		row         []*MatrixElement
+		sortBy      string
+		order       []int
+		unreachable []int
+	}{
+		{
+			name:  "by distance",
+			row:   []*MatrixElement{reachable(3000, "60s"), reachable(1000, "60s"), reachable(2000, "60s")},
+			order: []int{1, 2, 0},
+		},
+		{
+			name:  "distance tie broken by duration",
+			row:   []*MatrixElement{reachable(1000, "90s"), reachable(1000, "60s")},
+			order: []int{1, 0},
+		},
+		{
+			name:  "full tie keeps input order",
+			row:   []*MatrixElement{reachable(1000, "60s"), reachable(1000, "60s"), reachable(1000, "60s")},
+			order: []int{0, 1, 2},
+		},
+		{
+			name:   "by duration",
+			row:    []*MatrixElement{reachable(1000, "300s"), reachable(3000, "60s"), reachable(2000, "120s")},
+			sortBy: sortByDuration,
+			order:  []int{1, 2, 0},
+		},
+		{
+			name:   "duration tie broken by distance",
+			row:    []*MatrixElement{reachable(2000, "60s"), reachable(1000, "60s")},
+			sortBy: sortByDuration,
+			order:  []int{1, 0},
+		},
+		{
+			name:   "duration full tie keeps input order",
+			row:    []*MatrixElement{reachable(1000, "60s"), reachable(1000, "60s"), reachable(1000, "60s")},
+			sortBy: sortByDuration,
+			order:  []int{0, 1, 2},
+		},
+		{
+			name:   "missing duration ranks last",
+			row:    []*MatrixElement{reachable(1000, ""), reachable(5000, "600s")},
+			sortBy: sortByDuration,
+			order:  []int{1, 0},
+		},
+		{
+			name:        "unreachable excluded",
+			row:         []*MatrixElement{nil, reachable(1000, "60s"), {Condition: "ROUTE_NOT_FOUND"}},
+			order:       []int{1},
+			unreachable: []int{0, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked, unreachable := rankDestinations(tt.row, tt.sortBy)
+
+			if len(ranked) != len(tt.order) {
+				t.Fatalf("expected %d ranked destinations, got %d", len(tt.order), len(ranked))
+			}
+			for i, index := range tt.order {
+				if ranked[i].index != index {
+					t.Errorf("rank %d: expected destination %d, got %d", i+1, index, ranked[i].index)
+				}
+			}
+			if len(unreachable) != len(tt.unreachable) {
+				t.Fatalf("expected unreachable %v, got %v", tt.unreachable, unreachable)
+			}
+			for i := range unreachable {
+				if unreachable[i] != tt.unreachable[i] {
+					t.Errorf("expected unreachable %v, got %v", tt.unreachable, unreachable)
+				}
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleNearestDestination(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		response     string
+		expectErr    bool
+		expectedText string
+	}{
+		{
+			name: "nearest only",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Depot", "Island", "Store", "Airport"},
+			},
+			response:     nearestMatrixResponse,
+			expectedText: "Nearest destination: Store, 2.50 km (2500 meters), Duration: 5m0s",
+		},
+		{
+			name: "ranked",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Depot", "Island", "Store", "Airport"},
+				"ranked":               true,
+			},
+			response: nearestMatrixResponse,
+			expectedText: strings.Join([]string{
+				"Nearest destination: Store, 2.50 km (2500 meters), Duration: 5m0s",
+				"1. Store: 2.50 km (2500 meters), Duration: 5m0s",
+				"2. Depot: 2.50 km (2500 meters), Duration: 6m40s",
+				"3. Airport: 9.00 km (9000 meters), Duration: 10m0s",
+				"-  Island: no route available",
+			}, "\n"),
+		},
+		{
+			name: "sorted by duration",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Far", "Slow", "Near"},
+				"sortBy":               "duration",
+			},
+			response: `[
+				{"originIndex": 0, "destinationIndex": 0, "status": {}, "distanceMeters": 5000, "duration": "120s", "condition": "ROUTE_EXISTS"},
+				{"originIndex": 0, "destinationIndex": 1, "status": {}, "distanceMeters": 1000, "duration": "600s", "condition": "ROUTE_EXISTS"},
+				{"originIndex": 0, "destinationIndex": 2, "status": {}, "distanceMeters": 3000, "duration": "120s", "condition": "ROUTE_EXISTS"}
+			]`,
+			expectedText: strings.Join([]string{
+				"Fastest destination: Near, 3.00 km (3000 meters), Duration: 2m0s",
+				"1. Near: 3.00 km (3000 meters), Duration: 2m0s",
+				"2. Far: 5.00 km (5000 meters), Duration: 2m0s",
+				"3. Slow: 1.00 km (1000 meters), Duration: 10m0s",
+			}, "\n"),
+		},
+		{
+			name: "sorted by distance",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Depot", "Island", "Store", "Airport"},
+				"sortBy":               "DISTANCE",
+			},
+			response: nearestMatrixResponse,
+			expectedText: strings.Join([]string{
+				"Nearest destination: Store, 2.50 km (2500 meters), Duration: 5m0s",
+				"1. Store: 2.50 km (2500 meters), Duration: 5m0s",
+				"2. Depot: 2.50 km (2500 meters), Duration: 6m40s",
+				"3. Airport: 9.00 km (9000 meters), Duration: 10m0s",
+				"-  Island: no route available",
+			}, "\n"),
+		},
+		{
+			name: "invalid sortBy",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Depot"},
+				"sortBy":               "rating",
+			},
+			expectErr: true,
+		},
+		{
+			name: "duration without durations",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Depot"},
+				"sortBy":               "duration",
+				"distanceOnly":         true,
+			},
+			expectErr: true,
+		},
+		{
+			name: "no destination reachable",
+			args: map[string]interface{}{
+				"destinationAddresses": []interface{}{"Island"},
+			},
+			response:  `[{"originIndex": 0, "destinationIndex": 0, "status": {}, "condition": "ROUTE_NOT_FOUND"}]`,
+			expectErr: true,
+		},
+		{
+			name:      "missing destinations",
+			args:      map[string]interface{}{},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(http.StatusOK, tt.response), nil
+				}},
+			}
+
+			args := map[string]interface{}{"originAddress": "Home"}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolNearestDestination, Arguments: args},
+			}
+
+			result, err := handler.handleNearestDestination(context.Background(), request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != tt.expectedText {
+				t.Errorf("expected text:\n%s\ngot:\n%s", tt.expectedText, text)
+			}
+		})
+	}
+}
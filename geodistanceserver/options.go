@@ -0,0 +1,280 @@
+package geodistanceserver
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Option configures optional GeodistanceHandler behavior.
+type Option func(*GeodistanceHandler)
+
+// WithAPIKey sets the Google API key, so GOOGLE_API_KEY, GOOGLE_API_KEYS
+// and GOOGLE_API_KEY_FILE are not consulted.
+func WithAPIKey(apiKey string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.setAPIKeys([]string{apiKey})
+	}
+}
+
+// WithAPIKeys sets several Google API keys, used round-robin across
+// requests. A request throttled under one key is retried at once with the
+// next, until every key has been tried. Blank entries are ignored.
+func WithAPIKeys(keys ...string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.setAPIKeys(parseAPIKeys(strings.Join(keys, ",")))
+	}
+}
+
+// WithHTTPClient replaces the default HTTP client. WithTimeout and
+// GEODISTANCE_TIMEOUT do not apply to a supplied client.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.client = client
+	}
+}
+
+// WithMiddleware wraps every API request in middleware, applied in order:
+// the first middleware sees each request first. Retries pass through the
+// middleware again. Repeated calls append.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.middleware = append(gh.middleware, middleware...)
+	}
+}
+
+// WithBaseURL overrides the Routes API endpoint, e.g. to target a mock
+// server, a proxy, or a regional endpoint.
+func WithBaseURL(url string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.baseURL = url
+	}
+}
+
+// WithRoutesURL overrides the computeRoutes endpoint used for trips with
+// intermediate waypoints.
+func WithRoutesURL(url string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.routesURL = url
+	}
+}
+
+// WithGeocodeURL overrides the Geocoding API endpoint.
+func WithGeocodeURL(url string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.geocodeURL = url
+	}
+}
+
+// WithLogger enables structured logging of outgoing API calls. A nil
+// logger disables logging.
+func WithLogger(logger *slog.Logger) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.logger = logger
+	}
+}
+
+// WithTimeout sets the timeout of the default HTTP client built by
+// NewGeodistanceHandler, taking precedence over GEODISTANCE_TIMEOUT. It has
+// no effect on clients supplied via WithHTTPClient or
+// NewGeodistanceHandlerWithClient.
+func WithTimeout(timeout time.Duration) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.timeout = timeout
+	}
+}
+
+// WithConnectionPool tunes the idle connections kept by the default HTTP
+// client built by NewGeodistanceHandler: in total, per host, and how long
+// an idle connection is kept open. Non-positive values keep the defaults
+// of 100, 8 and 90s. It has no effect on clients supplied via
+// WithHTTPClient or NewGeodistanceHandlerWithClient.
+func WithConnectionPool(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.pool = connectionPool{
+			maxIdleConns:        maxIdleConns,
+			maxIdleConnsPerHost: maxIdleConnsPerHost,
+			idleConnTimeout:     idleConnTimeout,
+		}
+	}
+}
+
+// WithMetrics records Prometheus metrics for calculations, errors, and
+// API latency. Create m with NewMetrics.
+func WithMetrics(m *Metrics) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.metrics = m
+	}
+}
+
+// WithCache caches up to size route lookups in memory for ttl, so repeated
+// identical requests do not spend API quota. A non-positive size disables
+// caching; a non-positive ttl keeps entries until they are evicted.
+func WithCache(size int, ttl time.Duration) Option {
+	return func(gh *GeodistanceHandler) {
+		if size <= 0 {
+			gh.cache = nil
+			return
+		}
+		gh.cache = newRouteCache(size, ttl)
+	}
+}
+
+// WithCacheFile persists the cache enabled by WithCache to path, so routes
+// computed by one process are served to the next. The file is loaded when
+// the handler is created and rewritten on every update; entries past their
+// TTL are discarded on load. A corrupt file is ignored and rebuilt. Without
+// WithCache this option has no effect.
+func WithCacheFile(path string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.cacheFile = path
+	}
+}
+
+// WithMaxResponseSize caps how many bytes of an API response body are read;
+// larger responses fail with ErrResponseTooLarge. A non-positive size keeps
+// the default of 4 MiB.
+func WithMaxResponseSize(size int64) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.maxResponseBytes = size
+	}
+}
+
+// WithStrictDecoding rejects API responses containing fields the response
+// types do not declare, which helps catch API changes during development.
+func WithStrictDecoding(strict bool) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.strictDecoding = strict
+	}
+}
+
+// WithStrictArguments makes every tool reject arguments its schema does
+// not declare, such as a misspelled originAddress, instead of ignoring
+// them. It overrides GEODISTANCE_STRICT_ARGUMENTS.
+func WithStrictArguments(strict bool) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.strictArguments = strict
+	}
+}
+
+// WithStrictZeroDistance makes a zero-meter route between different places
+// fail with ErrSuspiciousZeroDistance instead of being reported. It is off
+// by default, since nearby coordinates can legitimately route to zero.
+func WithStrictZeroDistance(strict bool) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.strictZero = strict
+	}
+}
+
+// WithMaxRoutes sets how many routes of a response are processed and
+// reported; any further routes are ignored. A non-positive value keeps the
+// default of 10.
+func WithMaxRoutes(routes int) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.maxRoutes = routes
+	}
+}
+
+// WithMaxCSVRows sets how many origin/destination rows
+// calculate_distances_csv accepts in one call. A non-positive value keeps
+// the default of 100.
+func WithMaxCSVRows(rows int) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.maxCSVRows = rows
+	}
+}
+
+// WithMaxAddressLength sets the longest address, in characters, accepted
+// for any origin, destination, waypoint or geocoded address. A non-positive
+// value keeps the default of 512.
+func WithMaxAddressLength(chars int) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.maxAddressLen = chars
+	}
+}
+
+// WithUserAgent overrides the User-Agent sent with API requests, which
+// defaults to mcp-geodistance-server/<Version>.
+func WithUserAgent(userAgent string) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.userAgent = userAgent
+	}
+}
+
+// WithIdenticalAddressBehavior sets how requests whose origin equals their
+// destination are handled. The default is IdenticalAddressesReject.
+func WithIdenticalAddressBehavior(behavior IdenticalAddressBehavior) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.identicalAddresses = behavior
+	}
+}
+
+// WithMissingTrafficBehavior sets how traffic-aware requests answered
+// without traffic-adjusted durations are handled. The default is
+// MissingTrafficLabel.
+func WithMissingTrafficBehavior(behavior MissingTrafficBehavior) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.missingTraffic = behavior
+	}
+}
+
+// WithRateLimit caps outgoing Routes API calls at qps requests per second,
+// allowing bursts of up to burst calls. Calls wait for a token and fail if
+// their context ends first. A non-positive qps disables rate limiting.
+func WithRateLimit(qps float64, burst int) Option {
+	return func(gh *GeodistanceHandler) {
+		if qps <= 0 {
+			gh.limiter = nil
+			return
+		}
+		gh.limiter = rate.NewLimiter(rate.Limit(qps), max(burst, 1))
+	}
+}
+
+// WithProvider replaces the Google Routes API for single-route requests,
+// e.g. with NewMapboxProvider, so GEODISTANCE_PROVIDER is not consulted.
+// Matrix and geocoding tools keep using Google.
+func WithProvider(provider Provider) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.provider = provider
+	}
+}
+
+// WithFallbackProvider sets a provider to use when the primary provider
+// fails with a server error.
+func WithFallbackProvider(provider Provider) Option {
+	return func(gh *GeodistanceHandler) {
+		gh.fallback = provider
+	}
+}
+
+// WithThrottleRetry sets how often a call answered with 429 Too Many
+// Requests is retried, waiting as long as its Retry-After header asks, up
+// to maxDelay, or with exponential backoff when it has none. The default is
+// 2 retries waiting at most 10s; a non-positive maxRetries disables
+// retrying and a non-positive maxDelay keeps the 10s cap.
+func WithThrottleRetry(maxRetries int, maxDelay time.Duration) Option {
+	return func(gh *GeodistanceHandler) {
+		if maxRetries <= 0 {
+			gh.retry = nil
+			return
+		}
+		gh.retry = newThrottleRetry(maxRetries, maxDelay)
+	}
+}
+
+// WithCircuitBreaker stops calling the Routes API for cooldown after
+// threshold consecutive server or network failures, failing calls with
+// ErrCircuitOpen meanwhile. After the cooldown one probe call decides
+// whether to resume. A non-positive threshold disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(gh *GeodistanceHandler) {
+		if threshold <= 0 {
+			gh.breaker = nil
+			return
+		}
+		gh.breaker = newCircuitBreaker(threshold, cooldown)
+	}
+}
@@ -0,0 +1,299 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Method != "POST" {
+			t.Errorf("expected POST method, got %s", r.Method)
+		}
+		if r.Header.Get("X-Goog-Api-Key") != "test-key" {
+			t.Error("API key header not forwarded")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(createValidAPIResponse()))
+	}))
+	defer server.Close()
+
+	os.Setenv("GOOGLE_API_KEY", "test-key")
+	defer os.Unsetenv("GOOGLE_API_KEY")
+
+	handler, err := NewGeodistanceHandlerWithClient(server.Client(), WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.baseURL != server.URL {
+		t.Errorf("expected base URL %s, got %s", server.URL, handler.baseURL)
+	}
+
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Los Angeles"}}
+
+	result, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 request to reach the test server, got %d", hits)
+	}
+	if len(result.Routes) != 1 || result.Routes[0].DistanceMeters != 1000 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	client := &MockHTTPClient{}
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	tests := []struct {
+		name   string
+		option Option
+		check  func(gh *GeodistanceHandler) bool
+	}{
+		{"WithAPIKey", WithAPIKey("option-key"), func(gh *GeodistanceHandler) bool { return gh.apiKey == "option-key" }},
+		{"WithAPIKeys", WithAPIKeys("a", " ", "b"), func(gh *GeodistanceHandler) bool {
+			return gh.apiKey == "a" && gh.apiKeys != nil && slices.Equal(gh.apiKeys.keys, []string{"a", "b"})
+		}},
+		{"WithStrictArguments", WithStrictArguments(true), func(gh *GeodistanceHandler) bool { return gh.strictArguments }},
+		{"WithHTTPClient", WithHTTPClient(client), func(gh *GeodistanceHandler) bool { return gh.client == client }},
+		{"WithBaseURL", WithBaseURL("http://matrix.test"), func(gh *GeodistanceHandler) bool { return gh.baseURL == "http://matrix.test" }},
+		{"WithRoutesURL", WithRoutesURL("http://routes.test"), func(gh *GeodistanceHandler) bool { return gh.routesURL == "http://routes.test" }},
+		{"WithGeocodeURL", WithGeocodeURL("http://geocode.test"), func(gh *GeodistanceHandler) bool { return gh.geocodeURL == "http://geocode.test" }},
+		{"WithLogger", WithLogger(logger), func(gh *GeodistanceHandler) bool { return gh.logger == logger }},
+		{"WithTimeout", WithTimeout(5 * time.Second), func(gh *GeodistanceHandler) bool { return gh.timeout == 5*time.Second }},
+		{"WithConnectionPool", WithConnectionPool(10, 5, time.Minute), func(gh *GeodistanceHandler) bool { return gh.pool.maxIdleConnsPerHost == 5 }},
+		{"WithCache", WithCache(10, time.Minute), func(gh *GeodistanceHandler) bool { return gh.cache != nil }},
+		{"WithCacheFile", WithCacheFile("routes.json"), func(gh *GeodistanceHandler) bool { return gh.cacheFile == "routes.json" }},
+		{"WithMiddleware", WithMiddleware(func(next HTTPClient) HTTPClient { return next }), func(gh *GeodistanceHandler) bool { return len(gh.middleware) == 1 }},
+		{"WithStrictZeroDistance", WithStrictZeroDistance(true), func(gh *GeodistanceHandler) bool { return gh.strictZero }},
+		{"WithRateLimit", WithRateLimit(5, 1), func(gh *GeodistanceHandler) bool { return gh.limiter != nil }},
+		{"WithCircuitBreaker", WithCircuitBreaker(5, time.Minute), func(gh *GeodistanceHandler) bool { return gh.breaker != nil }},
+		{"WithUserAgent", WithUserAgent("agent/1"), func(gh *GeodistanceHandler) bool { return gh.userAgent == "agent/1" }},
+		{"WithMaxResponseSize", WithMaxResponseSize(1024), func(gh *GeodistanceHandler) bool { return gh.maxResponseBytes == 1024 }},
+		{"WithMaxRoutes", WithMaxRoutes(3), func(gh *GeodistanceHandler) bool { return gh.maxRoutes == 3 }},
+		{"WithStrictDecoding", WithStrictDecoding(true), func(gh *GeodistanceHandler) bool { return gh.strictDecoding }},
+		{"WithMaxCSVRows", WithMaxCSVRows(7), func(gh *GeodistanceHandler) bool { return gh.maxCSVRows == 7 }},
+		{"WithMaxAddressLength", WithMaxAddressLength(64), func(gh *GeodistanceHandler) bool { return gh.maxAddressLen == 64 }},
+		{"WithProvider", WithProvider(&mockProvider{}), func(gh *GeodistanceHandler) bool {
+			_, ok := gh.provider.(*mockProvider)
+			return ok
+		}},
+		{"WithThrottleRetry", WithThrottleRetry(4, time.Second), func(gh *GeodistanceHandler) bool {
+			return gh.retry != nil && gh.retry.maxRetries == 4 && gh.retry.maxDelay == time.Second
+		}},
+		{
+			"WithIdenticalAddressBehavior",
+			WithIdenticalAddressBehavior(IdenticalAddressesZeroDistance),
+			func(gh *GeodistanceHandler) bool { return gh.identicalAddresses == IdenticalAddressesZeroDistance },
+		},
+		{
+			"WithMissingTrafficBehavior",
+			WithMissingTrafficBehavior(MissingTrafficRetryUnaware),
+			func(gh *GeodistanceHandler) bool { return gh.missingTraffic == MissingTrafficRetryUnaware },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewGeodistanceHandler(WithAPIKey("test-key"), tt.option)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.check(handler) {
+				t.Errorf("%s did not configure the handler: %+v", tt.name, handler)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandler_WithAPIKey(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY_FILE", "")
+
+	if _, err := NewGeodistanceHandler(); !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("expected ErrMissingAPIKey without a key, got %v", err)
+	}
+
+	handler, err := NewGeodistanceHandler(WithAPIKey("option-key"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.apiKey != "option-key" {
+		t.Errorf("expected option key, got %q", handler.apiKey)
+	}
+}
+
+func TestNewGeodistanceHandler_WithHTTPClient(t *testing.T) {
+	t.Setenv("GEODISTANCE_TIMEOUT", "5s")
+
+	custom := &http.Client{Timeout: time.Minute}
+	handler, err := NewGeodistanceHandler(WithAPIKey("test-key"), WithHTTPClient(custom), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.client != custom {
+		t.Fatal("expected the supplied client")
+	}
+	if custom.Timeout != time.Minute {
+		t.Errorf("supplied client timeout should be left alone, got %v", custom.Timeout)
+	}
+}
+
+func TestNewGeodistanceHandlerWithClient_DefaultBaseURL(t *testing.T) {
+	os.Setenv("GOOGLE_API_KEY", "test-key")
+	defer os.Unsetenv("GOOGLE_API_KEY")
+
+	handler, err := NewGeodistanceHandlerWithClient(&MockHTTPClient{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.baseURL != defaultBaseURL {
+		t.Errorf("expected default base URL %s, got %s", defaultBaseURL, handler.baseURL)
+	}
+}
+
+func TestNewGeodistanceHandler_Timeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		envValue        string
+		opts            []Option
+		expectedTimeout time.Duration
+		expectWarning   bool
+	}{
+		{
+			name:            "default timeout",
+			expectedTimeout: defaultTimeout,
+		},
+		{
+			name:            "valid environment value",
+			envValue:        "5s",
+			expectedTimeout: 5 * time.Second,
+		},
+		{
+			name:            "invalid environment value falls back",
+			envValue:        "soon",
+			expectedTimeout: defaultTimeout,
+			expectWarning:   true,
+		},
+		{
+			name:            "non-positive environment value falls back",
+			envValue:        "-1s",
+			expectedTimeout: defaultTimeout,
+			expectWarning:   true,
+		},
+		{
+			name:            "option overrides environment",
+			envValue:        "5s",
+			opts:            []Option{WithTimeout(2 * time.Minute)},
+			expectedTimeout: 2 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("GOOGLE_API_KEY", "test-key")
+			defer os.Unsetenv("GOOGLE_API_KEY")
+			if tt.envValue != "" {
+				os.Setenv("GEODISTANCE_TIMEOUT", tt.envValue)
+				defer os.Unsetenv("GEODISTANCE_TIMEOUT")
+			}
+
+			var buf bytes.Buffer
+			opts := append([]Option{WithLogger(slog.New(slog.NewTextHandler(&buf, nil)))}, tt.opts...)
+
+			handler, err := NewGeodistanceHandler(opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			client, ok := handler.client.(*http.Client)
+			if !ok {
+				t.Fatalf("expected *http.Client, got %T", handler.client)
+			}
+			if client.Timeout != tt.expectedTimeout {
+				t.Errorf("expected timeout %v, got %v", tt.expectedTimeout, client.Timeout)
+			}
+
+			warned := strings.Contains(buf.String(), "GEODISTANCE_TIMEOUT")
+			if warned != tt.expectWarning {
+				t.Errorf("expected warning=%v, log output: %q", tt.expectWarning, buf.String())
+			}
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	newHandler := func(opts ...Option) (*GeodistanceHandler, *int) {
+		calls := 0
+		handler := &GeodistanceHandler{
+			apiKey: "test-key",
+			client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				calls++
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			}},
+		}
+		for _, opt := range opts {
+			opt(handler)
+		}
+		return handler, &calls
+	}
+	origins := []Origin{{Address: "New York"}}
+	destinations := []Destination{{Address: "Boston"}}
+
+	t.Run("sequential calls are spaced out", func(t *testing.T) {
+		handler, calls := newHandler(WithRateLimit(20, 1))
+
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if _, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		// The burst token covers the first call; the next two wait ~50ms each
+		if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+			t.Errorf("expected calls to be spaced out, 3 calls took %s", elapsed)
+		}
+		if *calls != 3 {
+			t.Errorf("expected 3 API calls, got %d", *calls)
+		}
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		handler, calls := newHandler(WithRateLimit(0.1, 1))
+
+		if _, err := handler.callDistanceMatrix(context.Background(), origins, destinations, routeOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if _, err := handler.callDistanceMatrix(ctx, origins, destinations, routeOptions{}); err == nil {
+			t.Error("expected error when the context is canceled during the wait")
+		}
+		if *calls != 1 {
+			t.Errorf("expected the rate-limited call not to reach the API, got %d calls", *calls)
+		}
+	})
+
+	t.Run("disabled by non-positive qps", func(t *testing.T) {
+		handler, _ := newHandler(WithRateLimit(0, 1))
+		if handler.limiter != nil {
+			t.Error("expected rate limiting to be disabled")
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// pingOrigin and pingDestination are two fixed points a few hundred meters
+// apart, so the probe never depends on geocoding an address.
+var (
+	pingOrigin      = LatLng{Latitude: 37.4220, Longitude: -122.0841}
+	pingDestination = LatLng{Latitude: 37.4250, Longitude: -122.0800}
+)
+
+const pingFieldMask = "routes.distanceMeters"
+
+// Ping verifies that the Routes API is reachable and accepts the configured
+// key by requesting a short traffic-unaware route. A rejected key is
+// reported as ErrUnauthorized.
+func (gh *GeodistanceHandler) Ping(ctx context.Context) error {
+	body := &ComputeRoutesRequestBody{
+		Origin:            Waypoint{Location: &Location{LatLng: pingOrigin}},
+		Destination:       Waypoint{Location: &Location{LatLng: pingDestination}},
+		TravelMode:        travelModeDrive,
+		RoutingPreference: routingPreferenceTrafficUnaware,
+		LanguageCode:      defaultLanguageCode,
+	}
+
+	endpoint := gh.routesURL
+	if endpoint == "" {
+		endpoint = defaultRoutesURL
+	}
+	req, err := gh.newAPIRequest(ctx, endpoint, body, pingFieldMask)
+	if err != nil {
+		return err
+	}
+
+	resp, err := gh.do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	isAuthFailure := resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+	if _, err := gh.processResponse(resp); err != nil {
+		if isAuthFailure {
+			return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+		}
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	return nil
+}
+
+func (gh *GeodistanceHandler) handlePing(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	if err := gh.Ping(ctx); err != nil {
+		return nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{
+				Type: "text",
+				Text: "OK: Routes API reachable and API key accepted",
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,103 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_Ping(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockFunc         func(req *http.Request) (*http.Response, error)
+		expectErr        bool
+		expectAuthFailed bool
+	}{
+		{
+			name: "success",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":512}]}`), nil
+			},
+		},
+		{
+			name: "auth failure",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusForbidden,
+					`{"error":{"code":403,"message":"The provided API key is invalid.","status":"PERMISSION_DENIED"}}`), nil
+			},
+			expectErr:        true,
+			expectAuthFailed: true,
+		},
+		{
+			name: "server error",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusInternalServerError, "internal error"), nil
+			},
+			expectErr: true,
+		},
+		{
+			name: "no route",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, `{}`), nil
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.String() != defaultRoutesURL {
+						t.Errorf("expected ping to call %s, got %s", defaultRoutesURL, req.URL)
+					}
+					var body ComputeRoutesRequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if body.Origin.Location == nil || body.Destination.Location == nil {
+						t.Error("expected ping to use fixed coordinates")
+					}
+					return tt.mockFunc(req)
+				}},
+			}
+
+			err := handler.Ping(context.Background())
+
+			if !tt.expectErr {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+			if errors.Is(err, ErrUnauthorized) != tt.expectAuthFailed {
+				t.Errorf("expected ErrUnauthorized=%v, got %v", tt.expectAuthFailed, err)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handlePing(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, `{"routes":[{"distanceMeters":512}]}`), nil
+		}},
+	}
+
+	result, err := handler.handlePing(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Content) != 1 {
+		t.Errorf("expected 1 content item, got %d", len(result.Content))
+	}
+}
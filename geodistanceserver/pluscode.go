@@ -0,0 +1,99 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Open Location Code (Plus Code) format constants, per
+// https://github.com/google/open-location-code/blob/main/docs/specification.md
+const (
+	plusCodeAlphabet          = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator         = '+'
+	plusCodePadding           = '0'
+	plusCodeSeparatorPosition = 8
+)
+
+// validatePlusCode checks that code is a full Plus Code such as
+// "849VCWC8+R9" or a short one such as "CWC8+R9", ignoring case. Short codes
+// are only meaningful with a nearby locality, which the Routes API geocodes
+// along with them.
+func validatePlusCode(code string) error {
+	upper := strings.ToUpper(code)
+
+	separator := strings.IndexByte(upper, plusCodeSeparator)
+	if separator < 0 || separator != strings.LastIndexByte(upper, plusCodeSeparator) {
+		return fmt.Errorf("invalid plus code %q: must contain exactly one %c", code, plusCodeSeparator)
+	}
+	if separator < 2 || separator > plusCodeSeparatorPosition || separator%2 != 0 {
+		return fmt.Errorf("invalid plus code %q: %c must follow 2, 4, 6 or 8 characters", code, plusCodeSeparator)
+	}
+	if len(upper)-separator-1 == 1 {
+		return fmt.Errorf("invalid plus code %q: a single character after %c is not allowed", code, plusCodeSeparator)
+	}
+
+	digits := upper[:separator]
+	if padding := strings.IndexByte(digits, plusCodePadding); padding >= 0 {
+		switch {
+		case separator < plusCodeSeparatorPosition:
+			return fmt.Errorf("invalid plus code %q: short codes cannot be padded", code)
+		case padding%2 != 0 || strings.Trim(digits[padding:], string(plusCodePadding)) != "":
+			return fmt.Errorf("invalid plus code %q: padding must be whole pairs of %c at the end", code, plusCodePadding)
+		case separator != len(upper)-1:
+			return fmt.Errorf("invalid plus code %q: padded codes cannot have characters after %c", code, plusCodeSeparator)
+		}
+		digits = digits[:padding]
+	}
+
+	for _, c := range digits + upper[separator+1:] {
+		if !strings.ContainsRune(plusCodeAlphabet, c) {
+			return fmt.Errorf("invalid plus code %q: invalid character %q", code, c)
+		}
+	}
+
+	// The first pair of a full code encodes 20-degree steps of latitude
+	// and longitude, so it cannot exceed 90 and 180 degrees
+	if separator == plusCodeSeparatorPosition {
+		if strings.IndexByte(plusCodeAlphabet, upper[0])*20 >= 180 {
+			return fmt.Errorf("invalid plus code %q: latitude out of range", code)
+		}
+		if strings.IndexByte(plusCodeAlphabet, upper[1])*20 >= 360 {
+			return fmt.Errorf("invalid plus code %q: longitude out of range", code)
+		}
+	}
+	return nil
+}
+
+// leadingPlusCode returns the first word of address if it is shaped like a
+// Plus Code: plus code characters around a separator following at least two
+// of them, as in "CWC8+R9, Mountain View". Such addresses are sent to the API
+// as is.
+func leadingPlusCode(address string) (string, bool) {
+	fields := strings.Fields(address)
+	if len(fields) == 0 {
+		return "", false
+	}
+	word := strings.TrimRight(fields[0], ",")
+	if strings.IndexByte(word, plusCodeSeparator) < 2 {
+		return "", false
+	}
+	for _, c := range strings.ToUpper(word) {
+		if c != plusCodeSeparator && c != plusCodePadding && !strings.ContainsRune(plusCodeAlphabet, c) {
+			return "", false
+		}
+	}
+	return word, true
+}
+
+// validatePlusCodeAddress rejects an address that starts with a malformed
+// Plus Code before it spends API quota. Other addresses are accepted.
+func validatePlusCodeAddress(label, address string) error {
+	code, ok := leadingPlusCode(address)
+	if !ok {
+		return nil
+	}
+	if err := validatePlusCode(code); err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidatePlusCode(t *testing.T) {
+	tests := []struct {
+		code      string
+		expectErr string
+	}{
+		{code: "849VCWC8+R9"},
+		{code: "849vcwc8+r9"},
+		{code: "849VCWC8+R9J"},
+		{code: "849VCWC8+"},
+		{code: "CWC8+R9"},
+		{code: "8FVC0000+"},
+		{code: "8F000000+"},
+		{code: "849VCWC8R9", expectErr: "exactly one +"},
+		{code: "849V+CWC8+R9", expectErr: "exactly one +"},
+		{code: "849VCWC+8R9", expectErr: "must follow 2, 4, 6 or 8 characters"},
+		{code: "849VCWC8R+9", expectErr: "must follow 2, 4, 6 or 8 characters"},
+		{code: "849VCWC8+R", expectErr: "single character"},
+		{code: "CWC800+", expectErr: "short codes cannot be padded"},
+		{code: "8FV00000+", expectErr: "whole pairs"},
+		{code: "8F00C000+", expectErr: "whole pairs"},
+		{code: "8FVC0000+22", expectErr: "padded codes"},
+		{code: "849VCWCA+R9", expectErr: "invalid character"},
+		{code: "849VCWC8+R1", expectErr: "invalid character"},
+		{code: "X49VCWC8+R9", expectErr: "latitude out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := validatePlusCode(tt.code)
+			if tt.expectErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+				t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestValidatePlusCodeAddress(t *testing.T) {
+	tests := []struct {
+		address   string
+		expectErr bool
+	}{
+		{address: "849VCWC8+R9"},
+		{address: "CWC8+R9 Mountain View, CA"},
+		{address: "CWC8+R9, Mountain View, CA"},
+		{address: "1600 Amphitheatre Parkway, Mountain View, CA"},
+		{address: "C++ Users Group, Berlin"},
+		{address: "3+2 Apartments"},
+		{address: "849VCWC8+R Mountain View", expectErr: true},
+		{address: "CWC+8R9 Mountain View", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := validatePlusCodeAddress("origin address", tt.address)
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_PlusCodes(t *testing.T) {
+	var sent RequestBody
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+				return nil, err
+			}
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: toolCalculateDistance,
+			Arguments: map[string]interface{}{
+				"originAddress":      "849VCWC8+R9",
+				"destinationAddress": "CWF6+FX Mountain View, CA",
+			},
+		},
+	}
+	if _, err := handler.handleDistanceCalculation(context.Background(), request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent.Origins[0].Address != "849VCWC8+R9" || sent.Destinations[0].Address != "CWF6+FX Mountain View, CA" {
+		t.Errorf("expected plus codes to be sent as addresses, got %q and %q",
+			sent.Origins[0].Address, sent.Destinations[0].Address)
+	}
+
+	request.Params.Arguments = map[string]interface{}{
+		"originAddress":      "849VCWC8+R",
+		"destinationAddress": "CWF6+FX Mountain View, CA",
+	}
+	_, err := handler.handleDistanceCalculation(context.Background(), request)
+	if err == nil || !strings.Contains(err.Error(), "origin address: invalid plus code") {
+		t.Errorf("expected an invalid plus code error, got %v", err)
+	}
+	if CategoryOf(err) != CategoryValidation {
+		t.Errorf("expected a validation error, got %s", CategoryOf(err))
+	}
+}
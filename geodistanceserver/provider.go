@@ -0,0 +1,79 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Provider computes routes for a single-route request. The Google Routes
+// API is the default provider; MapboxProvider is an alternative, chosen
+// with WithProvider or GEODISTANCE_PROVIDER. A fallback provider, such as
+// an OSRM-compatible service, can be configured with WithFallbackProvider.
+type Provider interface {
+	ComputeRoutes(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error)
+}
+
+// googleProvider adapts the handler's Routes API call to Provider.
+type googleProvider struct {
+	gh *GeodistanceHandler
+}
+
+func (p googleProvider) ComputeRoutes(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error) {
+	return p.gh.callRoutesAPI(ctx, body, fieldMask)
+}
+
+// primaryProvider returns the provider set by WithProvider or
+// GEODISTANCE_PROVIDER, defaulting to the Google Routes API.
+func (gh *GeodistanceHandler) primaryProvider() Provider {
+	if gh.provider == nil {
+		return googleProvider{gh: gh}
+	}
+	return gh.provider
+}
+
+// bindProviders hands each MapboxProvider the handler, so its requests
+// share the handler's rate limit, throttle retries and response size limit.
+func (gh *GeodistanceHandler) bindProviders() {
+	for _, provider := range []Provider{gh.provider, gh.fallback} {
+		if mapbox, ok := provider.(*MapboxProvider); ok {
+			mapbox.gh = gh
+		}
+	}
+}
+
+// computeRoutes calls the primary provider and, when it fails with a
+// server error, the fallback provider. Client errors such as invalid
+// arguments are not retried elsewhere, since they would fail again.
+func (gh *GeodistanceHandler) computeRoutes(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error) {
+	responseBody, err := gh.primaryProvider().ComputeRoutes(ctx, body, fieldMask)
+	if err == nil || gh.fallback == nil || !isServerError(err) {
+		return gh.capRoutes(responseBody), err
+	}
+
+	responseBody, fallbackErr := gh.fallback.ComputeRoutes(ctx, body, fieldMask)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("primary provider failed: %w; fallback provider failed: %w", err, fallbackErr)
+	}
+	return gh.capRoutes(responseBody), nil
+}
+
+// isServerError reports whether err came from a 5xx API response.
+func isServerError(err error) bool {
+	return errorStatus(err) >= http.StatusInternalServerError
+}
+
+// errorStatus returns the HTTP status of the API response that caused err,
+// or 0 when err did not come from an API response.
+func errorStatus(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// mockProvider implements Provider for testing
+type mockProvider struct {
+	calls        int
+	responseBody *ResponseBody
+	err          error
+}
+
+func (m *mockProvider) ComputeRoutes(ctx context.Context, body *RequestBody, fieldMask string) (*ResponseBody, error) {
+	m.calls++
+	return m.responseBody, m.err
+}
+
+func TestGeodistanceHandler_callDistanceMatrix_FallbackProvider(t *testing.T) {
+	fallbackRoutes := &ResponseBody{Routes: []Route{{DistanceMeters: 4242, Duration: "60s"}}}
+
+	tests := []struct {
+		name           string
+		primaryStatus  int
+		primaryBody    string
+		fallback       *mockProvider
+		expectErr      bool
+		expectFallback bool
+		expectedMeters int
+	}{
+		{
+			name:           "primary succeeds",
+			primaryStatus:  http.StatusOK,
+			primaryBody:    createValidAPIResponse(),
+			fallback:       &mockProvider{responseBody: fallbackRoutes},
+			expectFallback: false,
+			expectedMeters: 1000,
+		},
+		{
+			name:           "primary server error uses fallback",
+			primaryStatus:  http.StatusServiceUnavailable,
+			primaryBody:    `{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}`,
+			fallback:       &mockProvider{responseBody: fallbackRoutes},
+			expectFallback: true,
+			expectedMeters: 4242,
+		},
+		{
+			name:           "primary raw server error uses fallback",
+			primaryStatus:  http.StatusBadGateway,
+			primaryBody:    "bad gateway",
+			fallback:       &mockProvider{responseBody: fallbackRoutes},
+			expectFallback: true,
+			expectedMeters: 4242,
+		},
+		{
+			name:           "client error is not retried",
+			primaryStatus:  http.StatusBadRequest,
+			primaryBody:    `{"error":{"code":400,"message":"Invalid origin.","status":"INVALID_ARGUMENT"}}`,
+			fallback:       &mockProvider{responseBody: fallbackRoutes},
+			expectErr:      true,
+			expectFallback: false,
+		},
+		{
+			name:           "both providers fail",
+			primaryStatus:  http.StatusInternalServerError,
+			primaryBody:    "internal error",
+			fallback:       &mockProvider{err: errors.New("osrm unavailable")},
+			expectErr:      true,
+			expectFallback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					return createMockResponse(tt.primaryStatus, tt.primaryBody), nil
+				}},
+			}
+			WithFallbackProvider(tt.fallback)(handler)
+
+			result, err := handler.callDistanceMatrix(context.Background(),
+				[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+
+			if (tt.fallback.calls > 0) != tt.expectFallback {
+				t.Errorf("expected fallback called=%v, got %d calls", tt.expectFallback, tt.fallback.calls)
+			}
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				if tt.expectFallback && (!strings.Contains(err.Error(), "internal error") || !strings.Contains(err.Error(), "osrm unavailable")) {
+					t.Errorf("expected combined error, got %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Routes[0].DistanceMeters != tt.expectedMeters {
+				t.Errorf("expected %d meters, got %d", tt.expectedMeters, result.Routes[0].DistanceMeters)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// WithRequestID attaches a caller-provided request ID to ctx. It is sent as
+// the X-Request-Id header on outgoing API calls and included in logs and
+// errors.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID attached to ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextWithRequestIDArgument attaches the optional requestId tool
+// argument to ctx. An ID already on the context is kept when the argument
+// is absent.
+func contextWithRequestIDArgument(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	if id := request.GetString("requestId", ""); id != "" {
+		return WithRequestID(ctx, id)
+	}
+	return ctx
+}
+
+// annotateRequestID prefixes err with the request ID from ctx so failures
+// can be traced back to the originating call.
+func annotateRequestID(ctx context.Context, err error) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return fmt.Errorf("request %s: %w", id, err)
+	}
+	return err
+}
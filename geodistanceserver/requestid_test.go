@@ -0,0 +1,79 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleDistanceCalculation_RequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctxID      string
+		argumentID string
+		expectedID string
+	}{
+		{name: "no request ID", expectedID: ""},
+		{name: "from context", ctxID: "ctx-123", expectedID: "ctx-123"},
+		{name: "from argument", argumentID: "arg-456", expectedID: "arg-456"},
+		{name: "argument overrides context", ctxID: "ctx-123", argumentID: "arg-456", expectedID: "arg-456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				logger: slog.New(slog.NewTextHandler(&logs, nil)),
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if got := req.Header.Get(requestIDHeader); got != tt.expectedID {
+						t.Errorf("expected %s header %q, got %q", requestIDHeader, tt.expectedID, got)
+					}
+					return createMockResponse(http.StatusBadRequest,
+						`{"error":{"code":400,"message":"bad origin","status":"INVALID_ARGUMENT"}}`), nil
+				}},
+			}
+
+			ctx := context.Background()
+			if tt.ctxID != "" {
+				ctx = WithRequestID(ctx, tt.ctxID)
+			}
+			args := map[string]interface{}{
+				"originAddress":      "New York",
+				"destinationAddress": "Boston",
+			}
+			if tt.argumentID != "" {
+				args["requestId"] = tt.argumentID
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: args},
+			}
+
+			_, err := handler.handleDistanceCalculation(ctx, request)
+			if err == nil {
+				t.Fatal("expected error but got none")
+			}
+
+			if tt.expectedID == "" {
+				if strings.HasPrefix(err.Error(), "request ") {
+					t.Errorf("expected no request ID in error, got %q", err.Error())
+				}
+				if strings.Contains(logs.String(), "requestId") {
+					t.Errorf("expected no request ID in logs, got %s", logs.String())
+				}
+				return
+			}
+			if !strings.Contains(err.Error(), tt.expectedID) {
+				t.Errorf("expected request ID %q in error, got %q", tt.expectedID, err.Error())
+			}
+			if !strings.Contains(logs.String(), "requestId="+tt.expectedID) {
+				t.Errorf("expected request ID in logs, got %s", logs.String())
+			}
+		})
+	}
+}
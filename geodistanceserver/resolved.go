@@ -0,0 +1,79 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// geocodingResultsFieldMask requests how the Routes API geocoded the
+// waypoints given as addresses.
+const geocodingResultsFieldMask = "geocodingResults"
+
+// GeocodingResults reports how the Routes API geocoded each waypoint given
+// as an address. Waypoints given as coordinates have no entry.
+type GeocodingResults struct {
+	Origin        *GeocodedWaypoint  `json:"origin,omitempty"`
+	Destination   *GeocodedWaypoint  `json:"destination,omitempty"`
+	Intermediates []GeocodedWaypoint `json:"intermediates,omitempty"`
+}
+
+// GeocodedWaypoint is the place an address waypoint resolved to.
+type GeocodedWaypoint struct {
+	GeocoderStatus                   json.RawMessage `json:"geocoderStatus,omitempty"`
+	Type                             []string        `json:"type,omitempty"`
+	PartialMatch                     bool            `json:"partialMatch,omitempty"`
+	PlaceID                          string          `json:"placeId,omitempty"`
+	IntermediateWaypointRequestIndex int             `json:"intermediateWaypointRequestIndex,omitempty"`
+}
+
+// resolvedAddressesText renders the addresses the API resolved the origin,
+// waypoints and destination to. The response only carries place IDs, so
+// each is looked up with the Geocoding API for its formatted address; the
+// place ID itself is shown when that lookup fails.
+func (gh *GeodistanceHandler) resolvedAddressesText(ctx context.Context, results *GeocodingResults) string {
+	if results == nil {
+		return "Resolved addresses: unavailable"
+	}
+
+	var lines []string
+	if line := gh.resolvedWaypointText(ctx, "origin", results.Origin); line != "" {
+		lines = append(lines, line)
+	}
+	for i := range results.Intermediates {
+		waypoint := &results.Intermediates[i]
+		label := fmt.Sprintf("waypoint %d", waypoint.IntermediateWaypointRequestIndex+1)
+		if line := gh.resolvedWaypointText(ctx, label, waypoint); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if line := gh.resolvedWaypointText(ctx, "destination", results.Destination); line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return "Resolved addresses: unavailable"
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (gh *GeodistanceHandler) resolvedWaypointText(ctx context.Context, label string, waypoint *GeocodedWaypoint) string {
+	if waypoint == nil || waypoint.PlaceID == "" {
+		return ""
+	}
+
+	text := "place ID " + waypoint.PlaceID
+	if geocodeResponse, err := gh.callGeocode(ctx, url.Values{"place_id": {waypoint.PlaceID}}); err == nil {
+		text = geocodeResponse.Results[0].FormattedAddress
+	} else if gh.logger != nil {
+		gh.logger.LogAttrs(ctx, slog.LevelWarn, "failed to look up resolved address",
+			slog.String("placeId", waypoint.PlaceID), slog.String("error", err.Error()))
+	}
+	if waypoint.PartialMatch {
+		text += " (partial match)"
+	}
+	return fmt.Sprintf("Resolved %s: %s", label, text)
+}
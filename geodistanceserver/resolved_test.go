@@ -0,0 +1,110 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleDistanceCalculation_ResolvedAddresses(t *testing.T) {
+	const routeWithGeocoding = `{
+		"routes": [{"distanceMeters": 1000, "duration": "300s", "routeLabels": ["DEFAULT_ROUTE"]}],
+		"geocodingResults": {
+			"origin": {"geocoderStatus": {}, "type": ["street_address"], "partialMatch": true, "placeId": "place-origin"},
+			"destination": {"geocoderStatus": {}, "type": ["locality", "political"], "placeId": "place-unknown"},
+			"intermediates": [{"geocoderStatus": {}, "type": ["locality"], "placeId": "place-waypoint", "intermediateWaypointRequestIndex": 0}]
+		}
+	}`
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		response string
+		expected string
+	}{
+		{
+			name:     "not requested",
+			args:     map[string]interface{}{},
+			response: routeWithGeocoding,
+		},
+		{
+			name:     "normalized addresses",
+			args:     map[string]interface{}{"includeResolvedAddresses": true},
+			response: routeWithGeocoding,
+			expected: "Resolved origin: 1 Main St, Springfield, IL 62701, USA (partial match)\n" +
+				"Resolved waypoint 1: Peoria, IL, USA\n" +
+				"Resolved destination: place ID place-unknown",
+		},
+		{
+			name:     "with waypoints",
+			args:     map[string]interface{}{"includeResolvedAddresses": true, "waypoints": []interface{}{"Peoria"}},
+			response: routeWithGeocoding,
+			expected: "Resolved waypoint 1: Peoria, IL, USA",
+		},
+		{
+			name:     "response without geocoding results",
+			args:     map[string]interface{}{"includeResolvedAddresses": true},
+			response: createValidAPIResponse(),
+			expected: "Resolved addresses: unavailable",
+		},
+		{
+			name:     "round trip",
+			args:     map[string]interface{}{"includeResolvedAddresses": true, "roundTrip": true},
+			response: routeWithGeocoding,
+			expected: "Resolved origin: 1 Main St, Springfield, IL 62701, USA (partial match)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, requested := tt.args["includeResolvedAddresses"]
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.Method == http.MethodPost {
+						mask := req.Header.Get("X-Goog-FieldMask")
+						if strings.Contains(mask, geocodingResultsFieldMask) != requested {
+							t.Errorf("unexpected field mask %q", mask)
+						}
+						return createMockResponse(http.StatusOK, tt.response), nil
+					}
+					switch req.URL.Query().Get("place_id") {
+					case "place-origin":
+						return createMockResponse(http.StatusOK, `{"status":"OK","results":[{"formatted_address":"1 Main St, Springfield, IL 62701, USA"}]}`), nil
+					case "place-waypoint":
+						return createMockResponse(http.StatusOK, `{"status":"OK","results":[{"formatted_address":"Peoria, IL, USA"}]}`), nil
+					default:
+						return createMockResponse(http.StatusOK, `{"status":"ZERO_RESULTS","results":[]}`), nil
+					}
+				}},
+			}
+
+			args := map[string]interface{}{"originAddress": "1 main st springfield", "destinationAddress": "somewhere"}
+			for key, value := range tt.args {
+				args[key] = value
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: args},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			last := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+			if tt.expected == "" {
+				if strings.Contains(last, "Resolved") {
+					t.Errorf("expected no resolved addresses, got %q", last)
+				}
+				return
+			}
+			if !strings.Contains(last, tt.expected) {
+				t.Errorf("expected %q in %q", tt.expected, last)
+			}
+		})
+	}
+}
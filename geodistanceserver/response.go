@@ -0,0 +1,87 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// defaultMaxResponseBytes caps how much of an API response body is read.
+// Real Routes and Geocoding responses are far smaller; the cap only guards
+// against a misbehaving endpoint streaming an unbounded body.
+const defaultMaxResponseBytes int64 = 4 << 20
+
+// ErrResponseTooLarge is returned when an API response body exceeds the
+// configured maximum size.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// defaultMaxRoutes caps how many routes of a response are processed. The
+// Routes API returns at most a handful, so more only come from a
+// misbehaving provider.
+const defaultMaxRoutes = 10
+
+func (gh *GeodistanceHandler) maxRouteCount() int {
+	if gh.maxRoutes <= 0 {
+		return defaultMaxRoutes
+	}
+	return gh.maxRoutes
+}
+
+// capRoutes drops the routes of responseBody past the configured maximum,
+// keeping the API's order. A nil responseBody is returned unchanged.
+func (gh *GeodistanceHandler) capRoutes(responseBody *ResponseBody) *ResponseBody {
+	if responseBody == nil || len(responseBody.Routes) <= gh.maxRouteCount() {
+		return responseBody
+	}
+	if gh.logger != nil {
+		gh.logger.Warn("ignoring routes past the maximum",
+			slog.Int("routes", len(responseBody.Routes)), slog.Int("max", gh.maxRouteCount()))
+	}
+	responseBody.Routes = responseBody.Routes[:gh.maxRouteCount()]
+	return responseBody
+}
+
+func (gh *GeodistanceHandler) maxResponseSize() int64 {
+	if gh.maxResponseBytes <= 0 {
+		return defaultMaxResponseBytes
+	}
+	return gh.maxResponseBytes
+}
+
+// readResponseBody reads at most the configured maximum from r, returning
+// ErrResponseTooLarge rather than a truncated body when the limit is hit.
+func (gh *GeodistanceHandler) readResponseBody(r io.Reader) ([]byte, error) {
+	limit := gh.maxResponseSize()
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
+
+// readErrorBody reads a non-200 response body for inclusion in an error
+// message, silently truncating it at the configured maximum.
+func (gh *GeodistanceHandler) readErrorBody(r io.Reader) []byte {
+	data, _ := io.ReadAll(io.LimitReader(r, gh.maxResponseSize()))
+	return data
+}
+
+// decodeResponse unmarshals an API response body into v. With strict
+// decoding enabled, fields the response types do not declare are rejected
+// so that schema drift surfaces as an error instead of silently dropped data.
+func (gh *GeodistanceHandler) decodeResponse(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if gh.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
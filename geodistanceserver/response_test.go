@@ -0,0 +1,144 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_processResponse_SizeLimit(t *testing.T) {
+	normal := createValidAPIResponse()
+
+	tests := []struct {
+		name      string
+		handler   *GeodistanceHandler
+		body      string
+		expectErr error
+	}{
+		{
+			name:    "normal body under default limit",
+			handler: &GeodistanceHandler{},
+			body:    normal,
+		},
+		{
+			name:    "body exactly at limit",
+			handler: &GeodistanceHandler{maxResponseBytes: int64(len(normal))},
+			body:    normal,
+		},
+		{
+			name:      "oversized body",
+			handler:   &GeodistanceHandler{maxResponseBytes: 64},
+			body:      normal,
+			expectErr: ErrResponseTooLarge,
+		},
+		{
+			name:      "oversized body under default limit",
+			handler:   &GeodistanceHandler{},
+			body:      `{"routes": [` + strings.Repeat(" ", int(defaultMaxResponseBytes)) + `]}`,
+			expectErr: ErrResponseTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler.processResponse(createMockResponse(http.StatusOK, tt.body))
+
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Errorf("expected %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Routes) == 0 {
+				t.Error("expected routes in response")
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_processResponse_StrictDecoding(t *testing.T) {
+	body := `{"routes": [{"distanceMeters": 1000, "duration": "60s", "futureField": true}]}`
+
+	tests := []struct {
+		name      string
+		strict    bool
+		expectErr bool
+	}{
+		{name: "lenient ignores unknown fields", strict: false},
+		{name: "strict rejects unknown fields", strict: true, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{}
+			WithStrictDecoding(tt.strict)(handler)
+
+			_, err := handler.processResponse(createMockResponse(http.StatusOK, body))
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_MaxRoutes(t *testing.T) {
+	// 15 alternatives, numbered by distance so the kept ones can be checked
+	var routes []string
+	for i := 1; i <= 15; i++ {
+		routes = append(routes, fmt.Sprintf(`{"distanceMeters":%d,"duration":"60s"}`, i*1000))
+	}
+	response := `{"routes":[` + strings.Join(routes, ",") + `]}`
+
+	tests := []struct {
+		name     string
+		handler  *GeodistanceHandler
+		expected int
+	}{
+		{name: "default cap", handler: &GeodistanceHandler{}, expected: defaultMaxRoutes},
+		{name: "configured cap", handler: &GeodistanceHandler{maxRoutes: 3}, expected: 3},
+		{name: "cap above route count", handler: &GeodistanceHandler{maxRoutes: 20}, expected: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.handler.apiKey = "test-key"
+			tt.handler.client = &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, response), nil
+			}}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: toolCalculateDistance,
+					Arguments: map[string]interface{}{
+						"originAddress":            "New York",
+						"destinationAddress":       "Los Angeles",
+						"computeAlternativeRoutes": true,
+					},
+				},
+			}
+
+			result, err := tt.handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != tt.expected {
+				t.Fatalf("expected %d routes, got %d", tt.expected, len(result.Content))
+			}
+			last := result.Content[tt.expected-1].(mcp.TextContent).Text
+			if want := fmt.Sprintf("Route %d distance: %s", tt.expected, formatDistance(tt.expected*1000, unitsMetric, "")); !strings.HasPrefix(last, want) {
+				t.Errorf("expected %q to start with %q", last, want)
+			}
+		})
+	}
+}
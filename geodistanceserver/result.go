@@ -0,0 +1,37 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"time"
+)
+
+// RouteResult is the parsed form of a single route, for callers that use
+// the handler programmatically rather than through the MCP tools.
+type RouteResult struct {
+	DistanceMeters int
+	Duration       time.Duration
+	Labels         []string
+}
+
+// BestRoute extracts the route the API marked as its default, or the first
+// route when none is labeled. Duration is left zero when the response does
+// not carry one, as with distance-only requests.
+func (r *ResponseBody) BestRoute() (RouteResult, error) {
+	if r == nil || len(r.Routes) == 0 {
+		return RouteResult{}, ErrNoRoute
+	}
+
+	route := referenceRoutes(r.Routes)[0]
+	result := RouteResult{
+		DistanceMeters: route.DistanceMeters,
+		Labels:         route.RouteLabels,
+	}
+	if route.Duration != "" {
+		d, err := parseDuration(route.Duration)
+		if err != nil {
+			return RouteResult{}, fmt.Errorf("invalid route duration: %w", err)
+		}
+		result.Duration = d
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package geodistanceserver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResponseBody_BestRoute(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      *ResponseBody
+		expected  RouteResult
+		expectErr bool
+	}{
+		{
+			name: "single route",
+			body: &ResponseBody{Routes: []Route{
+				{DistanceMeters: 94475, Duration: "3288s", RouteLabels: []string{"DEFAULT_ROUTE"}},
+			}},
+			expected: RouteResult{DistanceMeters: 94475, Duration: 3288 * time.Second, Labels: []string{"DEFAULT_ROUTE"}},
+		},
+		{
+			name: "default route listed second",
+			body: &ResponseBody{Routes: []Route{
+				{DistanceMeters: 87865, Duration: "4903.5s", RouteLabels: []string{"SHORTER_DISTANCE"}},
+				{DistanceMeters: 94475, Duration: "3288s", RouteLabels: []string{"DEFAULT_ROUTE"}},
+			}},
+			expected: RouteResult{DistanceMeters: 94475, Duration: 3288 * time.Second, Labels: []string{"DEFAULT_ROUTE"}},
+		},
+		{
+			name: "unlabeled routes",
+			body: &ResponseBody{Routes: []Route{
+				{DistanceMeters: 1000, Duration: "60s"},
+				{DistanceMeters: 900, Duration: "90s"},
+			}},
+			expected: RouteResult{DistanceMeters: 1000, Duration: time.Minute},
+		},
+		{
+			name:     "distance only",
+			body:     &ResponseBody{Routes: []Route{{DistanceMeters: 1000}}},
+			expected: RouteResult{DistanceMeters: 1000},
+		},
+		{
+			name:      "invalid duration",
+			body:      &ResponseBody{Routes: []Route{{DistanceMeters: 1000, Duration: "soon"}}},
+			expectErr: true,
+		},
+		{
+			name:      "no routes",
+			body:      &ResponseBody{},
+			expectErr: true,
+		},
+		{
+			name:      "nil response",
+			body:      nil,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.body.BestRoute()
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultThrottleRetries is how many times a throttled (429) call is
+	// retried before its error is returned.
+	defaultThrottleRetries = 2
+	// defaultMaxThrottleDelay caps the wait before a retry, however long
+	// Retry-After asks for.
+	defaultMaxThrottleDelay = 10 * time.Second
+	// throttleBackoff is the first retry delay when the response carries no
+	// usable Retry-After; it doubles on each further attempt.
+	throttleBackoff = 500 * time.Millisecond
+)
+
+// throttleRetry retries calls the API answered with 429 Too Many Requests.
+type throttleRetry struct {
+	maxRetries int
+	maxDelay   time.Duration
+	now        func() time.Time
+}
+
+func newThrottleRetry(maxRetries int, maxDelay time.Duration) *throttleRetry {
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxThrottleDelay
+	}
+	return &throttleRetry{maxRetries: maxRetries, maxDelay: maxDelay, now: time.Now}
+}
+
+// delay returns how long to wait before retry attempt (zero-based),
+// honouring Retry-After when present and capping the result at maxDelay.
+func (t *throttleRetry) delay(header http.Header, attempt int) time.Duration {
+	d, ok := retryAfterDelay(header.Get("Retry-After"), t.now())
+	if !ok {
+		d = throttleBackoff << attempt
+	}
+	return min(d, t.maxDelay)
+}
+
+// retryAfterDelay parses a Retry-After value given either as delay
+// seconds or as an HTTP-date. A date in the past means retry immediately.
+func retryAfterDelay(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// do sends req, retrying throttled responses as configured by
+// WithThrottleRetry. With several API keys, a throttled request is first
+// resent at once under each other key in turn. Waiting for a retry ends
+// early with the context's error if ctx is done first.
+func (gh *GeodistanceHandler) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return gh.doWith(ctx, gh.httpClient(), req)
+}
+
+// doWith is do sending through client. Requests that carry no Google API
+// key are only retried, never failed over.
+func (gh *GeodistanceHandler) doWith(ctx context.Context, client HTTPClient, req *http.Request) (*http.Response, error) {
+	for attempt, failovers := 0, 0; ; {
+		resp, err := gh.doOnce(ctx, client, req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		failover := gh.apiKeys != nil && hasAPIKey(req) && failovers < len(gh.apiKeys.keys)-1
+		if !failover && (gh.retry == nil || attempt >= gh.retry.maxRetries) {
+			return resp, err
+		}
+
+		var delay time.Duration
+		if !failover {
+			delay = gh.retry.delay(resp.Header, attempt)
+		}
+		gh.readErrorBody(resp.Body)
+		resp.Body.Close()
+
+		if failover {
+			failovers++
+			if gh.logger != nil {
+				gh.logger.LogAttrs(ctx, slog.LevelWarn, "API call throttled, retrying with the next API key",
+					slog.String("url", redactURL(req.URL)), slog.Int("failover", failovers))
+			}
+		} else {
+			attempt++
+			if gh.logger != nil {
+				gh.logger.LogAttrs(ctx, slog.LevelWarn, "API call throttled, retrying",
+					slog.String("url", redactURL(req.URL)), slog.Int("attempt", attempt), slog.Duration("delay", delay))
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		if failover {
+			gh.failoverAPIKey(req)
+		}
+	}
+}
+
+// rewindRequest returns a copy of req with a fresh body, so it can be sent
+// again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
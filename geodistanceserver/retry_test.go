@@ -0,0 +1,162 @@
+package geodistanceserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", value: "3", expected: 3 * time.Second, ok: true},
+		{name: "zero seconds", value: "0", expected: 0, ok: true},
+		{name: "HTTP date", value: now.Add(5 * time.Second).Format(http.TimeFormat), expected: 5 * time.Second, ok: true},
+		{name: "HTTP date in the past", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+		{name: "empty", value: "", ok: false},
+		{name: "negative seconds", value: "-1", ok: false},
+		{name: "garbage", value: "soon", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterDelay(tt.value, now)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestThrottleRetry_delay(t *testing.T) {
+	retry := newThrottleRetry(3, 2*time.Second)
+
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		expected   time.Duration
+	}{
+		{name: "honours Retry-After", retryAfter: "1", expected: time.Second},
+		{name: "caps Retry-After", retryAfter: "120", expected: 2 * time.Second},
+		{name: "backoff without header", attempt: 0, expected: throttleBackoff},
+		{name: "backoff doubles", attempt: 1, expected: 2 * throttleBackoff},
+		{name: "backoff capped", attempt: 5, expected: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := make(http.Header)
+			if tt.retryAfter != "" {
+				header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := retry.delay(header, tt.attempt); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_do_ThrottleRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		retryAfter    string
+		throttled     int
+		expectErr     bool
+		expectedCalls int32
+	}{
+		{name: "numeric Retry-After", retryAfter: "1", throttled: 1, expectedCalls: 2},
+		{name: "date Retry-After", retryAfter: time.Now().Add(time.Second).Format(http.TimeFormat), throttled: 2, expectedCalls: 3},
+		{name: "no Retry-After", throttled: 1, expectedCalls: 2},
+		{name: "retries exhausted", retryAfter: "1", throttled: 5, expectErr: true, expectedCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				n := atomic.AddInt32(&calls, 1)
+				body, _ := io.ReadAll(req.Body)
+				if len(body) == 0 {
+					t.Errorf("call %d: expected the request body to be resent", n)
+				}
+				if int(n) <= tt.throttled {
+					resp := createMockResponse(http.StatusTooManyRequests, `{"error":{"code":429,"message":"Quota exceeded.","status":"RESOURCE_EXHAUSTED"}}`)
+					if tt.retryAfter != "" {
+						resp.Header.Set("Retry-After", tt.retryAfter)
+					}
+					return resp, nil
+				}
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			}}, WithThrottleRetry(2, 10*time.Millisecond))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			_, err = handler.callDistanceMatrix(context.Background(),
+				[]Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+			if tt.expectErr {
+				if errorStatus(err) != http.StatusTooManyRequests {
+					t.Errorf("expected a 429 error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_do_ThrottleRetryCanceled(t *testing.T) {
+	handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		resp := createMockResponse(http.StatusTooManyRequests, `{}`)
+		resp.Header.Set("Retry-After", "60")
+		return resp, nil
+	}}, WithThrottleRetry(2, time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = handler.callDistanceMatrix(ctx, []Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the wait to end with the context, took %v", elapsed)
+	}
+}
+
+func TestWithThrottleRetry_Disabled(t *testing.T) {
+	var calls int32
+	handler, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return createMockResponse(http.StatusTooManyRequests, `{}`), nil
+	}}, WithThrottleRetry(0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	handler.callDistanceMatrix(context.Background(), []Origin{{Address: "New York"}}, []Destination{{Address: "Boston"}}, routeOptions{})
+	if calls != 1 {
+		t.Errorf("expected a single call with retries disabled, got %d", calls)
+	}
+}
@@ -0,0 +1,157 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseRoundTrip reads the roundTrip argument. Round trips report one
+// route per leg, so they exclude waypoints, alternative routes and JSON
+// output.
+func parseRoundTrip(request mcp.CallToolRequest, opts routeOptions) (bool, error) {
+	if !request.GetBool("roundTrip", false) {
+		return false, nil
+	}
+	if len(opts.Waypoints) > 0 {
+		return false, fmt.Errorf("roundTrip is not supported with waypoints")
+	}
+	if opts.ComputeAlternativeRoutes {
+		return false, fmt.Errorf("roundTrip is not supported with computeAlternativeRoutes")
+	}
+	if opts.Format == outputFormatJSON {
+		return false, fmt.Errorf("roundTrip is not supported with %s output", outputFormatJSON)
+	}
+	return true, nil
+}
+
+// handleRoundTrip routes origin to destination and back, concurrently,
+// and reports the summed trip followed by each leg. The legs are separate
+// single-element requests: one matrix request for both would be billed
+// for all four origin/destination pairs.
+func (gh *GeodistanceHandler) handleRoundTrip(
+	ctx context.Context,
+	origin, destination Waypoint,
+	opts routeOptions,
+) (*mcp.CallToolResult, error) {
+	legs := [2]struct {
+		origins      []Origin
+		destinations []Destination
+		route        Route
+		geocoded     *GeocodingResults
+		elapsed      time.Duration
+		cached       bool
+		err          error
+	}{
+		{
+			origins:      []Origin{{Address: origin.Address, Location: origin.Location}},
+			destinations: []Destination{{Address: destination.Address, Location: destination.Location}},
+		},
+		{
+			origins:      []Origin{{Address: destination.Address, Location: destination.Location}},
+			destinations: []Destination{{Address: origin.Address, Location: origin.Location}},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := range legs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			responseBody, err := gh.callDistanceMatrix(ctx, legs[i].origins, legs[i].destinations, opts)
+			if err != nil {
+				legs[i].err = err
+				return
+			}
+			if len(responseBody.Routes) == 0 {
+				legs[i].err = ErrNoRoute
+				return
+			}
+			legs[i].route = referenceRoutes(responseBody.Routes)[0]
+			legs[i].geocoded = responseBody.GeocodingResults
+			legs[i].elapsed, legs[i].cached = responseBody.elapsed, responseBody.cached
+		}()
+	}
+	wg.Wait()
+
+	if legs[0].err != nil {
+		return nil, fmt.Errorf("outbound leg: %w", legs[0].err)
+	}
+	if legs[1].err != nil {
+		return nil, fmt.Errorf("return leg: %w", legs[1].err)
+	}
+
+	total, err := sumRoutes(legs[0].route, legs[1].route)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.TextContent{Type: "text", Text: "Round trip " + gh.formatRoute(total, opts)},
+			mcp.TextContent{Type: "text", Text: "Outbound " + gh.formatRoute(legs[0].route, opts)},
+			mcp.TextContent{Type: "text", Text: "Return " + gh.formatRoute(legs[1].route, opts)},
+		},
+	}
+	if opts.ResolvedAddresses {
+		// The outbound leg resolves both ends of the trip
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: gh.resolvedAddressesText(ctx, legs[0].geocoded),
+		})
+	}
+	if opts.ShowTiming {
+		// The legs run concurrently, so the slower one is the wait
+		result.Content = append(result.Content, mcp.TextContent{
+			Type: "text",
+			Text: timingText(max(legs[0].elapsed, legs[1].elapsed), legs[0].cached && legs[1].cached),
+		})
+	}
+	return result, nil
+}
+
+// sumRoutes combines two legs into one route. A duration is summed only
+// when both legs carry it, so distance-only calls and legs without a
+// no-traffic duration leave the total's field empty too.
+func sumRoutes(a, b Route) (Route, error) {
+	total := Route{
+		DistanceMeters:     a.DistanceMeters + b.DistanceMeters,
+		trafficUnavailable: a.trafficUnavailable || b.trafficUnavailable,
+	}
+	// Each leg's advisories still apply to the whole trip
+	for _, warning := range slices.Concat(a.Warnings, b.Warnings) {
+		if !slices.Contains(total.Warnings, warning) {
+			total.Warnings = append(total.Warnings, warning)
+		}
+	}
+
+	var err error
+	if total.Duration, err = sumDurations(a.Duration, b.Duration); err != nil {
+		return Route{}, fmt.Errorf("invalid route duration: %w", err)
+	}
+	if total.StaticDuration, err = sumDurations(a.StaticDuration, b.StaticDuration); err != nil {
+		return Route{}, fmt.Errorf("invalid route static duration: %w", err)
+	}
+	return total, nil
+}
+
+func sumDurations(a, b string) (string, error) {
+	if a == "" || b == "" {
+		return "", nil
+	}
+	da, err := parseDuration(a)
+	if err != nil {
+		return "", err
+	}
+	db, err := parseDuration(b)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat((da+db).Seconds(), 'f', -1, 64) + "s", nil
+}
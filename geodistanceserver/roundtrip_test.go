@@ -0,0 +1,208 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// roundTripDoFunc answers Omaha -> Lincoln and Lincoln -> Omaha with
+// different routes, as one-way streets would.
+func roundTripDoFunc(calls *int32) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(calls, 1)
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		var body RequestBody
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, err
+		}
+
+		route := Route{DistanceMeters: 94475, Duration: "3288s", StaticDuration: "3000s", RouteLabels: []string{"DEFAULT_ROUTE"}}
+		if body.Origins[0].Address == "Lincoln, NE" {
+			route = Route{DistanceMeters: 95025, Duration: "3312s", StaticDuration: "3100s", RouteLabels: []string{"DEFAULT_ROUTE"}}
+		}
+		response, _ := json.Marshal(ResponseBody{Routes: []Route{route}})
+		return createMockResponse(http.StatusOK, string(response)), nil
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_RoundTrip(t *testing.T) {
+	var calls int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: roundTripDoFunc(&calls)},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: toolCalculateDistance,
+			Arguments: map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "Lincoln, NE",
+				"durationFormat":     durationFormatSeconds,
+				"roundTrip":          true,
+			},
+		},
+	}
+
+	result, err := handler.handleDistanceCalculation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected one request per leg, got %d", calls)
+	}
+
+	expected := []string{
+		"Round trip distance: 189.50 km (189500 meters), Duration: 6600 seconds (no traffic: 6100 seconds)",
+		"Outbound distance: 94.47 km (94475 meters), Duration: 3288 seconds (no traffic: 3000 seconds)",
+		"Return distance: 95.03 km (95025 meters), Duration: 3312 seconds (no traffic: 3100 seconds)",
+	}
+	if len(result.Content) != len(expected) {
+		t.Fatalf("expected %d content items, got %d", len(expected), len(result.Content))
+	}
+	for i, want := range expected {
+		got := result.Content[i].(mcp.TextContent).Text
+		if !strings.HasPrefix(got, want) {
+			t.Errorf("content %d: expected %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_RoundTripErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		mockFunc func(req *http.Request) (*http.Response, error)
+		expected string
+	}{
+		{
+			name:     "with waypoints",
+			args:     map[string]interface{}{"waypoints": []interface{}{"Ashland, NE"}},
+			expected: "roundTrip is not supported with waypoints",
+		},
+		{
+			name:     "with alternative routes",
+			args:     map[string]interface{}{"computeAlternativeRoutes": true},
+			expected: "roundTrip is not supported with computeAlternativeRoutes",
+		},
+		{
+			name:     "with json output",
+			args:     map[string]interface{}{"format": outputFormatJSON},
+			expected: "roundTrip is not supported with json output",
+		},
+		{
+			name: "return leg fails",
+			mockFunc: func(req *http.Request) (*http.Response, error) {
+				data, _ := io.ReadAll(req.Body)
+				if strings.Contains(string(data), `"origins":[{"address":"Lincoln, NE"`) {
+					return nil, fmt.Errorf("network error")
+				}
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			},
+			expected: "return leg:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if tt.mockFunc == nil {
+						t.Error("no request expected")
+						return nil, fmt.Errorf("unexpected request")
+					}
+					return tt.mockFunc(req)
+				}},
+			}
+
+			args := map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "Lincoln, NE",
+				"roundTrip":          true,
+			}
+			for k, v := range tt.args {
+				args[k] = v
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: args},
+			}
+
+			_, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSumRoutes(t *testing.T) {
+	tests := []struct {
+		name      string
+		a, b      Route
+		expected  Route
+		expectErr bool
+	}{
+		{
+			name:     "both durations",
+			a:        Route{DistanceMeters: 1000, Duration: "60s", StaticDuration: "50s"},
+			b:        Route{DistanceMeters: 1500, Duration: "90.5s", StaticDuration: "70s"},
+			expected: Route{DistanceMeters: 2500, Duration: "150.5s", StaticDuration: "120s"},
+		},
+		{
+			name:     "distance only",
+			a:        Route{DistanceMeters: 1000},
+			b:        Route{DistanceMeters: 1500},
+			expected: Route{DistanceMeters: 2500},
+		},
+		{
+			name:     "one leg without static duration",
+			a:        Route{DistanceMeters: 1000, Duration: "60s", StaticDuration: "50s"},
+			b:        Route{DistanceMeters: 1500, Duration: "90s"},
+			expected: Route{DistanceMeters: 2500, Duration: "150s"},
+		},
+		{
+			name:     "warnings merged",
+			a:        Route{DistanceMeters: 1000, Warnings: []string{"Restricted usage road", "Toll road"}},
+			b:        Route{DistanceMeters: 1500, Warnings: []string{"Toll road"}},
+			expected: Route{DistanceMeters: 2500, Warnings: []string{"Restricted usage road", "Toll road"}},
+		},
+		{
+			name:      "invalid duration",
+			a:         Route{DistanceMeters: 1000, Duration: "soon"},
+			b:         Route{DistanceMeters: 1500, Duration: "90s"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sumRoutes(tt.a, tt.b)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.DistanceMeters != tt.expected.DistanceMeters || got.Duration != tt.expected.Duration ||
+				got.StaticDuration != tt.expected.StaticDuration || !slices.Equal(got.Warnings, tt.expected.Warnings) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
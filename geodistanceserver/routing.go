@@ -0,0 +1,176 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	travelModeDrive      = "DRIVE"
+	travelModeBicycle    = "BICYCLE"
+	travelModeWalk       = "WALK"
+	travelModeTwoWheeler = "TWO_WHEELER"
+	travelModeTransit    = "TRANSIT"
+)
+
+// travelModes lists the accepted travelMode values, as advertised in the
+// tool schemas.
+var travelModes = []string{travelModeDrive, travelModeBicycle, travelModeWalk, travelModeTwoWheeler, travelModeTransit}
+
+func validateTravelMode(mode string) error {
+	switch mode {
+	case travelModeDrive, travelModeBicycle, travelModeWalk, travelModeTwoWheeler, travelModeTransit:
+		return nil
+	default:
+		return fmt.Errorf("invalid travel mode %q: must be %s, %s, %s, %s or %s", mode,
+			travelModeDrive, travelModeBicycle, travelModeWalk, travelModeTwoWheeler, travelModeTransit)
+	}
+}
+
+// travelModeOrDefault returns mode, defaulting to DRIVE.
+func travelModeOrDefault(mode string) string {
+	if mode == "" {
+		return travelModeDrive
+	}
+	return mode
+}
+
+// resolveDefaultTravelMode reads GEODISTANCE_DEFAULT_TRAVEL_MODE, falling
+// back to DRIVE when it is unset or not a valid travel mode.
+func (gh *GeodistanceHandler) resolveDefaultTravelMode() string {
+	value := os.Getenv("GEODISTANCE_DEFAULT_TRAVEL_MODE")
+	if value == "" {
+		return travelModeDrive
+	}
+
+	mode := normalizeEnum(value)
+	if err := validateTravelMode(mode); err != nil {
+		if gh.logger != nil {
+			gh.logger.Warn("ignoring invalid GEODISTANCE_DEFAULT_TRAVEL_MODE",
+				slog.String("value", value), slog.String("fallback", travelModeDrive))
+		}
+		return travelModeDrive
+	}
+
+	return mode
+}
+
+// travelModeDefault returns the travel mode used when a call does not
+// choose one.
+func (gh *GeodistanceHandler) travelModeDefault() string {
+	return travelModeOrDefault(gh.defaultTravelMode)
+}
+
+// validateRouteModifiers rejects modifiers the travel mode cannot honour.
+// Avoiding indoor steps and passages is only defined for walking, and the
+// vehicle emission type only for driving.
+func validateRouteModifiers(modifiers RouteModifiers, mode string) error {
+	if modifiers.AvoidIndoor && travelModeOrDefault(mode) != travelModeWalk {
+		return fmt.Errorf("avoidIndoor is only supported for %s, got travel mode %s", travelModeWalk, travelModeOrDefault(mode))
+	}
+	if modifiers.VehicleInfo != nil && travelModeOrDefault(mode) != travelModeDrive {
+		return fmt.Errorf("emissionType is only supported for %s, got travel mode %s", travelModeDrive, travelModeOrDefault(mode))
+	}
+	return nil
+}
+
+// supportsRoutingPreference reports whether the Routes API accepts a
+// routing preference for the travel mode; it is only defined for motorized
+// road travel, where traffic applies.
+func supportsRoutingPreference(mode string) bool {
+	switch travelModeOrDefault(mode) {
+	case travelModeDrive, travelModeTwoWheeler:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	routingPreferenceTrafficUnaware      = "TRAFFIC_UNAWARE"
+	routingPreferenceTrafficAware        = "TRAFFIC_AWARE"
+	routingPreferenceTrafficAwareOptimal = "TRAFFIC_AWARE_OPTIMAL"
+)
+
+var routingPreferences = []string{
+	routingPreferenceTrafficAware, routingPreferenceTrafficAwareOptimal, routingPreferenceTrafficUnaware,
+}
+
+func validateRoutingPreference(preference string) error {
+	switch preference {
+	case routingPreferenceTrafficUnaware, routingPreferenceTrafficAware, routingPreferenceTrafficAwareOptimal:
+		return nil
+	default:
+		return fmt.Errorf("invalid routing preference %q: must be %s, %s or %s", preference,
+			routingPreferenceTrafficUnaware, routingPreferenceTrafficAware, routingPreferenceTrafficAwareOptimal)
+	}
+}
+
+func isTrafficAware(preference string) bool {
+	return preference == routingPreferenceTrafficAware || preference == routingPreferenceTrafficAwareOptimal
+}
+
+// parseDepartureTime parses an RFC3339 departure time. The Routes API only
+// accepts future timestamps, and only uses them for traffic-aware routing.
+func parseDepartureTime(value string, routingPreference string, now time.Time) (time.Time, error) {
+	departure, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid departureTime %q: must be RFC3339", value)
+	}
+	if err := validateDepartureTime(departure, routingPreference, now); err != nil {
+		return time.Time{}, err
+	}
+	return departure, nil
+}
+
+func validateDepartureTime(departure time.Time, routingPreference string, now time.Time) error {
+	if !departure.After(now) {
+		return fmt.Errorf("departureTime %s must be in the future", departure.Format(time.RFC3339))
+	}
+	if !isTrafficAware(routingPreference) {
+		return fmt.Errorf("departureTime requires a traffic-aware routing preference, got %s", routingPreference)
+	}
+	return nil
+}
+
+// parseArrivalTime parses an RFC3339 arrival time. The Routes API only
+// honours an arrival time for transit routes, and it cannot be combined
+// with a departure time.
+func parseArrivalTime(value string, travelMode string, now time.Time) (time.Time, error) {
+	arrival, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid arrivalTime %q: must be RFC3339", value)
+	}
+	if !arrival.After(now) {
+		return time.Time{}, fmt.Errorf("arrivalTime %s must be in the future", value)
+	}
+	if travelModeOrDefault(travelMode) != travelModeTransit {
+		return time.Time{}, fmt.Errorf("arrivalTime is only supported for %s, got travel mode %s", travelModeTransit, travelModeOrDefault(travelMode))
+	}
+	return arrival, nil
+}
+
+// trafficFreshness reports whether a result is based on live traffic data.
+// The Routes API does not return a freshness indicator, so it is inferred
+// from the request: traffic-aware routing without a departure time uses
+// live conditions, a future departure time uses predicted traffic, and
+// traffic-unaware routing uses none.
+func trafficFreshness(opts routeOptions) (fresh bool, source string) {
+	preference := opts.RoutingPreference
+	if preference == "" {
+		preference = routingPreferenceTrafficAware
+	}
+
+	switch {
+	case !supportsRoutingPreference(opts.TravelMode):
+		return false, "no traffic model for " + opts.TravelMode
+	case !isTrafficAware(preference):
+		return false, "traffic-unaware routing"
+	case !opts.DepartureTime.IsZero():
+		return false, "predicted for departure time"
+	default:
+		return true, "live traffic"
+	}
+}
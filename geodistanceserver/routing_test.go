@@ -0,0 +1,383 @@
+package geodistanceserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateRoutingPreference(t *testing.T) {
+	tests := []struct {
+		preference string
+		expectErr  bool
+	}{
+		{preference: routingPreferenceTrafficUnaware, expectErr: false},
+		{preference: routingPreferenceTrafficAware, expectErr: false},
+		{preference: routingPreferenceTrafficAwareOptimal, expectErr: false},
+		{preference: "FASTEST", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preference, func(t *testing.T) {
+			err := validateRoutingPreference(tt.preference)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseDepartureTime(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		value      string
+		preference string
+		expected   time.Time
+		expectErr  bool
+	}{
+		{
+			name:       "future time traffic aware",
+			value:      "2030-01-01T13:00:00Z",
+			preference: routingPreferenceTrafficAware,
+			expected:   time.Date(2030, 1, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "future time with offset",
+			value:      "2030-01-01T08:30:00-05:00",
+			preference: routingPreferenceTrafficAwareOptimal,
+			expected:   time.Date(2030, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "past time",
+			value:      "2029-12-31T23:00:00Z",
+			preference: routingPreferenceTrafficAware,
+			expectErr:  true,
+		},
+		{
+			name:       "current time",
+			value:      "2030-01-01T12:00:00Z",
+			preference: routingPreferenceTrafficAware,
+			expectErr:  true,
+		},
+		{
+			name:       "traffic unaware preference",
+			value:      "2030-01-01T13:00:00Z",
+			preference: routingPreferenceTrafficUnaware,
+			expectErr:  true,
+		},
+		{
+			name:       "not RFC3339",
+			value:      "tomorrow at noon",
+			preference: routingPreferenceTrafficAware,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			departure, err := parseDepartureTime(tt.value, tt.preference, now)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !departure.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, departure)
+			}
+		})
+	}
+}
+
+func TestParseArrivalTime(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		value      string
+		travelMode string
+		expected   time.Time
+		expectErr  bool
+	}{
+		{
+			name:       "future time transit",
+			value:      "2030-01-01T08:30:00-05:00",
+			travelMode: travelModeTransit,
+			expected:   time.Date(2030, 1, 1, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "past time",
+			value:      "2029-12-31T23:00:00Z",
+			travelMode: travelModeTransit,
+			expectErr:  true,
+		},
+		{
+			name:       "drive mode",
+			value:      "2030-01-01T13:00:00Z",
+			travelMode: travelModeDrive,
+			expectErr:  true,
+		},
+		{
+			name:       "default mode",
+			value:      "2030-01-01T13:00:00Z",
+			travelMode: "",
+			expectErr:  true,
+		},
+		{
+			name:       "not RFC3339",
+			value:      "tomorrow at noon",
+			travelMode: travelModeTransit,
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrival, err := parseArrivalTime(tt.value, tt.travelMode, now)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !arrival.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, arrival)
+			}
+		})
+	}
+}
+
+func TestTrafficFreshness(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           routeOptions
+		expectedFresh  bool
+		expectedSource string
+	}{
+		{
+			name:           "default preference is traffic aware",
+			opts:           routeOptions{},
+			expectedFresh:  true,
+			expectedSource: "live traffic",
+		},
+		{
+			name:           "traffic aware optimal",
+			opts:           routeOptions{RoutingPreference: routingPreferenceTrafficAwareOptimal},
+			expectedFresh:  true,
+			expectedSource: "live traffic",
+		},
+		{
+			name: "future departure",
+			opts: routeOptions{
+				RoutingPreference: routingPreferenceTrafficAware,
+				DepartureTime:     time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC),
+			},
+			expectedFresh:  false,
+			expectedSource: "predicted for departure time",
+		},
+		{
+			name:           "traffic unaware",
+			opts:           routeOptions{RoutingPreference: routingPreferenceTrafficUnaware},
+			expectedFresh:  false,
+			expectedSource: "traffic-unaware routing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fresh, source := trafficFreshness(tt.opts)
+			if fresh != tt.expectedFresh {
+				t.Errorf("expected fresh=%v, got %v", tt.expectedFresh, fresh)
+			}
+			if source != tt.expectedSource {
+				t.Errorf("expected source %q, got %q", tt.expectedSource, source)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandler_DefaultTravelMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		envValue      string
+		args          map[string]interface{}
+		expectedMode  string
+		expectWarning bool
+	}{
+		{
+			name:         "unset defaults to drive",
+			expectedMode: travelModeDrive,
+		},
+		{
+			name:         "environment sets default",
+			envValue:     "WALK",
+			expectedMode: travelModeWalk,
+		},
+		{
+			name:         "environment value in any case",
+			envValue:     " transit ",
+			expectedMode: travelModeTransit,
+		},
+		{
+			name:          "invalid environment value falls back",
+			envValue:      "HOVERCRAFT",
+			expectedMode:  travelModeDrive,
+			expectWarning: true,
+		},
+		{
+			name:         "explicit argument overrides environment",
+			envValue:     "WALK",
+			args:         map[string]interface{}{"travelMode": "BICYCLE"},
+			expectedMode: travelModeBicycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEODISTANCE_DEFAULT_TRAVEL_MODE", tt.envValue)
+
+			var buf bytes.Buffer
+			handler, err := NewGeodistanceHandler(WithAPIKey("test-key"), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			opts, err := handler.parseRouteOptions(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.TravelMode != tt.expectedMode {
+				t.Errorf("expected travel mode %s, got %s", tt.expectedMode, opts.TravelMode)
+			}
+
+			body := handler.buildRequestBody([]Origin{{Address: "Omaha, NE"}}, []Destination{{Address: "Lincoln, NE"}}, opts)
+			if body.TravelMode != tt.expectedMode {
+				t.Errorf("expected request travel mode %s, got %s", tt.expectedMode, body.TravelMode)
+			}
+
+			warned := strings.Contains(buf.String(), "GEODISTANCE_DEFAULT_TRAVEL_MODE")
+			if warned != tt.expectWarning {
+				t.Errorf("expected warning=%v, log output: %q", tt.expectWarning, buf.String())
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_TwoWheeler(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expectErr string
+		expected  []string
+		absent    []string
+	}{
+		{
+			name: "traffic-aware by default",
+			args: map[string]interface{}{"travelMode": "two_wheeler"},
+			expected: []string{
+				`"travelMode":"TWO_WHEELER"`,
+				`"routingPreference":"TRAFFIC_AWARE"`,
+			},
+			absent: []string{`"requestedReferenceRoutes"`},
+		},
+		{
+			name: "optimal routing with avoidances",
+			args: map[string]interface{}{
+				"travelMode":        "TWO_WHEELER",
+				"routingPreference": "TRAFFIC_AWARE_OPTIMAL",
+				"avoidTolls":        true,
+				"avoidHighways":     true,
+			},
+			expected: []string{
+				`"travelMode":"TWO_WHEELER"`,
+				`"routingPreference":"TRAFFIC_AWARE_OPTIMAL"`,
+				`"routeModifiers":{"avoidTolls":true,"avoidHighways":true}`,
+			},
+			absent: []string{`"requestedReferenceRoutes"`},
+		},
+		{
+			name:      "avoidIndoor rejected",
+			args:      map[string]interface{}{"travelMode": "TWO_WHEELER", "avoidIndoor": true},
+			expectErr: "avoidIndoor is only supported for WALK",
+		},
+		{
+			name:      "transit preferences rejected",
+			args:      map[string]interface{}{"travelMode": "TWO_WHEELER", "transitPreferences": map[string]interface{}{"routingPreference": "LESS_WALKING"}},
+			expectErr: "transitPreferences require travel mode TRANSIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			opts, err := handler.parseRouteOptions(request)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body := handler.buildRequestBody([]Origin{{Address: "Hanoi"}}, []Destination{{Address: "Hai Phong"}}, opts)
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("expected %s in %s", want, data)
+				}
+			}
+			for _, unwanted := range tt.absent {
+				if strings.Contains(string(data), unwanted) {
+					t.Errorf("unexpected %s in %s", unwanted, data)
+				}
+			}
+		})
+	}
+}
+
+func TestSupportsRoutingPreference(t *testing.T) {
+	expected := map[string]bool{
+		"":                   true,
+		travelModeDrive:      true,
+		travelModeTwoWheeler: true,
+		travelModeBicycle:    false,
+		travelModeWalk:       false,
+		travelModeTransit:    false,
+	}
+	for mode, want := range expected {
+		if got := supportsRoutingPreference(mode); got != want {
+			t.Errorf("supportsRoutingPreference(%q) = %v, want %v", mode, got, want)
+		}
+	}
+}
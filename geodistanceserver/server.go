@@ -7,30 +7,437 @@ import (
 
 var Version = "dev"
 
+const serverName = "mcp-geodistance-server"
+
+const (
+	toolCalculateDistance       = "calculate_distance"
+	toolCalculateDistanceMatrix = "calculate_distance_matrix"
+	toolCalculateDistancesCSV   = "calculate_distances_csv"
+	toolNearestDestination      = "nearest_destination"
+	toolCompareTravelModes      = "compare_travel_modes"
+	toolGeocodeAddress          = "geocode_address"
+	toolGeocodeAddresses        = "geocode_addresses"
+	toolReverseGeocode          = "reverse_geocode"
+	toolPing                    = "ping"
+	toolHaversineDistance       = "haversine_distance"
+)
+
+// Server is an MCP server together with the handler backing its tools.
+// Call Close once the server has stopped serving.
+type Server struct {
+	*server.MCPServer
+	handler *GeodistanceHandler
+}
+
+// Close releases the resources held by the server's handler.
+func (s *Server) Close() error {
+	return s.handler.Close()
+}
+
+// GeodistanceServer builds the MCP server with a handler configured from
+// the environment. Use NewGeodistanceServer to pass options or to release
+// the handler's resources on shutdown.
 func GeodistanceServer() (*server.MCPServer, error) {
-	h, err := NewGeodistanceHandler()
+	s, err := NewGeodistanceServer()
 	if err != nil {
 		return nil, err
 	}
+	return s.MCPServer, nil
+}
+
+// NewGeodistanceServer builds the MCP server and registers every tool on a
+// handler created with opts.
+func NewGeodistanceServer(opts ...Option) (*Server, error) {
+	h, err := NewGeodistanceHandler(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	travelModeDescription := "Travel mode: DRIVE, BICYCLE, WALK, TWO_WHEELER or TRANSIT; defaults to " + h.travelModeDefault()
 
 	s := server.NewMCPServer(
-		"mcp-geodistance-server",
+		serverName,
 		Version,
 		server.WithResourceCapabilities(true, true),
+		server.WithToolHandlerMiddleware(withErrorCategory),
 	)
+	addTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		s.AddTool(tool, h.withKnownArguments(tool, handler))
+	}
 
-	s.AddTool(mcp.NewTool(
-		"calculate_distance",
+	addTool(mcp.NewTool(
+		toolCalculateDistance,
 		mcp.WithDescription("Calculate distance between origin and destination addresses."),
+		mcp.WithString("originAddress",
+			mcp.Description("Address of origin; alternatively give originLatitude and originLongitude"),
+		),
+		mcp.WithString("destinationAddress",
+			mcp.Description("Address of destination; alternatively give destinationLatitude and destinationLongitude"),
+		),
+		mcp.WithNumber("originLatitude",
+			mcp.Description("Latitude of origin in decimal degrees"),
+		),
+		mcp.WithNumber("originLongitude",
+			mcp.Description("Longitude of origin in decimal degrees"),
+		),
+		mcp.WithNumber("destinationLatitude",
+			mcp.Description("Latitude of destination in decimal degrees"),
+		),
+		mcp.WithNumber("destinationLongitude",
+			mcp.Description("Longitude of destination in decimal degrees"),
+		),
+		mcp.WithString("travelMode",
+			mcp.Description(travelModeDescription),
+			mcp.Enum(travelModes...),
+		),
+		mcp.WithObject("transitPreferences",
+			mcp.Description("TRANSIT only: allowedTravelModes (BUS, SUBWAY, TRAIN, LIGHT_RAIL, RAIL) and routingPreference (LESS_WALKING or FEWER_TRANSFERS)"),
+		),
+		mcp.WithString("units",
+			mcp.Description("Unit system for the distance: METRIC (default), IMPERIAL or NAUTICAL (nautical miles)"),
+			mcp.Enum(unitSystems...),
+		),
+		mcp.WithString("durationFormat",
+			mcp.Description("Duration rendering: HUMANIZED (default), SECONDS, MINUTES or HOURS_MINUTES"),
+			mcp.Enum(durationFormats...),
+		),
+		mcp.WithString("routingPreference",
+			mcp.Description("Routing preference: TRAFFIC_AWARE (default), TRAFFIC_AWARE_OPTIMAL or TRAFFIC_UNAWARE"),
+			mcp.Enum(routingPreferences...),
+		),
+		mcp.WithString("departureTime",
+			mcp.Description("Future departure time in RFC3339 format for traffic-aware ETAs"),
+		),
+		mcp.WithString("arrivalTime",
+			mcp.Description("Future arrival time in RFC3339 format; TRANSIT only and exclusive with departureTime"),
+		),
+		mcp.WithString("languageCode",
+			mcp.Description("BCP-47 language code for place names and labels (default en-US); when given, distances also use its number separators"),
+		),
+		mcp.WithString("regionCode",
+			mcp.Description("Two-letter region code (e.g. us, de) biasing how ambiguous addresses are resolved"),
+		),
+		mcp.WithBoolean("avoidTolls",
+			mcp.Description("Avoid toll roads where reasonable"),
+		),
+		mcp.WithBoolean("avoidHighways",
+			mcp.Description("Avoid highways where reasonable"),
+		),
+		mcp.WithBoolean("avoidFerries",
+			mcp.Description("Avoid ferries where reasonable"),
+		),
+		mcp.WithBoolean("avoidIndoor",
+			mcp.Description("WALK only: avoid indoor routes such as stairs and passages where reasonable"),
+		),
+		mcp.WithString("avoid",
+			mcp.Description("Comma-separated features to avoid where reasonable: tolls, highways, ferries and indoor (WALK only), e.g. tolls,ferries; combined with the avoid* booleans"),
+		),
+		mcp.WithString("emissionType",
+			mcp.Description("DRIVE only: vehicle emission type for fuel-efficient routing: GASOLINE, ELECTRIC, HYBRID or DIESEL"),
+			mcp.Enum(emissionTypes...),
+		),
+		mcp.WithBoolean("computeAlternativeRoutes",
+			mcp.Description("Return every route in the response, including alternatives, instead of only the first"),
+		),
+		mcp.WithString("preferLabel",
+			mcp.Description("Report the route with this label first: DEFAULT_ROUTE, SHORTER_DISTANCE or DEFAULT_ROUTE_ALTERNATE; falls back to the first route when none carries it. DEFAULT_ROUTE_ALTERNATE requires computeAlternativeRoutes; SHORTER_DISTANCE requires DRIVE and cannot be combined with distanceOnly"),
+			mcp.Enum(routeLabels...),
+		),
+		mcp.WithNumber("round",
+			mcp.Description("Round distances in text output to this many meters, e.g. 10, 100 or 1000; JSON output stays exact (default 0, no rounding)"),
+		),
+		mcp.WithBoolean("includeTrafficFreshness",
+			mcp.Description("Flag whether the duration is based on live traffic data"),
+		),
+		mcp.WithBoolean("distanceOnly",
+			mcp.Description("Report only the distance, skipping duration and traffic data for lower cost and latency"),
+		),
+		mcp.WithArray("waypoints",
+			mcp.Description("Ordered intermediate stop addresses between origin and destination"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("includePolyline",
+			mcp.Description("Include the encoded polyline of the route geometry"),
+		),
+		mcp.WithBoolean("includeTolls",
+			mcp.Description("Include the estimated toll cost of the route"),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("Caller-provided ID sent as X-Request-Id and echoed in logs and errors"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (default) or json with distanceMeters, durationSeconds and routeLabels"),
+			mcp.Enum(outputFormats...),
+		),
+		mcp.WithBoolean("roundTrip",
+			mcp.Description("Also route destination back to origin and report the summed trip plus each leg"),
+		),
+		mcp.WithBoolean("includeResolvedAddresses",
+			mcp.Description("Also report the addresses the origin, destination and any waypoints were resolved to, looked up with the Geocoding API at one extra call each"),
+		),
+		mcp.WithBoolean("showTiming",
+			mcp.Description("Append how long the Routes API call took, or that the result was served from cache"),
+		),
+		mcp.WithBoolean("debug",
+			mcp.Description("Append the estimated quota usage and the raw Routes API response, truncated to 4 KiB, for troubleshooting"),
+		),
+	), h.handleDistanceCalculation)
+
+	addTool(mcp.NewTool(
+		toolCalculateDistanceMatrix,
+		mcp.WithDescription("Calculate distances and durations for every origin/destination pair."),
+		mcp.WithArray("originAddresses",
+			mcp.Description("Origin addresses"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("destinationAddresses",
+			mcp.Description("Destination addresses"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("travelMode",
+			mcp.Description(travelModeDescription),
+			mcp.Enum(travelModes...),
+		),
+		mcp.WithObject("transitPreferences",
+			mcp.Description("TRANSIT only: allowedTravelModes (BUS, SUBWAY, TRAIN, LIGHT_RAIL, RAIL) and routingPreference (LESS_WALKING or FEWER_TRANSFERS)"),
+		),
+		mcp.WithString("units",
+			mcp.Description("Unit system for the distance: METRIC (default), IMPERIAL or NAUTICAL (nautical miles)"),
+			mcp.Enum(unitSystems...),
+		),
+		mcp.WithString("durationFormat",
+			mcp.Description("Duration rendering: HUMANIZED (default), SECONDS, MINUTES or HOURS_MINUTES"),
+			mcp.Enum(durationFormats...),
+		),
+		mcp.WithString("routingPreference",
+			mcp.Description("Routing preference: TRAFFIC_AWARE (default), TRAFFIC_AWARE_OPTIMAL or TRAFFIC_UNAWARE"),
+			mcp.Enum(routingPreferences...),
+		),
+		mcp.WithString("departureTime",
+			mcp.Description("Future departure time in RFC3339 format for traffic-aware ETAs"),
+		),
+		mcp.WithString("arrivalTime",
+			mcp.Description("Future arrival time in RFC3339 format; TRANSIT only and exclusive with departureTime"),
+		),
+		mcp.WithString("languageCode",
+			mcp.Description("BCP-47 language code for place names and labels (default en-US); when given, distances also use its number separators"),
+		),
+		mcp.WithString("regionCode",
+			mcp.Description("Two-letter region code (e.g. us, de) biasing how ambiguous addresses are resolved"),
+		),
+		mcp.WithBoolean("avoidTolls",
+			mcp.Description("Avoid toll roads where reasonable"),
+		),
+		mcp.WithBoolean("avoidHighways",
+			mcp.Description("Avoid highways where reasonable"),
+		),
+		mcp.WithBoolean("avoidFerries",
+			mcp.Description("Avoid ferries where reasonable"),
+		),
+		mcp.WithBoolean("avoidIndoor",
+			mcp.Description("WALK only: avoid indoor routes such as stairs and passages where reasonable"),
+		),
+		mcp.WithString("avoid",
+			mcp.Description("Comma-separated features to avoid where reasonable: tolls, highways, ferries and indoor (WALK only), e.g. tolls,ferries; combined with the avoid* booleans"),
+		),
+		mcp.WithString("emissionType",
+			mcp.Description("DRIVE only: vehicle emission type for fuel-efficient routing: GASOLINE, ELECTRIC, HYBRID or DIESEL"),
+			mcp.Enum(emissionTypes...),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("Caller-provided ID sent as X-Request-Id and echoed in logs and errors"),
+		),
+		mcp.WithNumber("round",
+			mcp.Description("Round distances in text output to this many meters, e.g. 10, 100 or 1000; JSON output stays exact (default 0, no rounding)"),
+		),
+		mcp.WithNumber("pageSize",
+			mcp.Description("Return at most this many origin rows, followed by a pageToken for the next rows when more remain"),
+		),
+		mcp.WithString("pageToken",
+			mcp.Description("Token from the previous page of the same matrix; requires pageSize"),
+		),
+		mcp.WithBoolean("autoSplit",
+			mcp.Description("Split matrices larger than the per-request limit into multiple requests instead of failing"),
+		),
+		mcp.WithBoolean("debug",
+			mcp.Description("Append the estimated quota usage for troubleshooting"),
+		),
+	), h.handleDistanceMatrix)
+
+	addTool(mcp.NewTool(
+		toolCalculateDistancesCSV,
+		mcp.WithDescription("Calculate distance and duration for each origin,destination row of a CSV document."),
+		mcp.WithString("csv",
+			mcp.Description("CSV text with origin,destination rows; fields may be quoted and an origin,destination header row is optional"),
+			mcp.Required(),
+		),
+		mcp.WithString("travelMode",
+			mcp.Description(travelModeDescription),
+			mcp.Enum(travelModes...),
+		),
+		mcp.WithString("routingPreference",
+			mcp.Description("Routing preference: TRAFFIC_AWARE (default), TRAFFIC_AWARE_OPTIMAL or TRAFFIC_UNAWARE"),
+			mcp.Enum(routingPreferences...),
+		),
+		mcp.WithString("departureTime",
+			mcp.Description("Future departure time in RFC3339 format for traffic-aware ETAs"),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("Caller-provided ID sent as X-Request-Id and echoed in logs and errors"),
+		),
+	), h.handleDistancesCSV)
+
+	addTool(mcp.NewTool(
+		toolNearestDestination,
+		mcp.WithDescription("Find the destination closest by route distance, or by travel time, to an origin address."),
 		mcp.WithString("originAddress",
 			mcp.Description("Address of origin"),
 			mcp.Required(),
 		),
+		mcp.WithArray("destinationAddresses",
+			mcp.Description("Candidate destination addresses"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("ranked",
+			mcp.Description("Also list every destination ranked by sortBy, with unreachable ones last"),
+		),
+		mcp.WithString("sortBy",
+			mcp.Description("Rank destinations by DISTANCE (default) or DURATION, ascending, with ties kept in input order; implies ranked"),
+			mcp.Enum(sortKeys...),
+		),
+		mcp.WithString("travelMode",
+			mcp.Description(travelModeDescription),
+			mcp.Enum(travelModes...),
+		),
+		mcp.WithString("units",
+			mcp.Description("Unit system for the distance: METRIC (default), IMPERIAL or NAUTICAL (nautical miles)"),
+			mcp.Enum(unitSystems...),
+		),
+		mcp.WithString("durationFormat",
+			mcp.Description("Duration rendering: HUMANIZED (default), SECONDS, MINUTES or HOURS_MINUTES"),
+			mcp.Enum(durationFormats...),
+		),
+		mcp.WithString("routingPreference",
+			mcp.Description("Routing preference: TRAFFIC_AWARE (default), TRAFFIC_AWARE_OPTIMAL or TRAFFIC_UNAWARE"),
+			mcp.Enum(routingPreferences...),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("Caller-provided ID sent as X-Request-Id and echoed in logs and errors"),
+		),
+	), h.handleNearestDestination)
+
+	addTool(mcp.NewTool(
+		toolCompareTravelModes,
+		mcp.WithDescription("Compare distance and duration of one trip across several travel modes."),
+		mcp.WithString("originAddress",
+			mcp.Description("Address of origin; alternatively give originLatitude and originLongitude"),
+		),
 		mcp.WithString("destinationAddress",
-			mcp.Description("Address of destination"),
+			mcp.Description("Address of destination; alternatively give destinationLatitude and destinationLongitude"),
+		),
+		mcp.WithNumber("originLatitude",
+			mcp.Description("Latitude of origin in decimal degrees"),
+		),
+		mcp.WithNumber("originLongitude",
+			mcp.Description("Longitude of origin in decimal degrees"),
+		),
+		mcp.WithNumber("destinationLatitude",
+			mcp.Description("Latitude of destination in decimal degrees"),
+		),
+		mcp.WithNumber("destinationLongitude",
+			mcp.Description("Longitude of destination in decimal degrees"),
+		),
+		mcp.WithArray("travelModes",
+			mcp.Description("Travel modes to compare"),
 			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string", "enum": travelModes}),
 		),
-	), h.handleDistanceCalculation)
+		mcp.WithString("units",
+			mcp.Description("Unit system for the distance: METRIC (default), IMPERIAL or NAUTICAL (nautical miles)"),
+			mcp.Enum(unitSystems...),
+		),
+		mcp.WithString("durationFormat",
+			mcp.Description("Duration rendering: HUMANIZED (default), SECONDS, MINUTES or HOURS_MINUTES"),
+			mcp.Enum(durationFormats...),
+		),
+		mcp.WithString("departureTime",
+			mcp.Description("Future departure time in RFC3339 format for traffic-aware ETAs"),
+		),
+		mcp.WithString("requestId",
+			mcp.Description("Caller-provided ID sent as X-Request-Id and echoed in logs and errors"),
+		),
+	), h.handleCompareTravelModes)
+
+	addTool(mcp.NewTool(
+		toolGeocodeAddress,
+		mcp.WithDescription("Resolve an address into latitude/longitude coordinates."),
+		mcp.WithString("address",
+			mcp.Description("Address to geocode"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("precision",
+			mcp.Description("Decimal places of the returned latitude and longitude, from 0 to 10 (default 6)"),
+		),
+	), h.handleGeocode)
+
+	addTool(mcp.NewTool(
+		toolGeocodeAddresses,
+		mcp.WithDescription("Resolve many addresses into latitude/longitude coordinates at once. Each address is reported in input order, with its own error if it cannot be resolved."),
+		mcp.WithArray("addresses",
+			mcp.Description("Addresses to geocode, at most 100"),
+			mcp.Required(),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("precision",
+			mcp.Description("Decimal places of the returned latitudes and longitudes, from 0 to 10 (default 6)"),
+		),
+	), h.handleGeocodeAddresses)
+
+	addTool(mcp.NewTool(
+		toolReverseGeocode,
+		mcp.WithDescription("Resolve latitude/longitude coordinates into the nearest address."),
+		mcp.WithNumber("latitude",
+			mcp.Description("Latitude in decimal degrees, between -90 and 90"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("longitude",
+			mcp.Description("Longitude in decimal degrees, between -180 and 180"),
+			mcp.Required(),
+		),
+	), h.handleReverseGeocode)
+
+	addTool(mcp.NewTool(
+		toolPing,
+		mcp.WithDescription("Check that the Routes API is reachable and the API key is accepted."),
+	), h.handlePing)
+
+	addTool(mcp.NewTool(
+		toolHaversineDistance,
+		mcp.WithDescription("Compute the straight-line (great-circle) distance between two coordinate pairs locally, without calling any API."),
+		mcp.WithNumber("originLatitude",
+			mcp.Description("Origin latitude in decimal degrees, between -90 and 90"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("originLongitude",
+			mcp.Description("Origin longitude in decimal degrees, between -180 and 180"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("destinationLatitude",
+			mcp.Description("Destination latitude in decimal degrees, between -90 and 90"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("destinationLongitude",
+			mcp.Description("Destination longitude in decimal degrees, between -180 and 180"),
+			mcp.Required(),
+		),
+		mcp.WithString("units",
+			mcp.Description("Unit system for the distance: METRIC (default), IMPERIAL or NAUTICAL (nautical miles)"),
+			mcp.Enum(unitSystems...),
+		),
+	), h.handleHaversineDistance)
 
-	return s, nil
+	return &Server{MCPServer: s, handler: h}, nil
 }
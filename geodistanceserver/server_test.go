@@ -1,8 +1,14 @@
 package geodistanceserver
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"reflect"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestGeodistanceServer(t *testing.T) {
@@ -154,9 +160,8 @@ func TestGeodistanceServer_HandlerCreationFailure(t *testing.T) {
 	}
 
 	// Error should be related to missing API key
-	expectedErrMsg := "GOOGLE_API_KEY environment variable not set"
-	if err.Error() != expectedErrMsg {
-		t.Errorf("expected error message %q, got %q", expectedErrMsg, err.Error())
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Errorf("expected ErrMissingAPIKey, got %v", err)
 	}
 }
 
@@ -201,3 +206,107 @@ func BenchmarkGeodistanceServer(b *testing.B) {
 		}
 	}
 }
+
+// listTools returns the tools a client sees from tools/list, keyed by name.
+func listTools(t *testing.T) map[string]mcp.Tool {
+	t.Helper()
+	t.Setenv("GOOGLE_API_KEY", "test-api-key")
+
+	s, err := GeodistanceServer()
+	if err != nil {
+		t.Fatalf("unexpected error creating server: %v", err)
+	}
+
+	message := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatalf("expected a JSON-RPC response, got %#v", message)
+	}
+	result, ok := response.Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("expected a tools/list result, got %#v", response.Result)
+	}
+
+	tools := make(map[string]mcp.Tool, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+func TestGeodistanceServer_ToolSchemas(t *testing.T) {
+	tools := listTools(t)
+
+	tests := []struct {
+		tool       string
+		parameters []string
+		enums      map[string][]string
+	}{
+		{
+			tool: toolCalculateDistance,
+			parameters: []string{
+				"originAddress", "destinationAddress", "travelMode", "units", "durationFormat",
+				"routingPreference", "departureTime", "avoidTolls", "avoidHighways", "avoidFerries",
+				"waypoints", "includePolyline", "includeTolls", "format", "preferLabel", "showTiming", "avoid", "includeResolvedAddresses",
+			},
+			enums: map[string][]string{
+				"travelMode":        travelModes,
+				"units":             unitSystems,
+				"durationFormat":    durationFormats,
+				"routingPreference": routingPreferences,
+				"format":            outputFormats,
+				"preferLabel":       routeLabels,
+				"emissionType":      emissionTypes,
+			},
+		},
+		{
+			tool:       toolCalculateDistanceMatrix,
+			parameters: []string{"originAddresses", "destinationAddresses", "travelMode", "units", "pageSize", "pageToken", "autoSplit"},
+			enums: map[string][]string{
+				"travelMode":        travelModes,
+				"units":             unitSystems,
+				"routingPreference": routingPreferences,
+			},
+		},
+		{
+			tool:       toolCalculateDistancesCSV,
+			parameters: []string{"csv", "travelMode"},
+			enums:      map[string][]string{"travelMode": travelModes},
+		},
+		{
+			tool:       toolNearestDestination,
+			parameters: []string{"originAddress", "destinationAddresses", "ranked", "sortBy", "durationFormat"},
+			enums:      map[string][]string{"sortBy": sortKeys, "units": unitSystems, "durationFormat": durationFormats},
+		},
+		{tool: toolGeocodeAddress, parameters: []string{"address"}},
+		{tool: toolGeocodeAddresses, parameters: []string{"addresses", "precision"}},
+		{tool: toolReverseGeocode, parameters: []string{"latitude", "longitude"}},
+		{tool: toolPing},
+		{
+			tool:       toolHaversineDistance,
+			parameters: []string{"originLatitude", "originLongitude", "destinationLatitude", "destinationLongitude", "units"},
+			enums:      map[string][]string{"units": unitSystems},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tool, ok := tools[tt.tool]
+			if !ok {
+				t.Fatalf("tool %s not registered", tt.tool)
+			}
+
+			for _, name := range tt.parameters {
+				if _, ok := tool.InputSchema.Properties[name]; !ok {
+					t.Errorf("parameter %q not declared", name)
+				}
+			}
+			for name, values := range tt.enums {
+				property, _ := tool.InputSchema.Properties[name].(map[string]any)
+				if enum, _ := property["enum"].([]string); !reflect.DeepEqual(enum, values) {
+					t.Errorf("parameter %q: expected enum %v, got %v", name, values, property["enum"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxArgumentSuggestionDistance is the largest edit distance at which an
+// unknown argument is matched to a declared one as a likely typo.
+const maxArgumentSuggestionDistance = 2
+
+// withKnownArguments wraps the handler of tool so that, with strict
+// argument checking enabled, a call naming an argument the tool's schema
+// does not declare fails before the handler runs.
+func (gh *GeodistanceHandler) withKnownArguments(tool mcp.Tool, handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	if !gh.strictArguments {
+		return handler
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := checkKnownArguments(request, tool); err != nil {
+			return nil, invalidArgument(err)
+		}
+		return handler(ctx, request)
+	}
+}
+
+// checkKnownArguments rejects arguments tool does not declare, suggesting
+// the declared argument each one is most likely a misspelling of.
+func checkKnownArguments(request mcp.CallToolRequest, tool mcp.Tool) error {
+	var unknown []string
+	for key := range request.GetArguments() {
+		if _, ok := tool.InputSchema.Properties[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+
+	if len(unknown) == 1 {
+		return fmt.Errorf("unknown argument %q for %s%s", unknown[0], tool.Name, argumentSuggestion(unknown[0], tool))
+	}
+	described := make([]string, len(unknown))
+	for i, key := range unknown {
+		described[i] = fmt.Sprintf("%q%s", key, argumentSuggestion(key, tool))
+	}
+	return fmt.Errorf("unknown arguments for %s: %s", tool.Name, strings.Join(described, ", "))
+}
+
+// argumentSuggestion returns a " (did you mean ...?)" hint for key, or ""
+// when no declared argument is close to it.
+func argumentSuggestion(key string, tool mcp.Tool) string {
+	if suggestion := closestArgument(key, tool); suggestion != "" {
+		return fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return ""
+}
+
+// closestArgument returns the declared argument of tool nearest to key,
+// ignoring case, or "" when none is close enough to be a typo.
+func closestArgument(key string, tool mcp.Tool) string {
+	best, bestDistance := "", maxArgumentSuggestionDistance+1
+	for name := range tool.InputSchema.Properties {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(name))
+		if distance < bestDistance || (distance == bestDistance && name < best) {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
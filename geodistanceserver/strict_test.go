@@ -0,0 +1,113 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNewGeodistanceServer_StrictArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		env       string
+		args      string
+		expectErr string
+	}{
+		{
+			name: "unknown argument ignored by default",
+			args: `{"originAddress":"New York","destinationAddress":"Boston","orignAddress":"Albany"}`,
+		},
+		{
+			name:      "misspelled argument",
+			opts:      []Option{WithStrictArguments(true)},
+			args:      `{"orignAddress":"New York","destinationAddress":"Boston"}`,
+			expectErr: `unknown argument "orignAddress" for calculate_distance (did you mean "originAddress"?)`,
+		},
+		{
+			name:      "several unknown arguments",
+			opts:      []Option{WithStrictArguments(true)},
+			args:      `{"originAddress":"New York","destinationAddress":"Boston","TravelMode":"WALK","color":"red"}`,
+			expectErr: `unknown arguments for calculate_distance: "TravelMode" (did you mean "travelMode"?), "color"`,
+		},
+		{
+			name:      "enabled from the environment",
+			env:       "true",
+			args:      `{"originAddress":"New York","destinationAddress":"Boston","color":"red"}`,
+			expectErr: `unknown argument "color" for calculate_distance`,
+		},
+		{
+			name: "option overrides the environment",
+			opts: []Option{WithStrictArguments(false)},
+			env:  "true",
+			args: `{"originAddress":"New York","destinationAddress":"Boston","color":"red"}`,
+		},
+		{
+			name: "known arguments accepted",
+			opts: []Option{WithStrictArguments(true)},
+			args: `{"originAddress":"New York","destinationAddress":"Boston","travelMode":"DRIVE","units":"METRIC"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GEODISTANCE_STRICT_ARGUMENTS", tt.env)
+			client := &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+				return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+			}}
+			opts := append([]Option{WithAPIKey("test-key"), WithHTTPClient(client)}, tt.opts...)
+			s, err := NewGeodistanceServer(opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			message := s.HandleMessage(context.Background(), json.RawMessage(
+				`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"calculate_distance","arguments":`+tt.args+`}}`))
+			if tt.expectErr != "" {
+				response, ok := message.(mcp.JSONRPCError)
+				if !ok {
+					t.Fatalf("expected a JSON-RPC error, got %#v", message)
+				}
+				if !strings.Contains(response.Error.Message, tt.expectErr) {
+					t.Errorf("expected error containing %q, got %q", tt.expectErr, response.Error.Message)
+				}
+				return
+			}
+			if _, ok := message.(mcp.JSONRPCResponse); !ok {
+				t.Errorf("expected a JSON-RPC response, got %#v", message)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandler_InvalidStrictArgumentsEnv(t *testing.T) {
+	t.Setenv("GEODISTANCE_STRICT_ARGUMENTS", "sometimes")
+
+	if _, err := NewGeodistanceHandlerWithKey("test-key", &MockHTTPClient{}); err == nil {
+		t.Error("expected error for an invalid GEODISTANCE_STRICT_ARGUMENTS")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"units", "units", 0},
+		{"", "units", 5},
+		{"orignaddress", "originaddress", 1},
+		{"travelmod", "travelmode", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
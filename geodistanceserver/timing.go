@@ -0,0 +1,15 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"time"
+)
+
+// timingText reports how long the upstream call behind a result took, for
+// the showTiming argument. Cached results made no call at all.
+func timingText(elapsed time.Duration, cached bool) string {
+	if cached {
+		return "Served from cache; no API call was made"
+	}
+	return fmt.Sprintf("API call took %s", elapsed.Round(time.Millisecond))
+}
@@ -0,0 +1,91 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_ShowTiming(t *testing.T) {
+	const delay = 50 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		timed    bool
+		cacheHit bool
+	}{
+		{name: "not requested", args: map[string]interface{}{}},
+		{name: "single route", args: map[string]interface{}{"showTiming": true}, timed: true},
+		{name: "round trip", args: map[string]interface{}{"showTiming": true, "roundTrip": true}, timed: true},
+		{name: "cache hit", args: map[string]interface{}{"showTiming": true}, timed: true, cacheHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					time.Sleep(delay)
+					return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+				}},
+			}
+			if tt.cacheHit {
+				handler.cache = newRouteCache(10, 0)
+			}
+
+			args := map[string]interface{}{"originAddress": "New York", "destinationAddress": "Los Angeles"}
+			for key, value := range tt.args {
+				args[key] = value
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: args},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if tt.cacheHit && err == nil {
+				result, err = handler.handleDistanceCalculation(context.Background(), request)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			last := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+			switch {
+			case !tt.timed:
+				if strings.Contains(last, "API call") || strings.Contains(last, "cache") {
+					t.Errorf("expected no timing without showTiming, got %q", last)
+				}
+			case tt.cacheHit:
+				if last != "Served from cache; no API call was made" {
+					t.Errorf("expected the cache hit to be reported, got %q", last)
+				}
+			default:
+				took, ok := strings.CutPrefix(last, "API call took ")
+				if !ok {
+					t.Fatalf("expected timing content, got %q", last)
+				}
+				elapsed, err := time.ParseDuration(took)
+				if err != nil {
+					t.Fatalf("unparsable timing %q: %v", took, err)
+				}
+				if elapsed < delay {
+					t.Errorf("expected at least %v, got %v", delay, elapsed)
+				}
+			}
+		})
+	}
+}
+
+func TestTimingText(t *testing.T) {
+	if got := timingText(230*time.Millisecond+400*time.Microsecond, false); got != "API call took 230ms" {
+		t.Errorf("expected rounding to milliseconds, got %q", got)
+	}
+	if got := timingText(time.Second, true); !strings.Contains(got, "cache") {
+		t.Errorf("expected cached results to say so, got %q", got)
+	}
+}
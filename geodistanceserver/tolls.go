@@ -0,0 +1,45 @@
+package geodistanceserver
+
+import "strings"
+
+const extraComputationTolls = "TOLLS"
+
+// TravelAdvisory carries additional information about a route, such as
+// toll costs.
+type TravelAdvisory struct {
+	TollInfo *TollInfo `json:"tollInfo,omitempty"`
+}
+
+// TollInfo holds the estimated toll price, one entry per currency.
+type TollInfo struct {
+	EstimatedPrice []Money `json:"estimatedPrice,omitempty"`
+}
+
+// Money is a google.type.Money amount: whole units plus nanos (10^-9) of
+// the currency.
+type Money struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        int64  `json:"units,string,omitempty"`
+	Nanos        int32  `json:"nanos,omitempty"`
+}
+
+// String renders the amount in the currency's decimal places, e.g.
+// "USD 4.50".
+func (m Money) String() string {
+	return formatMoney(m.CurrencyCode, m.Units, m.Nanos)
+}
+
+// formatTolls renders the estimated toll prices of a route. Routes without
+// toll info either have no tolls or none the API could estimate.
+func formatTolls(route Route) string {
+	if route.TravelAdvisory == nil || route.TravelAdvisory.TollInfo == nil ||
+		len(route.TravelAdvisory.TollInfo.EstimatedPrice) == 0 {
+		return "none reported"
+	}
+
+	prices := make([]string, len(route.TravelAdvisory.TollInfo.EstimatedPrice))
+	for i, price := range route.TravelAdvisory.TollInfo.EstimatedPrice {
+		prices[i] = price.String()
+	}
+	return strings.Join(prices, " / ")
+}
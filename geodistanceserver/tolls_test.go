@@ -0,0 +1,155 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMoney_UnmarshalAndString(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{
+			name:     "units and nanos",
+			json:     `{"currencyCode":"USD","units":"4","nanos":500000000}`,
+			expected: "USD 4.50",
+		},
+		{
+			name:     "nanos only",
+			json:     `{"currencyCode":"EUR","nanos":750000000}`,
+			expected: "EUR 0.75",
+		},
+		{
+			name:     "whole units",
+			json:     `{"currencyCode":"USD","units":"12"}`,
+			expected: "USD 12.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Money
+			if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := m.String(); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatTolls(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    Route
+		expected string
+	}{
+		{
+			name:     "no travel advisory",
+			route:    Route{},
+			expected: "none reported",
+		},
+		{
+			name:     "no toll info",
+			route:    Route{TravelAdvisory: &TravelAdvisory{}},
+			expected: "none reported",
+		},
+		{
+			name: "single currency",
+			route: Route{TravelAdvisory: &TravelAdvisory{TollInfo: &TollInfo{
+				EstimatedPrice: []Money{{CurrencyCode: "USD", Units: 4, Nanos: 500000000}},
+			}}},
+			expected: "USD 4.50",
+		},
+		{
+			name: "multiple currencies",
+			route: Route{TravelAdvisory: &TravelAdvisory{TollInfo: &TollInfo{
+				EstimatedPrice: []Money{
+					{CurrencyCode: "USD", Units: 4, Nanos: 500000000},
+					{CurrencyCode: "CAD", Units: 6},
+				},
+			}}},
+			expected: "USD 4.50 / CAD 6.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTolls(tt.route); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_Tolls(t *testing.T) {
+	tollResponse := `{"routes":[{"distanceMeters":1000,"duration":"300s","routeLabels":["DEFAULT_ROUTE"],
+		"travelAdvisory":{"tollInfo":{"estimatedPrice":[{"currencyCode":"USD","units":"4","nanos":500000000}]}}}]}`
+
+	tests := []struct {
+		name         string
+		includeTolls bool
+		body         string
+		expectedLine string
+	}{
+		{name: "not requested", includeTolls: false, body: tollResponse},
+		{name: "requested", includeTolls: true, body: tollResponse, expectedLine: "Estimated tolls: USD 4.50"},
+		{name: "requested without toll info", includeTolls: true, body: createValidAPIResponse(), expectedLine: "Estimated tolls: none reported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if strings.Contains(req.Header.Get("X-Goog-FieldMask"), tollsFieldMask) != tt.includeTolls {
+						t.Errorf("unexpected field mask %q", req.Header.Get("X-Goog-FieldMask"))
+					}
+					var body RequestBody
+					if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+						return nil, err
+					}
+					if (len(body.ExtraComputations) > 0) != tt.includeTolls {
+						t.Errorf("unexpected extraComputations %v", body.ExtraComputations)
+					}
+					return createMockResponse(http.StatusOK, tt.body), nil
+				}},
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "calculate_distance",
+					Arguments: map[string]interface{}{
+						"originAddress":      "Newark, NJ",
+						"destinationAddress": "New York, NY",
+						"includeTolls":       tt.includeTolls,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectedLine == "" {
+				if strings.Contains(text, "Estimated tolls") {
+					t.Errorf("expected no toll line, got %q", text)
+				}
+				return
+			}
+			if !strings.Contains(text, tt.expectedLine) {
+				t.Errorf("expected %q in %q", tt.expectedLine, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package geodistanceserver
+
+import (
+	"context"
+	"log/slog"
+)
+
+// MissingTrafficBehavior controls how a traffic-aware request is answered
+// when the API returns routes without a traffic-adjusted duration.
+type MissingTrafficBehavior int
+
+const (
+	// MissingTrafficLabel reports the free-flow duration in place of the
+	// missing one, labelled as not reflecting traffic.
+	MissingTrafficLabel MissingTrafficBehavior = iota
+	// MissingTrafficRetryUnaware repeats the request once as
+	// TRAFFIC_UNAWARE and reports its durations, labelled as not
+	// reflecting traffic. If the retry fails, the routes are labelled as
+	// with MissingTrafficLabel.
+	MissingTrafficRetryUnaware
+)
+
+// trafficNoticeText marks a duration that does not reflect traffic although
+// traffic-aware routing was requested.
+const trafficNoticeText = "no traffic data"
+
+// missingTraffic reports whether any route of a traffic-aware response
+// lacks its duration. Distance-only calls do not request one.
+func missingTraffic(body *RequestBody, opts routeOptions, responseBody *ResponseBody) bool {
+	if !isTrafficAware(body.RoutingPreference) || opts.DistanceOnly {
+		return false
+	}
+	for _, route := range responseBody.Routes {
+		if route.Duration == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// degradeMissingTraffic applies gh's MissingTrafficBehavior to a response
+// missing traffic-adjusted durations, returning it unchanged otherwise.
+func (gh *GeodistanceHandler) degradeMissingTraffic(
+	ctx context.Context,
+	body *RequestBody,
+	fieldMask string,
+	opts routeOptions,
+	responseBody *ResponseBody,
+) *ResponseBody {
+	if !missingTraffic(body, opts, responseBody) {
+		return responseBody
+	}
+
+	if gh.missingTraffic == MissingTrafficRetryUnaware {
+		unaware := *body
+		unaware.RoutingPreference = routingPreferenceTrafficUnaware
+		// Departure times are only accepted for traffic-aware routing
+		unaware.DepartureTime = ""
+
+		retried, err := gh.computeRoutes(ctx, &unaware, fieldMask)
+		if err == nil && len(retried.Routes) > 0 {
+			labelMissingTraffic(retried, true)
+			return retried
+		}
+		if gh.logger != nil {
+			var attrs []slog.Attr
+			if id := RequestIDFromContext(ctx); id != "" {
+				attrs = append(attrs, slog.String("requestId", id))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			gh.logger.LogAttrs(ctx, slog.LevelWarn, "traffic-unaware retry failed; labelling durations instead", attrs...)
+		}
+	}
+
+	labelMissingTraffic(responseBody, false)
+	return responseBody
+}
+
+// labelMissingTraffic marks routes whose duration does not reflect
+// traffic: every route when all is set, else only those without a
+// duration, which fall back to their free-flow duration. A marked route
+// has no separate free-flow duration to report.
+func labelMissingTraffic(responseBody *ResponseBody, all bool) {
+	for i := range responseBody.Routes {
+		route := &responseBody.Routes[i]
+		if route.Duration == "" {
+			route.Duration = route.StaticDuration
+		} else if !all {
+			continue
+		}
+		route.StaticDuration = ""
+		route.trafficUnavailable = true
+	}
+}
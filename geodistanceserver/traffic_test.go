@@ -0,0 +1,169 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// missingTrafficDoFunc answers traffic-aware requests with a route lacking
+// its traffic-adjusted duration, and traffic-unaware ones normally. When
+// failUnaware is set, traffic-unaware requests fail instead.
+func missingTrafficDoFunc(calls *int32, failUnaware bool) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(calls, 1)
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		var body RequestBody
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, err
+		}
+
+		if body.RoutingPreference == routingPreferenceTrafficUnaware {
+			if failUnaware {
+				return createMockResponse(http.StatusInternalServerError, "backend error"), nil
+			}
+			return createMockResponse(http.StatusOK,
+				`{"routes":[{"distanceMeters":94475,"duration":"3100s","routeLabels":["DEFAULT_ROUTE"]}]}`), nil
+		}
+		return createMockResponse(http.StatusOK,
+			`{"routes":[{"distanceMeters":94475,"staticDuration":"3000s","routeLabels":["DEFAULT_ROUTE"]}]}`), nil
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_MissingTraffic(t *testing.T) {
+	tests := []struct {
+		name          string
+		behavior      MissingTrafficBehavior
+		failUnaware   bool
+		expectedCalls int32
+		expected      string
+	}{
+		{
+			name:          "label",
+			behavior:      MissingTrafficLabel,
+			expectedCalls: 1,
+			expected:      "Route distance: 94.47 km (94475 meters), Duration: 3000 seconds (no traffic data)",
+		},
+		{
+			name:          "retry unaware",
+			behavior:      MissingTrafficRetryUnaware,
+			expectedCalls: 2,
+			expected:      "Route distance: 94.47 km (94475 meters), Duration: 3100 seconds (no traffic data)",
+		},
+		{
+			name:          "failed retry labels",
+			behavior:      MissingTrafficRetryUnaware,
+			failUnaware:   true,
+			expectedCalls: 2,
+			expected:      "Route distance: 94.47 km (94475 meters), Duration: 3000 seconds (no traffic data)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			handler := &GeodistanceHandler{
+				apiKey:         "test-key",
+				client:         &MockHTTPClient{DoFunc: missingTrafficDoFunc(&calls, tt.failUnaware)},
+				missingTraffic: tt.behavior,
+			}
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: toolCalculateDistance,
+					Arguments: map[string]interface{}{
+						"originAddress":      "Omaha, NE",
+						"destinationAddress": "Lincoln, NE",
+						"durationFormat":     durationFormatSeconds,
+					},
+				},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("expected %d API calls, got %d", tt.expectedCalls, calls)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.HasPrefix(text, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_MissingTrafficJSON(t *testing.T) {
+	var calls int32
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: missingTrafficDoFunc(&calls, false)},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: toolCalculateDistance,
+			Arguments: map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "Lincoln, NE",
+				"format":             "json",
+			},
+		},
+	}
+
+	result, err := handler.handleDistanceCalculation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var route RouteJSON
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &route); err != nil {
+		t.Fatalf("failed to decode JSON output: %v", err)
+	}
+	if !route.TrafficUnavailable {
+		t.Error("expected trafficUnavailable to be set")
+	}
+	if route.DurationSeconds == nil || *route.DurationSeconds != 3000 {
+		t.Errorf("expected the free-flow duration of 3000 seconds, got %v", route.DurationSeconds)
+	}
+	if route.StaticDurationSeconds != nil {
+		t.Errorf("expected no separate static duration, got %v", *route.StaticDurationSeconds)
+	}
+}
+
+func TestMissingTraffic(t *testing.T) {
+	withoutDuration := &ResponseBody{Routes: []Route{{DistanceMeters: 1000, StaticDuration: "60s"}}}
+	withDuration := &ResponseBody{Routes: []Route{{DistanceMeters: 1000, Duration: "90s", StaticDuration: "60s"}}}
+
+	tests := []struct {
+		name     string
+		body     *RequestBody
+		opts     routeOptions
+		response *ResponseBody
+		expected bool
+	}{
+		{"traffic aware without duration", &RequestBody{RoutingPreference: routingPreferenceTrafficAware}, routeOptions{}, withoutDuration, true},
+		{"optimal without duration", &RequestBody{RoutingPreference: routingPreferenceTrafficAwareOptimal}, routeOptions{}, withoutDuration, true},
+		{"traffic aware with duration", &RequestBody{RoutingPreference: routingPreferenceTrafficAware}, routeOptions{}, withDuration, false},
+		{"traffic unaware", &RequestBody{RoutingPreference: routingPreferenceTrafficUnaware}, routeOptions{}, withoutDuration, false},
+		{"distance only", &RequestBody{RoutingPreference: routingPreferenceTrafficAware}, routeOptions{DistanceOnly: true}, withoutDuration, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingTraffic(tt.body, tt.opts, tt.response); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var transitTravelModes = []string{"BUS", "SUBWAY", "TRAIN", "LIGHT_RAIL", "RAIL"}
+
+var transitRoutingPreferences = []string{"LESS_WALKING", "FEWER_TRANSFERS"}
+
+// TransitPreferences narrows the transit routes returned for TRANSIT
+// travel.
+type TransitPreferences struct {
+	AllowedTravelModes []string `json:"allowedTravelModes,omitempty"`
+	RoutingPreference  string   `json:"routingPreference,omitempty"`
+}
+
+// parseTransitPreferences reads the optional transitPreferences object
+// argument, which is only valid for the TRANSIT travel mode.
+func parseTransitPreferences(request mcp.CallToolRequest, travelMode string) (*TransitPreferences, error) {
+	value, ok := request.GetArguments()["transitPreferences"]
+	if !ok {
+		return nil, nil
+	}
+	if travelMode != travelModeTransit {
+		return nil, fmt.Errorf("transitPreferences require travel mode %s, got %s", travelModeTransit, travelModeOrDefault(travelMode))
+	}
+
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("transitPreferences must be an object")
+	}
+
+	prefs := &TransitPreferences{}
+	if modes, ok := raw["allowedTravelModes"]; ok {
+		list, ok := modes.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("transitPreferences.allowedTravelModes must be an array of strings")
+		}
+		for _, m := range list {
+			mode, ok := m.(string)
+			if !ok || !slices.Contains(transitTravelModes, mode) {
+				return nil, fmt.Errorf("invalid transit travel mode %v: must be one of %v", m, transitTravelModes)
+			}
+			prefs.AllowedTravelModes = append(prefs.AllowedTravelModes, mode)
+		}
+	}
+	if pref, ok := raw["routingPreference"]; ok {
+		preference, ok := pref.(string)
+		if !ok || !slices.Contains(transitRoutingPreferences, preference) {
+			return nil, fmt.Errorf("invalid transit routing preference %v: must be one of %v", pref, transitRoutingPreferences)
+		}
+		prefs.RoutingPreference = preference
+	}
+
+	return prefs, nil
+}
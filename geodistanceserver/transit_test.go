@@ -0,0 +1,148 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_parseRouteOptions_TransitPreferences(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name         string
+		args         map[string]interface{}
+		expectErr    bool
+		expectedJSON string
+	}{
+		{
+			name: "transit preferences serialized",
+			args: map[string]interface{}{
+				"travelMode": "TRANSIT",
+				"transitPreferences": map[string]interface{}{
+					"allowedTravelModes": []interface{}{"BUS", "SUBWAY"},
+					"routingPreference":  "LESS_WALKING",
+				},
+			},
+			expectedJSON: `"transitPreferences":{"allowedTravelModes":["BUS","SUBWAY"],"routingPreference":"LESS_WALKING"}`,
+		},
+		{
+			name: "rejected for DRIVE",
+			args: map[string]interface{}{
+				"travelMode": "DRIVE",
+				"transitPreferences": map[string]interface{}{
+					"routingPreference": "FEWER_TRANSFERS",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "rejected for default travel mode",
+			args: map[string]interface{}{
+				"transitPreferences": map[string]interface{}{
+					"routingPreference": "FEWER_TRANSFERS",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown transit mode",
+			args: map[string]interface{}{
+				"travelMode": "TRANSIT",
+				"transitPreferences": map[string]interface{}{
+					"allowedTravelModes": []interface{}{"FERRY"},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown transit routing preference",
+			args: map[string]interface{}{
+				"travelMode": "TRANSIT",
+				"transitPreferences": map[string]interface{}{
+					"routingPreference": "FASTEST",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "not an object",
+			args: map[string]interface{}{
+				"travelMode":         "TRANSIT",
+				"transitPreferences": "BUS",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "routing preference rejected for TRANSIT",
+			args:      map[string]interface{}{"travelMode": "TRANSIT", "routingPreference": "TRAFFIC_AWARE"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid travel mode",
+			args:      map[string]interface{}{"travelMode": "FLY"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+
+			opts, err := handler.parseRouteOptions(request)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := json.Marshal(handler.buildRequestBody([]Origin{{Address: "A"}}, []Destination{{Address: "B"}}, opts))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(data), tt.expectedJSON) {
+				t.Errorf("expected %s in %s", tt.expectedJSON, data)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_buildRequestBody_TravelMode(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name              string
+		travelMode        string
+		expectedMode      string
+		expectPreferences bool
+	}{
+		{name: "default is DRIVE", travelMode: "", expectedMode: travelModeDrive, expectPreferences: true},
+		{name: "walking", travelMode: travelModeWalk, expectedMode: travelModeWalk, expectPreferences: false},
+		{name: "transit", travelMode: travelModeTransit, expectedMode: travelModeTransit, expectPreferences: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := handler.buildRequestBody(nil, nil, routeOptions{TravelMode: tt.travelMode})
+
+			if body.TravelMode != tt.expectedMode {
+				t.Errorf("expected travel mode %s, got %s", tt.expectedMode, body.TravelMode)
+			}
+			if (body.RoutingPreference != "") != tt.expectPreferences {
+				t.Errorf("unexpected routing preference %q for %s", body.RoutingPreference, tt.expectedMode)
+			}
+			if (len(body.RequestedReferenceRoutes) > 0) != tt.expectPreferences {
+				t.Errorf("unexpected reference routes %v for %s", body.RequestedReferenceRoutes, tt.expectedMode)
+			}
+		})
+	}
+}
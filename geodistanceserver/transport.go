@@ -0,0 +1,56 @@
+package geodistanceserver
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Connection pool defaults of the default HTTP client. Nearly every request
+// goes to the one Google host, so most idle connections are kept for it:
+// enough for concurrent matrix chunks with headroom.
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = maxMatrixConcurrency * 2
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// connectionPool is the idle connection tuning set by WithConnectionPool;
+// zero fields keep the defaults.
+type connectionPool struct {
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// newDefaultTransport builds the transport of the default HTTP client. It
+// honours HTTP_PROXY, HTTPS_PROXY and NO_PROXY, and keeps enough idle
+// connections to the single Google host for concurrent matrix chunks.
+func newDefaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          defaultMaxIdleConns,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// apply overrides the transport's pool settings with the non-zero fields.
+func (p connectionPool) apply(transport *http.Transport) {
+	if p.maxIdleConns > 0 {
+		transport.MaxIdleConns = p.maxIdleConns
+	}
+	if p.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = p.maxIdleConnsPerHost
+	}
+	if p.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = p.idleConnTimeout
+	}
+}
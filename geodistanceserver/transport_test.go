@@ -0,0 +1,100 @@
+package geodistanceserver
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewGeodistanceHandler_DefaultTransport(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "test-api-key")
+
+	handler, err := NewGeodistanceHandler()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client, ok := handler.client.(*http.Client)
+	if !ok {
+		t.Fatalf("expected *http.Client, got %T", handler.client)
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", client.Transport)
+	}
+	if transport.Proxy == nil {
+		t.Fatal("expected a proxy function")
+	}
+	if reflect.ValueOf(transport.Proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		t.Error("expected the transport to use http.ProxyFromEnvironment")
+	}
+	if transport.MaxIdleConnsPerHost < maxMatrixConcurrency {
+		t.Errorf("expected at least %d idle connections per host, got %d", maxMatrixConcurrency, transport.MaxIdleConnsPerHost)
+	}
+}
+
+func TestNewGeodistanceHandler_ConnectionPool(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "test-api-key")
+
+	tests := []struct {
+		name            string
+		opts            []Option
+		maxIdle         int
+		maxIdlePerHost  int
+		idleConnTimeout time.Duration
+	}{
+		{
+			name:            "defaults",
+			maxIdle:         defaultMaxIdleConns,
+			maxIdlePerHost:  defaultMaxIdleConnsPerHost,
+			idleConnTimeout: defaultIdleConnTimeout,
+		},
+		{
+			name:            "tuned",
+			opts:            []Option{WithConnectionPool(20, 10, 30*time.Second)},
+			maxIdle:         20,
+			maxIdlePerHost:  10,
+			idleConnTimeout: 30 * time.Second,
+		},
+		{
+			name:            "non-positive values keep defaults",
+			opts:            []Option{WithConnectionPool(0, 32, -time.Second)},
+			maxIdle:         defaultMaxIdleConns,
+			maxIdlePerHost:  32,
+			idleConnTimeout: defaultIdleConnTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewGeodistanceHandler(tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			transport := handler.client.(*http.Client).Transport.(*http.Transport)
+			if transport.MaxIdleConns != tt.maxIdle {
+				t.Errorf("expected MaxIdleConns %d, got %d", tt.maxIdle, transport.MaxIdleConns)
+			}
+			if transport.MaxIdleConnsPerHost != tt.maxIdlePerHost {
+				t.Errorf("expected MaxIdleConnsPerHost %d, got %d", tt.maxIdlePerHost, transport.MaxIdleConnsPerHost)
+			}
+			if transport.IdleConnTimeout != tt.idleConnTimeout {
+				t.Errorf("expected IdleConnTimeout %v, got %v", tt.idleConnTimeout, transport.IdleConnTimeout)
+			}
+		})
+	}
+}
+
+func TestNewGeodistanceHandlerWithClient_KeepsTransport(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEY", "test-api-key")
+
+	custom := &http.Client{Transport: http.DefaultTransport}
+	handler, err := NewGeodistanceHandlerWithClient(custom, WithConnectionPool(1, 1, time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handler.client != custom || custom.Transport != http.DefaultTransport {
+		t.Error("expected the supplied client and transport to be used unchanged")
+	}
+}
@@ -0,0 +1,97 @@
+package geodistanceserver
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	unitsMetric   = "METRIC"
+	unitsImperial = "IMPERIAL"
+	unitsNautical = "NAUTICAL"
+
+	metersPerKilometer    = 1000.0
+	metersPerMile         = 1609.344
+	metersPerNauticalMile = 1852.0
+)
+
+var unitSystems = []string{unitsMetric, unitsImperial, unitsNautical}
+
+func validateUnits(units string) error {
+	switch units {
+	case unitsMetric, unitsImperial, unitsNautical:
+		return nil
+	default:
+		return fmt.Errorf("invalid units %q: must be %s, %s or %s", units, unitsMetric, unitsImperial, unitsNautical)
+	}
+}
+
+// convertDistance converts meters into the display unit for the given
+// unit system and returns the value along with its unit label.
+func convertDistance(meters int, units string) (float64, string) {
+	switch units {
+	case unitsImperial:
+		return float64(meters) / metersPerMile, "miles"
+	case unitsNautical:
+		return float64(meters) / metersPerNauticalMile, "nmi"
+	default:
+		return float64(meters) / metersPerKilometer, "km"
+	}
+}
+
+// maxRoundMeters bounds the round argument at 100 km.
+const maxRoundMeters = 100000
+
+// roundMeters rounds meters to the nearest multiple of granularity, with
+// halves rounded away from zero. A granularity of 0 or 1 keeps the value.
+func roundMeters(meters, granularity int) int {
+	if granularity <= 1 {
+		return meters
+	}
+	return int(math.Round(float64(meters)/float64(granularity))) * granularity
+}
+
+// parseRounding reads the optional round argument: a whole number of
+// meters that text output rounds distances to, such as 10, 100 or 1000.
+func parseRounding(request mcp.CallToolRequest) (int, error) {
+	value, err := optionalFloatArgument(request, request.Params.Name, "round")
+	if err != nil {
+		return 0, err
+	}
+	if value == nil {
+		return 0, nil
+	}
+	if *value != math.Trunc(*value) || *value < 0 || *value > maxRoundMeters {
+		return 0, fmt.Errorf("invalid round %v: must be a whole number of meters from 0 to %d", *value, maxRoundMeters)
+	}
+	return int(*value), nil
+}
+
+// formatDistance renders a distance rounded to two decimals in the requested
+// unit system, keeping the exact meters in parentheses. Numbers follow the
+// separators of locale, a BCP-47 tag; an empty or unknown locale keeps Go's
+// default formatting.
+func formatDistance(meters int, units string, locale string) string {
+	value, label := convertDistance(meters, units)
+	if printer := numberPrinter(locale); printer != nil {
+		return printer.Sprintf("%.2f %s (%d meters)", value, label, meters)
+	}
+	return fmt.Sprintf("%.2f %s (%d meters)", value, label, meters)
+}
+
+// numberPrinter returns a printer for the number conventions of locale, or
+// nil when locale is empty or not a known language.
+func numberPrinter(locale string) *message.Printer {
+	if locale == "" {
+		return nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil
+	}
+	return message.NewPrinter(tag)
+}
@@ -0,0 +1,209 @@
+package geodistanceserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestValidateUnits(t *testing.T) {
+	tests := []struct {
+		units     string
+		expectErr bool
+	}{
+		{units: unitsMetric, expectErr: false},
+		{units: unitsImperial, expectErr: false},
+		{units: unitsNautical, expectErr: false},
+		{units: "FURLONGS", expectErr: true},
+		{units: "", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			err := validateUnits(tt.units)
+
+			if tt.expectErr && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		meters   int
+		units    string
+		locale   string
+		expected string
+	}{
+		{
+			name:     "metric one kilometer",
+			meters:   1000,
+			units:    unitsMetric,
+			expected: "1.00 km (1000 meters)",
+		},
+		{
+			name:     "metric rounding",
+			meters:   94480,
+			units:    unitsMetric,
+			expected: "94.48 km (94480 meters)",
+		},
+		{
+			name:     "imperial one kilometer",
+			meters:   1000,
+			units:    unitsImperial,
+			expected: "0.62 miles (1000 meters)",
+		},
+		{
+			name:     "imperial one mile",
+			meters:   1609,
+			units:    unitsImperial,
+			expected: "1.00 miles (1609 meters)",
+		},
+		{
+			name:     "nautical one nautical mile",
+			meters:   1852,
+			units:    unitsNautical,
+			expected: "1.00 nmi (1852 meters)",
+		},
+		{
+			name:     "nautical conversion factor",
+			meters:   100000,
+			units:    unitsNautical,
+			expected: "54.00 nmi (100000 meters)",
+		},
+		{
+			name:     "zero distance",
+			meters:   0,
+			units:    unitsImperial,
+			expected: "0.00 miles (0 meters)",
+		},
+		{
+			name:     "english separators",
+			meters:   1234567,
+			units:    unitsMetric,
+			locale:   "en-US",
+			expected: "1,234.57 km (1,234,567 meters)",
+		},
+		{
+			name:     "german separators",
+			meters:   1234567,
+			units:    unitsMetric,
+			locale:   "de-DE",
+			expected: "1.234,57 km (1.234.567 meters)",
+		},
+		{
+			name:     "unknown locale keeps default",
+			meters:   1234567,
+			units:    unitsMetric,
+			locale:   "xx-YY",
+			expected: "1234.57 km (1234567 meters)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDistance(tt.meters, tt.units, tt.locale); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRoundMeters(t *testing.T) {
+	tests := []struct {
+		name        string
+		meters      int
+		granularity int
+		expected    int
+	}{
+		{name: "no rounding", meters: 94475, granularity: 0, expected: 94475},
+		{name: "granularity one", meters: 94475, granularity: 1, expected: 94475},
+		{name: "nearest 10 meters", meters: 94474, granularity: 10, expected: 94470},
+		{name: "halves round up", meters: 94475, granularity: 10, expected: 94480},
+		{name: "nearest 100 meters", meters: 94475, granularity: 100, expected: 94500},
+		{name: "nearest kilometer", meters: 94475, granularity: 1000, expected: 94000},
+		{name: "short distance rounds to zero", meters: 40, granularity: 100, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundMeters(tt.meters, tt.granularity); got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  int
+		expectErr bool
+	}{
+		{name: "absent", args: map[string]interface{}{}, expected: 0},
+		{name: "100 meters", args: map[string]interface{}{"round": 100.0}, expected: 100},
+		{name: "fractional", args: map[string]interface{}{"round": 2.5}, expectErr: true},
+		{name: "negative", args: map[string]interface{}{"round": -10.0}, expectErr: true},
+		{name: "too large", args: map[string]interface{}{"round": 1e6}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: tt.args},
+			}
+			got, err := parseRounding(request)
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_Round(t *testing.T) {
+	response := `{"routes":[{"distanceMeters":94475,"duration":"3288s","routeLabels":["DEFAULT_ROUTE"]}]}`
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, response), nil
+		}},
+	}
+	call := func(args map[string]interface{}) string {
+		t.Helper()
+		args["originAddress"] = "Omaha, NE"
+		args["destinationAddress"] = "Lincoln, NE"
+		request := mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: toolCalculateDistance, Arguments: args},
+		}
+		result, err := handler.handleDistanceCalculation(context.Background(), request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	if text := call(map[string]interface{}{"round": 100.0}); !strings.HasPrefix(text, "Route distance: 94.50 km (94500 meters)") {
+		t.Errorf("expected distance rounded to 100 meters, got %q", text)
+	}
+	if text := call(map[string]interface{}{"round": 100.0, "format": "json"}); !strings.Contains(text, `"distanceMeters":94475`) {
+		t.Errorf("expected exact meters in JSON output, got %q", text)
+	}
+}
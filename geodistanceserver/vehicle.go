@@ -0,0 +1,34 @@
+package geodistanceserver
+
+import "fmt"
+
+const (
+	emissionTypeGasoline = "GASOLINE"
+	emissionTypeElectric = "ELECTRIC"
+	emissionTypeHybrid   = "HYBRID"
+	emissionTypeDiesel   = "DIESEL"
+)
+
+// emissionTypes lists the accepted emissionType values, as advertised in
+// the tool schemas.
+var emissionTypes = []string{emissionTypeGasoline, emissionTypeElectric, emissionTypeHybrid, emissionTypeDiesel}
+
+// VehicleInfo describes the vehicle a DRIVE route is computed for, which
+// the Routes API uses for fuel-efficient routing.
+type VehicleInfo struct {
+	EmissionType string `json:"emissionType,omitempty"`
+}
+
+// parseEmissionType returns the vehicle info for an emissionType argument,
+// or nil when none was given.
+func parseEmissionType(emissionType string) (*VehicleInfo, error) {
+	switch emissionType {
+	case "":
+		return nil, nil
+	case emissionTypeGasoline, emissionTypeElectric, emissionTypeHybrid, emissionTypeDiesel:
+		return &VehicleInfo{EmissionType: emissionType}, nil
+	default:
+		return nil, fmt.Errorf("invalid emissionType %q: must be %s, %s, %s or %s", emissionType,
+			emissionTypeGasoline, emissionTypeElectric, emissionTypeHybrid, emissionTypeDiesel)
+	}
+}
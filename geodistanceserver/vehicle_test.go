@@ -0,0 +1,91 @@
+package geodistanceserver
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_parseRouteOptions_EmissionType(t *testing.T) {
+	handler := &GeodistanceHandler{}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  string
+		expectErr string
+	}{
+		{name: "not set", args: map[string]interface{}{}},
+		{name: "electric car", args: map[string]interface{}{"emissionType": "ELECTRIC"}, expected: emissionTypeElectric},
+		{name: "explicit drive", args: map[string]interface{}{"emissionType": "diesel", "travelMode": "DRIVE"}, expected: emissionTypeDiesel},
+		{name: "invalid type", args: map[string]interface{}{"emissionType": "STEAM"}, expectErr: "invalid emissionType"},
+		{name: "bicycle", args: map[string]interface{}{"emissionType": "HYBRID", "travelMode": "BICYCLE"}, expectErr: "only supported for DRIVE"},
+		{name: "walk", args: map[string]interface{}{"emissionType": "HYBRID", "travelMode": "WALK"}, expectErr: "only supported for DRIVE"},
+		{name: "two wheeler", args: map[string]interface{}{"emissionType": "GASOLINE", "travelMode": "TWO_WHEELER"}, expectErr: "only supported for DRIVE"},
+		{name: "transit", args: map[string]interface{}{"emissionType": "ELECTRIC", "travelMode": "TRANSIT"}, expectErr: "only supported for DRIVE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+
+			opts, err := handler.parseRouteOptions(request)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := ""
+			if opts.RouteModifiers.VehicleInfo != nil {
+				got = opts.RouteModifiers.VehicleInfo.EmissionType
+			}
+			if got != tt.expected {
+				t.Errorf("expected emission type %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEmissionTypeSerialization(t *testing.T) {
+	handler := &GeodistanceHandler{}
+	opts := routeOptions{RouteModifiers: RouteModifiers{VehicleInfo: &VehicleInfo{EmissionType: emissionTypeElectric}}}
+	const expected = `"routeModifiers":{"vehicleInfo":{"emissionType":"ELECTRIC"}}`
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{name: "routes", body: handler.buildRequestBody([]Origin{{Address: "A"}}, []Destination{{Address: "B"}}, opts)},
+		{name: "route matrix", body: handler.buildMatrixRequestBody([]string{"A"}, []string{"B"}, opts)},
+		{name: "compute routes", body: newComputeRoutesBody(handler.buildRequestBody([]Origin{{Address: "A"}}, []Destination{{Address: "B"}}, opts))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(data), expected) {
+				t.Errorf("expected %s in %s", expected, data)
+			}
+		})
+	}
+
+	data, err := json.Marshal(handler.buildRequestBody(nil, nil, routeOptions{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "vehicleInfo") {
+		t.Errorf("vehicleInfo should be omitted when not set: %s", data)
+	}
+}
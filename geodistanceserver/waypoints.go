@@ -0,0 +1,94 @@
+package geodistanceserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxIntermediates is the number of intermediate waypoints computeRoutes
+// accepts in a single request.
+const maxIntermediates = 25
+
+// ComputeRoutesRequestBody is the computeRoutes request for a trip through
+// ordered intermediate waypoints.
+type ComputeRoutesRequestBody struct {
+	Origin             Waypoint            `json:"origin"`
+	Destination        Waypoint            `json:"destination"`
+	Intermediates      []Waypoint          `json:"intermediates,omitempty"`
+	TravelMode         string              `json:"travelMode,omitempty"`
+	RoutingPreference  string              `json:"routingPreference,omitempty"`
+	LanguageCode       string              `json:"languageCode,omitempty"`
+	RegionCode         string              `json:"regionCode,omitempty"`
+	RouteModifiers     *RouteModifiers     `json:"routeModifiers,omitempty"`
+	DepartureTime      string              `json:"departureTime,omitempty"`
+	ArrivalTime        string              `json:"arrivalTime,omitempty"`
+	ExtraComputations  []string            `json:"extraComputations,omitempty"`
+	TransitPreferences *TransitPreferences `json:"transitPreferences,omitempty"`
+}
+
+// parseWaypoints reads the optional waypoints argument, normalizing each
+// address. Alternative routes are not available for trips with
+// intermediates.
+func parseWaypoints(request mcp.CallToolRequest, opts routeOptions) ([]string, error) {
+	waypoints, err := optionalStringSliceArgument(request, toolCalculateDistance, "waypoints")
+	if err != nil {
+		return nil, err
+	}
+	if len(waypoints) == 0 {
+		return nil, nil
+	}
+
+	if len(waypoints) > maxIntermediates {
+		return nil, fmt.Errorf("too many waypoints: %d exceeds the limit of %d", len(waypoints), maxIntermediates)
+	}
+	if opts.ComputeAlternativeRoutes {
+		return nil, fmt.Errorf("computeAlternativeRoutes is not supported with waypoints")
+	}
+
+	normalized := make([]string, len(waypoints))
+	for i, address := range waypoints {
+		normalized[i] = normalizeAddress(address)
+		if normalized[i] == "" {
+			return nil, fmt.Errorf("waypoint %d cannot be empty", i+1)
+		}
+	}
+
+	return normalized, nil
+}
+
+// newComputeRoutesBody converts a single-route request carrying
+// intermediates into the computeRoutes request shape.
+func newComputeRoutesBody(body *RequestBody) *ComputeRoutesRequestBody {
+	routesBody := &ComputeRoutesRequestBody{
+		Intermediates:      body.Intermediates,
+		TravelMode:         body.TravelMode,
+		RoutingPreference:  body.RoutingPreference,
+		LanguageCode:       body.LanguageCode,
+		RegionCode:         body.RegionCode,
+		RouteModifiers:     body.RouteModifiers,
+		DepartureTime:      body.DepartureTime,
+		ArrivalTime:        body.ArrivalTime,
+		ExtraComputations:  body.ExtraComputations,
+		TransitPreferences: body.TransitPreferences,
+	}
+	if len(body.Origins) > 0 {
+		routesBody.Origin = Waypoint{Address: body.Origins[0].Address, Location: body.Origins[0].Location}
+	}
+	if len(body.Destinations) > 0 {
+		routesBody.Destination = Waypoint{Address: body.Destinations[0].Address, Location: body.Destinations[0].Location}
+	}
+	return routesBody
+}
+
+// createComputeRoutesRequest builds the request for a trip through
+// intermediate waypoints, which computeRouteMatrix cannot express.
+func (gh *GeodistanceHandler) createComputeRoutesRequest(ctx context.Context, body *RequestBody, fieldMask string) (*http.Request, error) {
+	endpoint := gh.routesURL
+	if endpoint == "" {
+		endpoint = defaultRoutesURL
+	}
+	return gh.newAPIRequest(ctx, endpoint, newComputeRoutesBody(body), fieldMask)
+}
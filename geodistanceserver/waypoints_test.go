@@ -0,0 +1,223 @@
+package geodistanceserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGeodistanceHandler_handleDistanceCalculation_Waypoints(t *testing.T) {
+	tests := []struct {
+		name                  string
+		waypoints             []interface{}
+		expectedURL           string
+		expectedIntermediates []string
+	}{
+		{
+			name:        "no waypoints",
+			waypoints:   nil,
+			expectedURL: defaultBaseURL,
+		},
+		{
+			name:                  "one waypoint",
+			waypoints:             []interface{}{"Des Moines, IA"},
+			expectedURL:           defaultRoutesURL,
+			expectedIntermediates: []string{"Des Moines, IA"},
+		},
+		{
+			name:                  "several waypoints in order",
+			waypoints:             []interface{}{" Des Moines, IA", "Iowa City,  IA", "Davenport, IA"},
+			expectedURL:           defaultRoutesURL,
+			expectedIntermediates: []string{"Des Moines, IA", "Iowa City, IA", "Davenport, IA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &GeodistanceHandler{
+				apiKey: "test-key",
+				client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.String() != tt.expectedURL {
+						t.Errorf("expected URL %s, got %s", tt.expectedURL, req.URL)
+					}
+
+					data, err := io.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					var raw map[string]json.RawMessage
+					if err := json.Unmarshal(data, &raw); err != nil {
+						return nil, err
+					}
+
+					if tt.expectedIntermediates == nil {
+						if _, ok := raw["intermediates"]; ok {
+							t.Error("intermediates should be omitted without waypoints")
+						}
+						return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+					}
+
+					var body ComputeRoutesRequestBody
+					if err := json.Unmarshal(data, &body); err != nil {
+						return nil, err
+					}
+					if body.Origin.Address != "Omaha, NE" || body.Destination.Address != "Chicago, IL" {
+						t.Errorf("unexpected endpoints: %+v -> %+v", body.Origin, body.Destination)
+					}
+					var got []string
+					for _, w := range body.Intermediates {
+						got = append(got, w.Address)
+					}
+					if !reflect.DeepEqual(got, tt.expectedIntermediates) {
+						t.Errorf("expected intermediates %v, got %v", tt.expectedIntermediates, got)
+					}
+					return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+				}},
+			}
+
+			args := map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "Chicago, IL",
+			}
+			if tt.waypoints != nil {
+				args["waypoints"] = tt.waypoints
+			}
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: args},
+			}
+
+			result, err := handler.handleDistanceCalculation(context.Background(), request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Content) != 1 {
+				t.Errorf("expected 1 content item, got %d", len(result.Content))
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_WaypointTooLong(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Error("no request expected for an over-long waypoint")
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "calculate_distance", Arguments: map[string]interface{}{
+			"originAddress":      "Omaha, NE",
+			"destinationAddress": "Chicago, IL",
+			"waypoints":          []interface{}{"Des Moines, IA", strings.Repeat("a", defaultMaxAddressLength+1)},
+		}},
+	}
+
+	_, err := handler.handleDistanceCalculation(context.Background(), request)
+	if err == nil || !strings.Contains(err.Error(), "waypoint 2 is too long") {
+		t.Errorf("expected waypoint length error, got %v", err)
+	}
+}
+
+func TestParseWaypoints(t *testing.T) {
+	tooMany := make([]interface{}, maxIntermediates+1)
+	for i := range tooMany {
+		tooMany[i] = "Stop"
+	}
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "absent",
+			args:     map[string]interface{}{},
+			expected: nil,
+		},
+		{
+			name:     "empty array",
+			args:     map[string]interface{}{"waypoints": []interface{}{}},
+			expected: nil,
+		},
+		{
+			name:      "not an array",
+			args:      map[string]interface{}{"waypoints": "Des Moines"},
+			expectErr: true,
+		},
+		{
+			name:      "blank waypoint",
+			args:      map[string]interface{}{"waypoints": []interface{}{"Des Moines", "  "}},
+			expectErr: true,
+		},
+		{
+			name:      "too many waypoints",
+			args:      map[string]interface{}{"waypoints": tooMany},
+			expectErr: true,
+		},
+		{
+			name: "with alternative routes",
+			args: map[string]interface{}{
+				"waypoints":                []interface{}{"Des Moines"},
+				"computeAlternativeRoutes": true,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Arguments: tt.args},
+			}
+			opts := routeOptions{ComputeAlternativeRoutes: request.GetBool("computeAlternativeRoutes", false)}
+
+			got, err := parseWaypoints(request, opts)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGeodistanceHandler_handleDistanceCalculation_WaypointsRoundTrip(t *testing.T) {
+	handler := &GeodistanceHandler{
+		apiKey: "test-key",
+		client: &MockHTTPClient{DoFunc: func(req *http.Request) (*http.Response, error) {
+			return createMockResponse(http.StatusOK, createValidAPIResponse()), nil
+		}},
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "calculate_distance",
+			Arguments: map[string]interface{}{
+				"originAddress":      "Omaha, NE",
+				"destinationAddress": "omaha, ne",
+				"waypoints":          []interface{}{"Lincoln, NE"},
+			},
+		},
+	}
+
+	if _, err := handler.handleDistanceCalculation(context.Background(), request); err != nil {
+		t.Errorf("expected a loop through waypoints to be allowed, got %v", err)
+	}
+}
@@ -2,13 +2,27 @@ module github.com/nobelk/geodistance
 
 go 1.24.3
 
-require github.com/kr/pretty v0.3.1
+require (
+	github.com/kr/pretty v0.3.1
+	github.com/mark3labs/mcp-go v0.32.0
+	github.com/prometheus/client_golang v1.22.0
+	golang.org/x/text v0.30.0
+	golang.org/x/time v0.11.0
+)
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/kr/text v0.2.0 // indirect
-	github.com/mark3labs/mcp-go v0.32.0 // indirect
-	github.com/rogpeppe/go-internal v1.9.0 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.62.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	github.com/rogpeppe/go-internal v1.10.0 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/protobuf v1.36.5 // indirect
 )